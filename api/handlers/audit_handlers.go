@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+	"github.com/afreedicp/zolaris-backend-app/internal/services"
+	"github.com/afreedicp/zolaris-backend-app/internal/transport/dto"
+	"github.com/afreedicp/zolaris-backend-app/internal/transport/mappers"
+	"github.com/afreedicp/zolaris-backend-app/internal/transport/response"
+	"github.com/afreedicp/zolaris-backend-app/internal/utils"
+)
+
+// AuditHandler handles the admin requests to review the audit log
+type AuditHandler struct {
+	auditService *services.AuditService
+}
+
+// NewAuditHandler creates a new AuditHandler
+func NewAuditHandler(auditService *services.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// HandleListAuditEntries handles requests to list the audit log
+// @Summary List audit log
+// @Description Get one page of the mutating actions recorded in the audit log, newest first by default. Admins only.
+// @Tags Admin
+// @Produce json
+// @Param X-Cognito-ID header string true "Cognito ID"
+// @Param page query int false "Page number, starting at 1 (default: 1)"
+// @Param pageSize query int false "Items per page (default: 20, max: 100)"
+// @Param cursor query string false "Cursor from a previous response's nextCursor"
+// @Param sort query string false "Sort field: occurredAt; prefix with - for descending (default: -occurredAt)"
+// @Param actorId query string false "Filter by the user who acted"
+// @Param action query string false "Filter by action: create, update, import or attach_policy"
+// @Param resourceType query string false "Filter by resource type: user, entity, category, device or iot_identity"
+// @Param resourceId query string false "Filter by resource ID"
+// @Param from query string false "Only actions at or after this time (RFC 3339)"
+// @Param to query string false "Only actions before this time (RFC 3339)"
+// @Success 200 {object} dto.Response{data=dto.PaginatedResponse{items=[]dto.AuditEntryResponse}} "Page of audit entries"
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 401 {object} dto.ErrorResponse "User not authenticated"
+// @Failure 403 {object} dto.ErrorResponse "Admin access required"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /v1/admin/audit [get]
+// @Router /v2/admin/audit-entries [get]
+func (h *AuditHandler) HandleListAuditEntries(c *gin.Context) {
+	// Parse query parameters
+	var request dto.ListAuditEntriesRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		response.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	// Validate request
+	validationErrs := utils.Validate(request)
+	if validationErrs != nil {
+		slog.InfoContext(c.Request.Context(), "Validation errors", "errors", utils.ValidationErrorsToString(validationErrs))
+		response.ValidationErrors(c, utils.CreateDtoValidationErrors(validationErrs))
+		return
+	}
+
+	filter, ok := parseAuditFilter(c, request.AuditFilterParams)
+	if !ok {
+		return
+	}
+
+	if request.Sort == "" {
+		request.Sort = "-occurredAt"
+	}
+	opts := mappers.PaginationToListOptions(request.PaginationParams, nil)
+
+	// Call service to get one page of the audit log
+	page, err := h.auditService.ListEntries(c.Request.Context(), filter, opts)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Error listing audit entries", "error", err)
+		c.Error(err)
+		return
+	}
+
+	response.Paginated(c, mappers.AuditEntriesToResponses(page.Items), page.TotalItems, page.Page, page.PageSize, page.NextCursor)
+}
+
+// HandleExportAuditEntries handles requests to export the audit log as CSV
+// @Summary Export audit log
+// @Description Download every audit entry matching the filters as CSV, oldest first, for compliance reviews. Admins only.
+// @Tags Admin
+// @Produce text/csv
+// @Param X-Cognito-ID header string true "Cognito ID"
+// @Param actorId query string false "Filter by the user who acted"
+// @Param action query string false "Filter by action"
+// @Param resourceType query string false "Filter by resource type"
+// @Param resourceId query string false "Filter by resource ID"
+// @Param from query string false "Only actions at or after this time (RFC 3339)"
+// @Param to query string false "Only actions before this time (RFC 3339)"
+// @Success 200 {string} string "Audit entries as CSV"
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 401 {object} dto.ErrorResponse "User not authenticated"
+// @Failure 403 {object} dto.ErrorResponse "Admin access required"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /v1/admin/audit/export [get]
+// @Router /v2/admin/audit-entries/export [get]
+func (h *AuditHandler) HandleExportAuditEntries(c *gin.Context) {
+	// Parse query parameters
+	var request dto.AuditFilterParams
+	if err := c.ShouldBindQuery(&request); err != nil {
+		response.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	// Validate request
+	validationErrs := utils.Validate(request)
+	if validationErrs != nil {
+		slog.InfoContext(c.Request.Context(), "Validation errors", "errors", utils.ValidationErrorsToString(validationErrs))
+		response.ValidationErrors(c, utils.CreateDtoValidationErrors(validationErrs))
+		return
+	}
+
+	filter, ok := parseAuditFilter(c, request)
+	if !ok {
+		return
+	}
+
+	// The rows are streamed, so the status is only sent with the first one: a
+	// failing query is still reported as an error, a failure later truncates the file
+	var writer *mappers.AuditCSVWriter
+	start := func() error {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="audit-%s.csv"`, time.Now().UTC().Format("20060102T150405Z")))
+		c.Status(http.StatusOK)
+		var err error
+		writer, err = mappers.NewAuditCSVWriter(c.Writer)
+		return err
+	}
+
+	err := h.auditService.ExportEntries(c.Request.Context(), filter, func(entry *domain.AuditEntry) error {
+		if writer == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		return writer.Write(entry)
+	})
+	if err == nil && writer == nil {
+		err = start()
+	}
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Error exporting audit entries", "error", err)
+		if writer == nil {
+			c.Error(err)
+		}
+		return
+	}
+
+	if err := writer.Flush(); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Error writing audit CSV", "error", err)
+	}
+}
+
+// parseAuditFilter converts the filter parameters of an audit request, sending
+// a 400 response if the time range is invalid
+func parseAuditFilter(c *gin.Context, params dto.AuditFilterParams) (domain.AuditFilter, bool) {
+	filter := domain.AuditFilter{
+		ActorID:      params.ActorID,
+		Action:       params.Action,
+		ResourceType: params.ResourceType,
+		ResourceID:   params.ResourceID,
+	}
+
+	var err error
+	if params.From != "" {
+		if filter.From, err = time.Parse(time.RFC3339, params.From); err != nil {
+			response.BadRequest(c, "from must be an RFC 3339 timestamp")
+			return filter, false
+		}
+	}
+	if params.To != "" {
+		if filter.To, err = time.Parse(time.RFC3339, params.To); err != nil {
+			response.BadRequest(c, "to must be an RFC 3339 timestamp")
+			return filter, false
+		}
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		response.BadRequest(c, "from must be before to")
+		return filter, false
+	}
+
+	return filter, true
+}
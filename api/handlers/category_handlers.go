@@ -1,13 +1,13 @@
 package handlers
 
 import (
-	"log"
-	"net/http"
+	"log/slog"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/afreedicp/zolaris-backend-app/internal/services"
 	"github.com/afreedicp/zolaris-backend-app/internal/transport/dto"
+	"github.com/afreedicp/zolaris-backend-app/internal/transport/mappers"
 	"github.com/afreedicp/zolaris-backend-app/internal/transport/response"
 	"github.com/afreedicp/zolaris-backend-app/internal/utils"
 )
@@ -33,12 +33,13 @@ func NewAddCategoryHandler(categoryService *services.CategoryService) *AddCatego
 // @Failure 400 {object} dto.ErrorResponse "Validation error"
 // @Failure 409 {object} dto.ErrorResponse "Category already exists"
 // @Failure 500 {object} dto.ErrorResponse "Internal server error"
-// @Router /category/add [post]
+// @Router /v1/category/add [post]
+// @Router /v2/categories [post]
 func (h *AddCategoryHandler) HandleGin(c *gin.Context) {
 	// Parse request body
 	var request dto.CategoryRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		log.Printf("Error decoding request: %v", err)
+		slog.InfoContext(c.Request.Context(), "Error decoding request", "error", err)
 		response.BadRequest(c, "Invalid request format")
 		return
 	}
@@ -46,19 +47,15 @@ func (h *AddCategoryHandler) HandleGin(c *gin.Context) {
 	// Validate request
 	validationErrs := utils.Validate(request)
 	if validationErrs != nil {
-		log.Printf("Validation errors: %s", utils.ValidationErrorsToString(validationErrs))
+		slog.InfoContext(c.Request.Context(), "Validation errors", "errors", utils.ValidationErrorsToString(validationErrs))
 		response.ValidationErrors(c, utils.CreateDtoValidationErrors(validationErrs))
 		return
 	}
 
 	// Call service to add category
 	if err := h.categoryService.AddCategory(c.Request.Context(), request.Name, request.Type); err != nil {
-		if err.Error() == "category with this name already exists" {
-			response.Error(c, http.StatusConflict, "Category with this name already exists", "CONFLICT")
-			return
-		}
-		log.Printf("Error adding category: %v", err)
-		response.InternalError(c, "Failed to add category")
+		slog.ErrorContext(c.Request.Context(), "Error adding category", "error", err)
+		c.Error(err)
 		return
 	}
 
@@ -81,23 +78,28 @@ func NewGetCategoriesByTypeHandler(categoryService *services.CategoryService) *G
 // @Tags Category Management
 // @Accept json
 // @Produce json
-// @Param type path string true "Category type"
+// @Param type path string true "Category type" Enums(user, office, location)
 // @Success 200 {array} dto.CategoryResponse "List of categories"
+// @Failure 400 {object} dto.ErrorResponse "Validation error"
 // @Failure 500 {object} dto.ErrorResponse "Internal server error"
-// @Router /category/type/{type} [get]
+// @Router /v1/category/type/{type} [get]
 func (h *GetCategoriesByTypeHandler) HandleGin(c *gin.Context) {
 	// Get type from URL parameter
-	categoryType := c.Param("type")
-	if categoryType == "" {
-		response.BadRequest(c, "Category type is required")
+	request := dto.GetCategoriesByTypeRequest{Type: c.Param("type")}
+
+	// Validate request
+	validationErrs := utils.Validate(request)
+	if validationErrs != nil {
+		slog.InfoContext(c.Request.Context(), "Validation errors", "errors", utils.ValidationErrorsToString(validationErrs))
+		response.ValidationErrors(c, utils.CreateDtoValidationErrors(validationErrs))
 		return
 	}
 
 	// Call service to get categories by type
-	categories, err := h.categoryService.GetCategoriesByType(c.Request.Context(), categoryType)
+	categories, err := h.categoryService.GetCategoriesByType(c.Request.Context(), request.Type)
 	if err != nil {
-		log.Printf("Error getting categories: %v", err)
-		response.InternalError(c, "Failed to get categories")
+		slog.ErrorContext(c.Request.Context(), "Error getting categories", "error", err)
+		c.Error(err)
 		return
 	}
 
@@ -121,26 +123,47 @@ func NewListAllCategoriesHandler(categoryService *services.CategoryService) *Lis
 
 // HandleGin handles requests using Gin framework
 // @Summary Get all categories
-// @Description Retrieve all categories
+// @Description Retrieve one page of all categories
 // @Tags Category Management
 // @Produce json
-// @Success 200 {array} dto.CategoryResponse "List of categories"
+// @Param page query int false "Page number, starting at 1 (default: 1)"
+// @Param pageSize query int false "Items per page (default: 20, max: 100)"
+// @Param cursor query string false "Cursor from a previous response's nextCursor"
+// @Param sort query string false "Sort field: name, type or createdAt; prefix with - for descending"
+// @Param type query string false "Filter by category type" Enums(user, office, location)
+// @Success 200 {object} dto.Response{data=dto.PaginatedResponse{items=[]dto.CategoryResponse}} "Page of categories"
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
 // @Failure 500 {object} dto.ErrorResponse "Internal server error"
-// @Router /category/all [get]
+// @Router /v1/category/all [get]
+// @Router /v2/categories [get]
 func (h *ListAllCategoriesHandler) HandleGin(c *gin.Context) {
-	// Call service to get all categories
-	categories, err := h.categoryService.GetAllCategories(c.Request.Context())
-	if err != nil {
-		log.Printf("Error getting categories: %v", err)
-		response.InternalError(c, "Failed to get categories")
+	// Parse query parameters
+	var request dto.ListCategoriesRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		response.BadRequest(c, "Invalid query parameters")
 		return
 	}
 
-	// Return empty array if no categories found
-	if categories == nil {
-		categories = []*dto.CategoryResponse{}
+	// Validate request
+	validationErrs := utils.Validate(request)
+	if validationErrs != nil {
+		slog.InfoContext(c.Request.Context(), "Validation errors", "errors", utils.ValidationErrorsToString(validationErrs))
+		response.ValidationErrors(c, utils.CreateDtoValidationErrors(validationErrs))
+		return
 	}
 
-	response.OK(c, categories, "Categories retrieved successfully")
+	opts := mappers.PaginationToListOptions(request.PaginationParams, map[string]string{
+		"type": request.Type,
+	})
+
+	// Call service to get one page of categories
+	page, err := h.categoryService.GetAllCategories(c.Request.Context(), opts)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Error getting categories", "error", err)
+		c.Error(err)
+		return
+	}
+
+	response.Paginated(c, mappers.CategoriesToResponses(page.Items), page.TotalItems, page.Page, page.PageSize, page.NextCursor)
 }
 
@@ -1,13 +1,14 @@
 package handlers
 
 import (
-	"log"
+	"log/slog"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/afreedicp/zolaris-backend-app/internal/middleware"
 	"github.com/afreedicp/zolaris-backend-app/internal/services"
 	"github.com/afreedicp/zolaris-backend-app/internal/transport/dto"
+	"github.com/afreedicp/zolaris-backend-app/internal/transport/mappers"
 	"github.com/afreedicp/zolaris-backend-app/internal/transport/response"
 	"github.com/afreedicp/zolaris-backend-app/internal/utils"
 )
@@ -30,17 +31,19 @@ func NewAddDeviceHandler(deviceService *services.DeviceService) *AddDeviceHandle
 // @Produce json
 // @Param X-Cognito-ID header string true "Cognito ID"
 // @Param device body dto.DeviceRequest true "Device information"
+// @Param Idempotency-Key header string false "Key identifying retries of this request"
 // @Success 201 {object} dto.Response "Device added successfully"
 // @Failure 400 {object} dto.ErrorResponse "Validation error"
 // @Failure 401 {object} dto.ErrorResponse "User not authenticated"
 // @Failure 500 {object} dto.ErrorResponse "Internal server error"
 // @Security ApiKeyAuth
-// @Router /device/add [post]
+// @Router /v1/device/add [post]
+// @Router /v2/devices [post]
 func (h *AddDeviceHandler) HandleGin(c *gin.Context) {
 	// Parse request body
 	var request dto.DeviceRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		log.Printf("Error decoding request: %v", err)
+		slog.InfoContext(c.Request.Context(), "Error decoding request", "error", err)
 		response.BadRequest(c, "Invalid request format")
 		return
 	}
@@ -48,7 +51,7 @@ func (h *AddDeviceHandler) HandleGin(c *gin.Context) {
 	// Validate request
 	validationErrs := utils.Validate(request)
 	if validationErrs != nil {
-		log.Printf("Validation errors: %s", utils.ValidationErrorsToString(validationErrs))
+		slog.InfoContext(c.Request.Context(), "Validation errors", "errors", utils.ValidationErrorsToString(validationErrs))
 		response.ValidationErrors(c, utils.CreateDtoValidationErrors(validationErrs))
 		return
 	}
@@ -62,8 +65,8 @@ func (h *AddDeviceHandler) HandleGin(c *gin.Context) {
 
 	// Call service to add device
 	if err := h.deviceService.AddDevice(c.Request.Context(), request.DeviceID, request.DeviceName, userID); err != nil {
-		log.Printf("Error adding device: %v", err)
-		response.InternalError(c, "Failed to add device")
+		slog.ErrorContext(c.Request.Context(), "Error adding device", "error", err)
+		c.Error(err)
 		return
 	}
 
@@ -82,16 +85,24 @@ func NewListUserDevicesHandler(deviceService *services.DeviceService) *ListUserD
 
 // HandleGin handles requests using Gin framework
 // @Summary List user devices
-// @Description Get all devices registered to the authenticated user
+// @Description Get one page of the devices registered to the authenticated user
 // @Tags Device Management
 // @Accept json
 // @Produce json
-// @Param X-User-ID header string true "User ID"
-// @Success 200 {array} dto.DeviceResponse "List of user devices"
+// @Param X-Cognito-ID header string true "Cognito ID"
+// @Param page query int false "Page number, starting at 1 (default: 1)"
+// @Param pageSize query int false "Items per page (default: 20, max: 100)"
+// @Param cursor query string false "Cursor from a previous response's nextCursor"
+// @Param sort query string false "Sort field: name or createdAt; prefix with - for descending"
+// @Param category query string false "Filter by device category"
+// @Param entityId query string false "Filter by assigned entity"
+// @Success 200 {object} dto.Response{data=dto.PaginatedResponse{items=[]dto.DeviceResponse}} "Page of user devices"
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
 // @Failure 401 {object} dto.ErrorResponse "User not authenticated"
 // @Failure 500 {object} dto.ErrorResponse "Internal server error"
 // @Security ApiKeyAuth
-// @Router /user/devices [get]
+// @Router /v1/user/devices [get]
+// @Router /v2/devices [get]
 func (h *ListUserDevicesHandler) HandleGin(c *gin.Context) {
 	// Get user ID from context (set by auth middleware)
 	userID := middleware.GetUserIDFromGin(c)
@@ -100,15 +111,35 @@ func (h *ListUserDevicesHandler) HandleGin(c *gin.Context) {
 		return
 	}
 
-	// Call service to get user devices
-	devices, err := h.deviceService.GetUserDevices(c.Request.Context(), userID)
+	// Parse query parameters
+	var request dto.ListUserDevicesRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		response.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	// Validate request
+	validationErrs := utils.Validate(request)
+	if validationErrs != nil {
+		slog.InfoContext(c.Request.Context(), "Validation errors", "errors", utils.ValidationErrorsToString(validationErrs))
+		response.ValidationErrors(c, utils.CreateDtoValidationErrors(validationErrs))
+		return
+	}
+
+	opts := mappers.PaginationToListOptions(request.PaginationParams, map[string]string{
+		"category": request.Category,
+		"entityId": request.EntityID,
+	})
+
+	// Call service to get one page of user devices
+	page, err := h.deviceService.GetUserDevices(c.Request.Context(), userID, opts)
 	if err != nil {
-		log.Printf("Error getting user devices: %v", err)
-		response.InternalError(c, "Failed to retrieve user devices")
+		slog.ErrorContext(c.Request.Context(), "Error getting user devices", "error", err)
+		c.Error(err)
 		return
 	}
 
-	response.OK(c, devices, "Devices retrieved successfully")
+	response.Paginated(c, mappers.DevicesToResponses(page.Items), page.TotalItems, page.Page, page.PageSize, page.NextCursor)
 }
 
 // GetDeviceSensorDataHandler handles requests to get sensor data for a device
@@ -131,12 +162,13 @@ func NewGetDeviceSensorDataHandler(deviceService *services.DeviceService) *GetDe
 // @Success 200 {object} dto.Response{data=[]dto.SensorDataResponse} "Sensor data for the device"
 // @Failure 400 {object} dto.ErrorResponse "Invalid request or validation error"
 // @Failure 500 {object} dto.ErrorResponse "Internal server error"
-// @Router /device/sensor-data [post]
+// @Failure 503 {object} dto.ErrorResponse "Sensor data store unavailable"
+// @Router /v1/device/sensor-data [post]
 func (h *GetDeviceSensorDataHandler) HandleGin(c *gin.Context) {
 	// Parse request body
 	var request dto.SensorDataRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		log.Printf("Error decoding request: %v", err)
+		slog.InfoContext(c.Request.Context(), "Error decoding request", "error", err)
 		response.BadRequest(c, "Invalid request format")
 		return
 	}
@@ -144,7 +176,7 @@ func (h *GetDeviceSensorDataHandler) HandleGin(c *gin.Context) {
 	// Validate request
 	validationErrs := utils.Validate(request)
 	if validationErrs != nil {
-		log.Printf("Validation errors: %s", utils.ValidationErrorsToString(validationErrs))
+		slog.InfoContext(c.Request.Context(), "Validation errors", "errors", utils.ValidationErrorsToString(validationErrs))
 		response.ValidationErrors(c, utils.CreateDtoValidationErrors(validationErrs))
 		return
 	}
@@ -155,11 +187,57 @@ func (h *GetDeviceSensorDataHandler) HandleGin(c *gin.Context) {
 	// Call service to get sensor data
 	data, err := h.deviceService.GetDeviceSensorData(c.Request.Context(), request.DeviceMacID, request.DateMode, baseTimestamp)
 	if err != nil {
-		log.Printf("Error getting sensor data: %v", err)
-		response.InternalError(c, "Failed to retrieve sensor data")
+		slog.ErrorContext(c.Request.Context(), "Error getting sensor data", "error", err)
+		c.Error(err)
 		return
 	}
 
 	// Use the data directly in the response
 	response.OK(c, data, "Data retrieved successfully")
 }
+
+// HandleGetSensorData handles requests for the sensor data of the device in the path
+// @Summary Get device sensor data
+// @Description Retrieve sensor data for a specific device with time filtering
+// @Tags Device Data
+// @Produce json
+// @Param device_id path string true "Device MAC ID"
+// @Param timestamp query string true "Base timestamp of the period"
+// @Param dateMode query string true "Period: hourly, daily, weekly, monthly or yearly"
+// @Success 200 {object} dto.Response{data=[]dto.SensorDataResponse} "Sensor data for the device"
+// @Failure 400 {object} dto.ErrorResponse "Invalid request or validation error"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Failure 503 {object} dto.ErrorResponse "Sensor data store unavailable"
+// @Router /v2/devices/{device_id}/sensor-data [get]
+func (h *GetDeviceSensorDataHandler) HandleGetSensorData(c *gin.Context) {
+	// Parse query parameters
+	var query dto.SensorDataQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	request := dto.SensorDataRequest{
+		DeviceMacID: c.Param("device_id"),
+		Timestamp:   query.Timestamp,
+		DateMode:    query.DateMode,
+	}
+
+	// Validate request
+	validationErrs := utils.Validate(request)
+	if validationErrs != nil {
+		slog.InfoContext(c.Request.Context(), "Validation errors", "errors", utils.ValidationErrorsToString(validationErrs))
+		response.ValidationErrors(c, utils.CreateDtoValidationErrors(validationErrs))
+		return
+	}
+
+	// Call service to get sensor data
+	data, err := h.deviceService.GetDeviceSensorData(c.Request.Context(), request.DeviceMacID, request.DateMode, request.Timestamp)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Error getting sensor data", "error", err)
+		c.Error(err)
+		return
+	}
+
+	response.OK(c, data, "Data retrieved successfully")
+}
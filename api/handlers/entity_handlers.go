@@ -1,11 +1,13 @@
 package handlers
 
 import (
-	"log"
+	"encoding/json"
+	"log/slog"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/afreedicp/zolaris-backend-app/internal/middleware"
+	"github.com/afreedicp/zolaris-backend-app/internal/repositories"
 	"github.com/afreedicp/zolaris-backend-app/internal/services"
 	"github.com/afreedicp/zolaris-backend-app/internal/transport/dto"
 	"github.com/afreedicp/zolaris-backend-app/internal/transport/mappers"
@@ -34,14 +36,16 @@ func NewEntityHandler(entityService *services.EntityService) *EntityHandler {
 // @Success 201 {object} dto.Response "Entity created successfully"
 // @Failure 400 {object} dto.ErrorResponse "Validation error"
 // @Failure 401 {object} dto.ErrorResponse "User not authenticated"
+// @Failure 404 {object} dto.ErrorResponse "Category not found"
 // @Failure 500 {object} dto.ErrorResponse "Internal server error"
 // @Security ApiKeyAuth
-// @Router /entity/root [post]
+// @Router /v1/entity/root [post]
+// @Router /v2/entities [post]
 func (h *EntityHandler) HandleCreateRootEntity(c *gin.Context) {
 	// Parse request body
 	var request dto.CreateRootEntityRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		log.Printf("Error decoding request: %v", err)
+		slog.InfoContext(c.Request.Context(), "Error decoding request", "error", err)
 		response.BadRequest(c, "Invalid request format")
 		return
 	}
@@ -49,7 +53,7 @@ func (h *EntityHandler) HandleCreateRootEntity(c *gin.Context) {
 	// Validate request
 	validationErrs := utils.Validate(request)
 	if validationErrs != nil {
-		log.Printf("Validation errors: %s", utils.ValidationErrorsToString(validationErrs))
+		slog.InfoContext(c.Request.Context(), "Validation errors", "errors", utils.ValidationErrorsToString(validationErrs))
 		response.ValidationErrors(c, utils.CreateDtoValidationErrors(validationErrs))
 		return
 	}
@@ -70,8 +74,8 @@ func (h *EntityHandler) HandleCreateRootEntity(c *gin.Context) {
 		request.Details,
 	)
 	if err != nil {
-		log.Printf("Error creating root entity: %v", err)
-		response.InternalError(c, "Failed to create root entity")
+		slog.ErrorContext(c.Request.Context(), "Error creating root entity", "error", err)
+		c.Error(err)
 		return
 	}
 
@@ -84,28 +88,64 @@ func (h *EntityHandler) HandleCreateRootEntity(c *gin.Context) {
 // @Tags Entity Management
 // @Accept json
 // @Produce json
-// @Param X-User-ID header string true "User ID"
+// @Param X-Cognito-ID header string true "Cognito ID"
 // @Param entity body dto.CreateSubEntityRequest true "Entity information"
+// @Param Idempotency-Key header string false "Key identifying retries of this request"
 // @Success 201 {object} dto.Response "Sub-entity created successfully"
 // @Failure 400 {object} dto.ErrorResponse "Validation error"
 // @Failure 401 {object} dto.ErrorResponse "User not authenticated"
 // @Failure 404 {object} dto.ErrorResponse "Parent entity not found"
 // @Failure 500 {object} dto.ErrorResponse "Internal server error"
 // @Security ApiKeyAuth
-// @Router /entity/sub [post]
+// @Router /v1/entity/sub [post]
 func (h *EntityHandler) HandleCreateSubEntity(c *gin.Context) {
 	// Parse request body
 	var request dto.CreateSubEntityRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		log.Printf("Error decoding request: %v", err)
+		slog.InfoContext(c.Request.Context(), "Error decoding request", "error", err)
+		response.BadRequest(c, "Invalid request format")
+		return
+	}
+
+	h.createSubEntity(c, request)
+}
+
+// HandleCreateChildEntity handles requests to create a child of the entity in the path
+// @Summary Create a child entity
+// @Description Create a new entity as a child of an existing entity
+// @Tags Entity Management
+// @Accept json
+// @Produce json
+// @Param X-Cognito-ID header string true "Cognito ID"
+// @Param entity_id path string true "Parent entity ID"
+// @Param entity body dto.CreateSubEntityRequest true "Entity information; parentEntityId is taken from the path"
+// @Param Idempotency-Key header string false "Key identifying retries of this request"
+// @Success 201 {object} dto.Response "Sub-entity created successfully"
+// @Failure 400 {object} dto.ErrorResponse "Validation error"
+// @Failure 401 {object} dto.ErrorResponse "User not authenticated"
+// @Failure 404 {object} dto.ErrorResponse "Parent entity not found"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /v2/entities/{entity_id}/children [post]
+func (h *EntityHandler) HandleCreateChildEntity(c *gin.Context) {
+	// Parse request body
+	var request dto.CreateSubEntityRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		slog.InfoContext(c.Request.Context(), "Error decoding request", "error", err)
 		response.BadRequest(c, "Invalid request format")
 		return
 	}
+	request.ParentEntityID = c.Param("entity_id")
 
+	h.createSubEntity(c, request)
+}
+
+// createSubEntity validates request and creates the sub-entity it describes
+func (h *EntityHandler) createSubEntity(c *gin.Context, request dto.CreateSubEntityRequest) {
 	// Validate request
 	validationErrs := utils.Validate(request)
 	if validationErrs != nil {
-		log.Printf("Validation errors: %s", utils.ValidationErrorsToString(validationErrs))
+		slog.InfoContext(c.Request.Context(), "Validation errors", "errors", utils.ValidationErrorsToString(validationErrs))
 		response.ValidationErrors(c, utils.CreateDtoValidationErrors(validationErrs))
 		return
 	}
@@ -127,12 +167,8 @@ func (h *EntityHandler) HandleCreateSubEntity(c *gin.Context) {
 		request.ParentEntityID,
 	)
 	if err != nil {
-		if err.Error() == "user with ID "+userID+" does not have any existing entities" {
-			response.BadRequest(c, "User does not have any existing entities")
-			return
-		}
-		log.Printf("Error creating sub-entity: %v", err)
-		response.InternalError(c, "Failed to create sub-entity")
+		slog.ErrorContext(c.Request.Context(), "Error creating sub-entity", "error", err)
+		c.Error(err)
 		return
 	}
 
@@ -148,12 +184,18 @@ func (h *EntityHandler) HandleCreateSubEntity(c *gin.Context) {
 // @Param entity_id path string true "Entity ID"
 // @Param recursive query bool false "Whether to include all descendants"
 // @Param level query int false "Maximum depth level for descendants (0 for direct children only, -1 for all)"
-// @Param category_type query string false "Filter by category type"
-// @Success 200 {object} dto.Response{data=dto.EntityChildrenResponse} "Entity children retrieved successfully"
+// @Param categoryType query string false "Filter by category type" Enums(user, office, location)
+// @Param categoryId query string false "Filter by category ID"
+// @Param page query int false "Page number, starting at 1 (default: 1)"
+// @Param pageSize query int false "Items per page (default: 20, max: 100)"
+// @Param cursor query string false "Cursor from a previous response's nextCursor"
+// @Param sort query string false "Sort field: name, depth or createdAt; prefix with - for descending"
+// @Success 200 {object} dto.Response{data=dto.PaginatedResponse{items=[]dto.EntityResponse}} "Page of entity children"
 // @Failure 400 {object} dto.ErrorResponse "Invalid request"
 // @Failure 404 {object} dto.ErrorResponse "Entity not found"
 // @Failure 500 {object} dto.ErrorResponse "Internal server error"
-// @Router /entity/{entity_id}/children [get]
+// @Router /v1/entity/{entity_id}/children [get]
+// @Router /v2/entities/{entity_id}/children [get]
 func (h *EntityHandler) HandleGetEntityChildren(c *gin.Context) {
 	// Get entity ID from URL path
 	entityID := c.Param("entity_id")
@@ -169,6 +211,14 @@ func (h *EntityHandler) HandleGetEntityChildren(c *gin.Context) {
 		return
 	}
 
+	// Validate request
+	validationErrs := utils.Validate(request)
+	if validationErrs != nil {
+		slog.InfoContext(c.Request.Context(), "Validation errors", "errors", utils.ValidationErrorsToString(validationErrs))
+		response.ValidationErrors(c, utils.CreateDtoValidationErrors(validationErrs))
+		return
+	}
+
 	// Set level based on recursive flag and level parameter
 	level := 0
 	if request.Recursive {
@@ -179,49 +229,39 @@ func (h *EntityHandler) HandleGetEntityChildren(c *gin.Context) {
 		}
 	}
 
-	// Call service to get entity children
-	entities, err := h.entityService.ListEntityChildren(
-		c.Request.Context(),
-		entityID,
-		level,
-		request.CategoryType,
-	)
+	opts := mappers.PaginationToListOptions(request.PaginationParams, map[string]string{
+		"categoryType": request.CategoryType,
+		"categoryId":   request.CategoryID,
+	})
+
+	// Call service to get one page of entity children
+	page, err := h.entityService.ListEntityChildren(c.Request.Context(), entityID, level, opts)
 	if err != nil {
-		if err.Error() == "entity with ID "+entityID+" not found" {
-			response.NotFound(c, "Entity not found")
-			return
-		}
-		log.Printf("Error getting entity children: %v", err)
-		response.InternalError(c, "Failed to retrieve entity children")
+		slog.ErrorContext(c.Request.Context(), "Error getting entity children", "error", err)
+		c.Error(err)
 		return
 	}
 
-	// Convert entities to responses
-	childResponses := mappers.EntitiesToResponses(entities)
-
-	// Create the response structure
-	result := dto.EntityChildrenResponse{
-		ParentID: entityID,
-		Children: childResponses,
-		Count:    len(childResponses),
-	}
-
-	response.OK(c, result, "Entity children retrieved successfully")
+	response.Paginated(c, mappers.EntitiesToResponses(page.Items), page.TotalItems, page.Page, page.PageSize, page.NextCursor)
 }
 
 // HandleGetEntityHierarchy handles requests to get an entity hierarchy
 // @Summary Get entity hierarchy
-// @Description Get an entity and all its descendants as a hierarchical structure
+// @Description Get an entity and its descendants up to maxDepth levels as a hierarchical structure.
+// @Description Direct children of the root are paged; every node carries childCount so clients can expand lazily.
 // @Tags Entity Management
 // @Accept json
 // @Produce json
 // @Param entity_id path string true "Entity ID"
-// @Param max_depth query int false "Maximum depth to include (default: 10)"
+// @Param maxDepth query int false "Maximum depth to include below the root, 0 for the root alone (default: 10)"
+// @Param limit query int false "Maximum number of direct children of the root to return (default: 50)"
+// @Param cursor query string false "Cursor from a previous response's nextCursor"
 // @Success 200 {object} dto.Response{data=dto.EntityHierarchyResponse} "Entity hierarchy retrieved successfully"
 // @Failure 400 {object} dto.ErrorResponse "Invalid request"
 // @Failure 404 {object} dto.ErrorResponse "Entity not found"
 // @Failure 500 {object} dto.ErrorResponse "Internal server error"
-// @Router /entity/{entity_id}/hierarchy [get]
+// @Router /v1/entity/{entity_id}/hierarchy [get]
+// @Router /v2/entities/{entity_id}/hierarchy [get]
 func (h *EntityHandler) HandleGetEntityHierarchy(c *gin.Context) {
 	// Get entity ID from URL path
 	entityID := c.Param("entity_id")
@@ -230,23 +270,37 @@ func (h *EntityHandler) HandleGetEntityHierarchy(c *gin.Context) {
 		return
 	}
 
+	// Parse query parameters
+	var request dto.GetEntityHierarchyRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		response.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	// Validate request
+	validationErrs := utils.Validate(request)
+	if validationErrs != nil {
+		slog.InfoContext(c.Request.Context(), "Validation errors", "errors", utils.ValidationErrorsToString(validationErrs))
+		response.ValidationErrors(c, utils.CreateDtoValidationErrors(validationErrs))
+		return
+	}
+
 	// Call service to get entity hierarchy
 	hierarchy, err := h.entityService.GetEntityHierarchy(
 		c.Request.Context(),
 		entityID,
+		request.MaxDepth,
+		request.Limit,
+		request.Cursor,
 	)
 	if err != nil {
-		if err.Error() == "entity with ID "+entityID+" not found" {
-			response.NotFound(c, "Entity not found")
-			return
-		}
-		log.Printf("Error getting entity hierarchy: %v", err)
-		response.InternalError(c, "Failed to retrieve entity hierarchy")
+		slog.ErrorContext(c.Request.Context(), "Error getting entity hierarchy", "error", err)
+		c.Error(err)
 		return
 	}
 
-	// Convert hierarchy map to response structure
-	hierarchyResponse := mappers.HierarchyMapToResponse(hierarchy)
+	// Convert hierarchy to response structure
+	hierarchyResponse := mappers.EntityHierarchyToResponse(hierarchy)
 	if hierarchyResponse == nil {
 		response.InternalError(c, "Failed to process entity hierarchy")
 		return
@@ -255,18 +309,147 @@ func (h *EntityHandler) HandleGetEntityHierarchy(c *gin.Context) {
 	response.OK(c, hierarchyResponse, "Entity hierarchy retrieved successfully")
 }
 
+// HandleGetEntityAncestors handles requests to get the ancestors of an entity
+// @Summary Get entity ancestors
+// @Description Get the ordered chain of ancestors of an entity, from the root down, for breadcrumbs
+// @Tags Entity Management
+// @Accept json
+// @Produce json
+// @Param X-Cognito-ID header string true "Cognito ID"
+// @Param entity_id path string true "Entity ID"
+// @Param includeSelf query bool false "Whether to end the chain with the entity itself"
+// @Success 200 {object} dto.Response{data=dto.EntityAncestorsResponse} "Entity ancestors retrieved successfully"
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 401 {object} dto.ErrorResponse "User not authenticated"
+// @Failure 403 {object} dto.ErrorResponse "Entity not accessible"
+// @Failure 404 {object} dto.ErrorResponse "Entity not found"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /v1/entity/{entity_id}/ancestors [get]
+// @Router /v2/entities/{entity_id}/ancestors [get]
+func (h *EntityHandler) HandleGetEntityAncestors(c *gin.Context) {
+	// Get user ID from context (set by auth middleware)
+	userID := middleware.GetUserIDFromGin(c)
+	if userID == "" {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	// Get entity ID from URL path
+	entityID := c.Param("entity_id")
+	if entityID == "" {
+		response.BadRequest(c, "Entity ID is required")
+		return
+	}
+
+	// Parse query parameters
+	var request dto.GetEntityAncestorsRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		response.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	// Call service to get entity ancestors
+	ancestors, err := h.entityService.GetEntityAncestors(c.Request.Context(), userID, entityID, request.IncludeSelf)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Error getting entity ancestors", "error", err)
+		c.Error(err)
+		return
+	}
+
+	result := dto.EntityAncestorsResponse{
+		EntityID:  entityID,
+		Ancestors: mappers.EntityNodesToResponses(ancestors),
+	}
+
+	response.OK(c, result, "Entity ancestors retrieved successfully")
+}
+
+// HandleSearchEntities handles requests to search the authenticated user's entity tree
+// @Summary Search entities
+// @Description Search entities within the subtrees accessible to the authenticated user, shallowest first
+// @Tags Entity Management
+// @Accept json
+// @Produce json
+// @Param X-Cognito-ID header string true "Cognito ID"
+// @Param name query string false "Case-insensitive name prefix"
+// @Param categoryId query string false "Filter by category ID"
+// @Param categoryType query string false "Filter by category type"
+// @Param details query string false "JSON object that entity details must contain"
+// @Param limit query int false "Maximum number of results (default: 50)"
+// @Success 200 {object} dto.Response{data=dto.EntitySearchResponse} "Entities retrieved successfully"
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 401 {object} dto.ErrorResponse "User not authenticated"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /v1/entity/search [get]
+// @Router /v2/entities/search [get]
+func (h *EntityHandler) HandleSearchEntities(c *gin.Context) {
+	userID := middleware.GetUserIDFromGin(c)
+	if userID == "" {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	// Parse query parameters
+	var request dto.SearchEntitiesRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		response.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	// Validate request
+	validationErrs := utils.Validate(request)
+	if validationErrs != nil {
+		slog.InfoContext(c.Request.Context(), "Validation errors", "errors", utils.ValidationErrorsToString(validationErrs))
+		response.ValidationErrors(c, utils.CreateDtoValidationErrors(validationErrs))
+		return
+	}
+
+	var details map[string]any
+	if request.Details != "" {
+		if err := json.Unmarshal([]byte(request.Details), &details); err != nil {
+			response.BadRequest(c, "details must be a JSON object")
+			return
+		}
+	}
+
+	// Call service to search entities
+	results, err := h.entityService.SearchEntities(c.Request.Context(), repositories.EntitySearchFilter{
+		UserID:       userID,
+		NamePrefix:   request.Name,
+		CategoryID:   request.CategoryID,
+		CategoryType: request.CategoryType,
+		Details:      details,
+		Limit:        request.Limit,
+	})
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Error searching entities", "error", err)
+		c.Error(err)
+		return
+	}
+
+	result := dto.EntitySearchResponse{
+		Results: mappers.EntityNodesToResponses(results),
+		Count:   len(results),
+	}
+
+	response.OK(c, result, "Entities retrieved successfully")
+}
+
 // HandleCheckEntityPresence handles requests to check if a user has any entities
 // @Summary Check entity presence
 // @Description Check if the authenticated user has any entities
 // @Tags Entity Management
 // @Accept json
 // @Produce json
-// @Param X-User-ID header string true "User ID"
+// @Param X-Cognito-ID header string true "Cognito ID"
 // @Success 200 {object} dto.Response{data=map[string]bool} "Entity presence check successful"
 // @Failure 401 {object} dto.ErrorResponse "User not authenticated"
 // @Failure 500 {object} dto.ErrorResponse "Internal server error"
 // @Security ApiKeyAuth
-// @Router /user/has-entity [get]
+// @Router /v1/user/has-entity [get]
+// @Router /v2/entities/mine [get]
 func (h *EntityHandler) HandleCheckEntityPresence(c *gin.Context) {
 	userID := middleware.GetUserIDFromGin(c)
 	if userID == "" {
@@ -277,8 +460,8 @@ func (h *EntityHandler) HandleCheckEntityPresence(c *gin.Context) {
 	// Check if the user has an entity
 	hasEntity, err := h.entityService.CheckEntityExists(c.Request.Context(), userID)
 	if err != nil {
-		log.Printf("Error checking entity presence: %v", err)
-		response.InternalError(c, "Failed to check entity presence")
+		slog.ErrorContext(c.Request.Context(), "Error checking entity presence", "error", err)
+		c.Error(err)
 		return
 	}
 
@@ -287,8 +470,8 @@ func (h *EntityHandler) HandleCheckEntityPresence(c *gin.Context) {
 	if hasEntity {
 		entityID, err := h.entityService.GetEntityID(c.Request.Context(), userID)
 		if err != nil {
-			log.Printf("Error fetching entity ID: %v", err)
-			response.InternalError(c, "Failed to fetch entity ID")
+			slog.ErrorContext(c.Request.Context(), "Error fetching entity ID", "error", err)
+			c.Error(err)
 			return
 		}
 		result["entityId"] = entityID
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/middleware"
+	"github.com/afreedicp/zolaris-backend-app/internal/services"
+	"github.com/afreedicp/zolaris-backend-app/internal/transport/dto"
+	"github.com/afreedicp/zolaris-backend-app/internal/transport/mappers"
+	"github.com/afreedicp/zolaris-backend-app/internal/transport/response"
+	"github.com/afreedicp/zolaris-backend-app/internal/utils"
+)
+
+// EntityHistoryHandler handles requests for the change history of entities
+type EntityHistoryHandler struct {
+	historyService *services.EntityHistoryService
+}
+
+// NewEntityHistoryHandler creates a new EntityHistoryHandler
+func NewEntityHistoryHandler(historyService *services.EntityHistoryService) *EntityHistoryHandler {
+	return &EntityHistoryHandler{historyService: historyService}
+}
+
+// HandleGetEntityHistory handles requests to get the change history of an entity
+// @Summary Get entity history
+// @Description Get every recorded create, update, move and delete of an entity with its actor and before/after values, newest first
+// @Tags Entity Management
+// @Produce json
+// @Param X-Cognito-ID header string true "Cognito ID"
+// @Param entity_id path string true "Entity ID"
+// @Param limit query int false "Maximum number of entries (default: 100)"
+// @Success 200 {object} dto.Response{data=dto.EntityHistoryResponse} "Entity history retrieved successfully"
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 401 {object} dto.ErrorResponse "User not authenticated"
+// @Failure 403 {object} dto.ErrorResponse "Entity not accessible"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /v1/entity/{entity_id}/history [get]
+// @Router /v2/entities/{entity_id}/history [get]
+func (h *EntityHistoryHandler) HandleGetEntityHistory(c *gin.Context) {
+	userID := middleware.GetUserIDFromGin(c)
+	if userID == "" {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	// Get entity ID from URL path
+	entityID := c.Param("entity_id")
+	if entityID == "" {
+		response.BadRequest(c, "Entity ID is required")
+		return
+	}
+
+	// Parse query parameters
+	var request dto.EntityHistoryRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		response.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	// Validate request
+	validationErrs := utils.Validate(request)
+	if validationErrs != nil {
+		slog.InfoContext(c.Request.Context(), "Validation errors", "errors", utils.ValidationErrorsToString(validationErrs))
+		response.ValidationErrors(c, utils.CreateDtoValidationErrors(validationErrs))
+		return
+	}
+
+	// Call service to get the history
+	entries, err := h.historyService.GetEntityHistory(c.Request.Context(), userID, entityID, request.Limit)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Error getting entity history", "error", err)
+		c.Error(err)
+		return
+	}
+
+	result := dto.EntityHistoryResponse{
+		EntityID: entityID,
+		Entries:  mappers.EntityHistoryToResponses(entries),
+		Count:    len(entries),
+	}
+
+	response.OK(c, result, "Entity history retrieved successfully")
+}
+
+// HandleDiffEntityTree handles requests to diff an entity subtree between two points in time
+// @Summary Diff entity subtree
+// @Description List the entities added, removed, moved and renamed within an entity's subtree between two timestamps
+// @Tags Entity Management
+// @Produce json
+// @Param X-Cognito-ID header string true "Cognito ID"
+// @Param entity_id path string true "Entity ID"
+// @Param from query string true "Start of the comparison (RFC 3339)"
+// @Param to query string false "End of the comparison (RFC 3339, default: now)"
+// @Success 200 {object} dto.Response{data=dto.EntityTreeDiffResponse} "Entity diff retrieved successfully"
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 401 {object} dto.ErrorResponse "User not authenticated"
+// @Failure 403 {object} dto.ErrorResponse "Entity not accessible"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /v1/entity/{entity_id}/diff [get]
+// @Router /v2/entities/{entity_id}/diff [get]
+func (h *EntityHistoryHandler) HandleDiffEntityTree(c *gin.Context) {
+	userID := middleware.GetUserIDFromGin(c)
+	if userID == "" {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	// Get entity ID from URL path
+	entityID := c.Param("entity_id")
+	if entityID == "" {
+		response.BadRequest(c, "Entity ID is required")
+		return
+	}
+
+	// Parse query parameters
+	var request dto.EntityDiffRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		response.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	// Validate request
+	validationErrs := utils.Validate(request)
+	if validationErrs != nil {
+		slog.InfoContext(c.Request.Context(), "Validation errors", "errors", utils.ValidationErrorsToString(validationErrs))
+		response.ValidationErrors(c, utils.CreateDtoValidationErrors(validationErrs))
+		return
+	}
+
+	from, err := time.Parse(time.RFC3339, request.From)
+	if err != nil {
+		response.BadRequest(c, "from must be an RFC 3339 timestamp")
+		return
+	}
+
+	to := time.Now()
+	if request.To != "" {
+		if to, err = time.Parse(time.RFC3339, request.To); err != nil {
+			response.BadRequest(c, "to must be an RFC 3339 timestamp")
+			return
+		}
+	}
+
+	if !from.Before(to) {
+		response.BadRequest(c, "from must be before to")
+		return
+	}
+
+	// Call service to diff the subtree
+	diff, err := h.historyService.DiffSubtree(c.Request.Context(), userID, entityID, from, to)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Error diffing entity tree", "error", err)
+		c.Error(err)
+		return
+	}
+
+	response.OK(c, mappers.EntityTreeDiffToResponse(entityID, diff), "Entity diff retrieved successfully")
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/middleware"
+	"github.com/afreedicp/zolaris-backend-app/internal/services"
+	"github.com/afreedicp/zolaris-backend-app/internal/transport/dto"
+	"github.com/afreedicp/zolaris-backend-app/internal/transport/mappers"
+	"github.com/afreedicp/zolaris-backend-app/internal/transport/response"
+	"github.com/afreedicp/zolaris-backend-app/internal/utils"
+)
+
+// EntityMetricsHandler handles requests for sensor metrics aggregated over the entity hierarchy
+type EntityMetricsHandler struct {
+	metricsService *services.EntityMetricsService
+}
+
+// NewEntityMetricsHandler creates a new EntityMetricsHandler
+func NewEntityMetricsHandler(metricsService *services.EntityMetricsService) *EntityMetricsHandler {
+	return &EntityMetricsHandler{metricsService: metricsService}
+}
+
+// HandleGetEntityMetrics handles requests to get sensor metrics rolled up over an entity subtree
+// @Summary Get entity metrics
+// @Description Aggregate the sensor readings of every device in an entity's subtree, for the whole subtree and per direct child entity
+// @Tags Entity Management
+// @Produce json
+// @Param X-Cognito-ID header string true "Cognito ID"
+// @Param entity_id path string true "Entity ID"
+// @Param timestamp query string false "End of the time range in Unix milliseconds (default: now)"
+// @Param dateMode query string false "Length of the time range: hourly, daily (default), weekly, monthly or yearly"
+// @Success 200 {object} dto.Response{data=dto.SubtreeMetricsResponse} "Entity metrics retrieved successfully"
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 401 {object} dto.ErrorResponse "User not authenticated"
+// @Failure 403 {object} dto.ErrorResponse "Entity not accessible"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /v1/entity/{entity_id}/metrics [get]
+// @Router /v2/entities/{entity_id}/metrics [get]
+func (h *EntityMetricsHandler) HandleGetEntityMetrics(c *gin.Context) {
+	userID := middleware.GetUserIDFromGin(c)
+	if userID == "" {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	// Get entity ID from URL path
+	entityID := c.Param("entity_id")
+	if entityID == "" {
+		response.BadRequest(c, "Entity ID is required")
+		return
+	}
+
+	// Parse query parameters
+	var request dto.EntityMetricsRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		response.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	// Validate request
+	validationErrs := utils.Validate(request)
+	if validationErrs != nil {
+		slog.InfoContext(c.Request.Context(), "Validation errors", "errors", utils.ValidationErrorsToString(validationErrs))
+		response.ValidationErrors(c, utils.CreateDtoValidationErrors(validationErrs))
+		return
+	}
+
+	timestampMs := time.Now().UnixMilli()
+	if request.Timestamp != "" {
+		parsed, err := strconv.ParseInt(request.Timestamp, 10, 64)
+		if err != nil {
+			response.BadRequest(c, "Invalid timestamp")
+			return
+		}
+		timestampMs = parsed
+	}
+
+	dateMode := request.DateMode
+	if dateMode == "" {
+		dateMode = "daily"
+	}
+
+	// Call service to aggregate the metrics
+	metrics, err := h.metricsService.GetSubtreeMetrics(c.Request.Context(), userID, entityID, timestampMs, dateMode)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Error getting entity metrics", "error", err)
+		c.Error(err)
+		return
+	}
+
+	response.OK(c, mappers.SubtreeMetricsToResponse(metrics), "Entity metrics retrieved successfully")
+}
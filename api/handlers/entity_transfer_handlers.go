@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+	"github.com/afreedicp/zolaris-backend-app/internal/middleware"
+	"github.com/afreedicp/zolaris-backend-app/internal/services"
+	"github.com/afreedicp/zolaris-backend-app/internal/transport/dto"
+	"github.com/afreedicp/zolaris-backend-app/internal/transport/mappers"
+	"github.com/afreedicp/zolaris-backend-app/internal/transport/response"
+	"github.com/afreedicp/zolaris-backend-app/internal/utils"
+)
+
+// maxImportBodyBytes bounds the size of an uploaded import document
+const maxImportBodyBytes = 10 << 20
+
+// EntityTransferHandler handles bulk import and export of entity trees
+type EntityTransferHandler struct {
+	transferService *services.EntityTransferService
+}
+
+// NewEntityTransferHandler creates a new EntityTransferHandler
+func NewEntityTransferHandler(transferService *services.EntityTransferService) *EntityTransferHandler {
+	return &EntityTransferHandler{transferService: transferService}
+}
+
+// HandleImportEntities handles requests to bulk import an entity tree with devices
+// @Summary Import entities
+// @Description Import a nested JSON entity tree, or a parent-reference CSV (Content-Type text/csv), in a single transaction.
+// @Description All rows are validated first and rejected rows are reported individually. With dryRun nothing is persisted.
+// @Tags Entity Management
+// @Accept json
+// @Accept text/csv
+// @Produce json
+// @Param X-Cognito-ID header string true "Cognito ID"
+// @Param dryRun query bool false "Validate and simulate the import without persisting it"
+// @Param parentEntityId query string false "Entity to import under (defaults to the user's entity)"
+// @Param document body dto.EntityTreeDocument false "Entity tree (JSON imports)"
+// @Success 201 {object} dto.Response{data=dto.EntityImportResponse} "Entities imported successfully"
+// @Success 200 {object} dto.Response{data=dto.EntityImportResponse} "Dry run completed successfully"
+// @Failure 400 {object} dto.ErrorResponse "Invalid request or rejected rows"
+// @Failure 401 {object} dto.ErrorResponse "User not authenticated"
+// @Failure 403 {object} dto.ErrorResponse "Parent entity not accessible"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /v1/entity/import [post]
+// @Router /v2/entities/import [post]
+func (h *EntityTransferHandler) HandleImportEntities(c *gin.Context) {
+	userID := middleware.GetUserIDFromGin(c)
+	if userID == "" {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	// Parse query parameters
+	var request dto.ImportEntitiesRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		response.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	// Validate request
+	validationErrs := utils.Validate(request)
+	if validationErrs != nil {
+		slog.InfoContext(c.Request.Context(), "Validation errors", "errors", utils.ValidationErrorsToString(validationErrs))
+		response.ValidationErrors(c, utils.CreateDtoValidationErrors(validationErrs))
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBodyBytes)
+
+	// Parse the document in whichever format was uploaded
+	var rows []domain.EntityImportRow
+	parentEntityID := request.ParentEntityID
+	if strings.HasPrefix(c.ContentType(), "text/csv") {
+		csvRows, rowErrors, err := mappers.CSVToImportRows(c.Request.Body)
+		if err != nil {
+			slog.InfoContext(c.Request.Context(), "Error parsing import CSV", "error", err)
+			response.BadRequest(c, "Invalid CSV document")
+			return
+		}
+		if len(rowErrors) > 0 {
+			response.ImportErrors(c, mappers.ImportErrorsToResponses(rowErrors))
+			return
+		}
+		rows = csvRows
+	} else {
+		var document dto.EntityTreeDocument
+		if err := c.ShouldBindJSON(&document); err != nil {
+			slog.InfoContext(c.Request.Context(), "Error decoding request", "error", err)
+			response.BadRequest(c, "Invalid request format")
+			return
+		}
+		if parentEntityID == "" {
+			parentEntityID = document.ParentEntityID
+		}
+		rows = mappers.TreeDocumentToImportRows(&document)
+	}
+
+	// Call service to import the rows
+	result, err := h.transferService.ImportEntities(c.Request.Context(), userID, parentEntityID, rows, request.DryRun)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Error importing entities", "error", err)
+		c.Error(err)
+		return
+	}
+
+	if len(result.Errors) > 0 {
+		response.ImportErrors(c, mappers.ImportErrorsToResponses(result.Errors))
+		return
+	}
+
+	if result.DryRun {
+		response.OK(c, mappers.ImportResultToResponse(result), "Dry run completed successfully")
+		return
+	}
+
+	response.Created(c, mappers.ImportResultToResponse(result), "Entities imported successfully")
+}
+
+// HandleExportEntities handles requests to export an entity subtree with devices
+// @Summary Export entities
+// @Description Export an entity and all of its descendants, including assigned devices, as a nested JSON tree or a parent-reference CSV.
+// @Description Both formats can be imported again.
+// @Tags Entity Management
+// @Produce json
+// @Produce text/csv
+// @Param X-Cognito-ID header string true "Cognito ID"
+// @Param entity_id path string true "Entity ID"
+// @Param format query string false "Export format: json (default) or csv"
+// @Success 200 {object} dto.Response{data=dto.EntityTreeDocument} "Entities exported successfully"
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 401 {object} dto.ErrorResponse "User not authenticated"
+// @Failure 403 {object} dto.ErrorResponse "Entity not accessible"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Security ApiKeyAuth
+// @Router /v1/entity/{entity_id}/export [get]
+// @Router /v2/entities/{entity_id}/export [get]
+func (h *EntityTransferHandler) HandleExportEntities(c *gin.Context) {
+	userID := middleware.GetUserIDFromGin(c)
+	if userID == "" {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	// Get entity ID from URL path
+	entityID := c.Param("entity_id")
+	if entityID == "" {
+		response.BadRequest(c, "Entity ID is required")
+		return
+	}
+
+	// Parse query parameters
+	var request dto.ExportEntitiesRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		response.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	// Validate request
+	validationErrs := utils.Validate(request)
+	if validationErrs != nil {
+		slog.InfoContext(c.Request.Context(), "Validation errors", "errors", utils.ValidationErrorsToString(validationErrs))
+		response.ValidationErrors(c, utils.CreateDtoValidationErrors(validationErrs))
+		return
+	}
+
+	// Call service to export the subtree
+	subtree, err := h.transferService.ExportSubtree(c.Request.Context(), userID, entityID)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Error exporting entities", "error", err)
+		c.Error(err)
+		return
+	}
+
+	if request.Format == "csv" {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="entity-%s.csv"`, entityID))
+		c.Status(http.StatusOK)
+		if err := mappers.WriteSubtreeCSV(c.Writer, subtree); err != nil {
+			slog.ErrorContext(c.Request.Context(), "Error writing export CSV", "error", err)
+		}
+		return
+	}
+
+	response.OK(c, mappers.SubtreeToTreeDocument(subtree), "Entities exported successfully")
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/health"
+)
+
+// HealthHandler serves the liveness and readiness probes
+type HealthHandler struct {
+	checker *health.Checker
+}
+
+// NewHealthHandler creates a new HealthHandler
+func NewHealthHandler(checker *health.Checker) *HealthHandler {
+	return &HealthHandler{checker: checker}
+}
+
+// HandleHealth reports that the API is running
+// @Summary Health check
+// @Description Check if the API is running
+// @Tags System
+// @Produce plain
+// @Success 200 {string} string "OK"
+// @Router /health [get]
+func (h *HealthHandler) HandleHealth(c *gin.Context) {
+	c.String(http.StatusOK, "OK")
+}
+
+// HandleLivez reports that the process is serving requests. It checks no
+// dependency, so an outage of one does not get the instance restarted.
+// @Summary Liveness probe
+// @Description Check that the process is serving requests
+// @Tags System
+// @Produce json
+// @Success 200 {object} map[string]string "Process is alive"
+// @Router /livez [get]
+func (h *HealthHandler) HandleLivez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusUp})
+}
+
+// HandleReadyz reports whether the dependencies are usable, with the status of each
+// @Summary Readiness probe
+// @Description Check PostgreSQL, the schema version, the DynamoDB tables and the IoT configuration. Results are cached for a few seconds.
+// @Tags System
+// @Produce json
+// @Success 200 {object} health.Report "Every dependency is up"
+// @Failure 503 {object} health.Report "A dependency is down"
+// @Router /readyz [get]
+func (h *HealthHandler) HandleReadyz(c *gin.Context) {
+	report := h.checker.Report(c.Request.Context())
+	status := http.StatusOK
+	if !report.Up() {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
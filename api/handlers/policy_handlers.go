@@ -1,7 +1,7 @@
 package handlers
 
 import (
-	"log"
+	"log/slog"
 
 	"github.com/gin-gonic/gin"
 
@@ -31,12 +31,13 @@ func NewAttachIotPolicyHandler(policyService *services.PolicyService) *AttachIot
 // @Success 200 {object} dto.Response "IoT policy attached successfully"
 // @Failure 400 {object} dto.ErrorResponse "Invalid request or validation error"
 // @Failure 500 {object} dto.ErrorResponse "Failed to attach IoT policy"
-// @Router /device/attach-policy [post]
+// @Router /v1/device/attach-policy [post]
+// @Router /v2/iot/policy-attachments [post]
 func (h *AttachIotPolicyHandler) HandleGin(c *gin.Context) {
 	// Parse request body
 	var request dto.PolicyAttachRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		log.Printf("Error decoding request: %v", err)
+		slog.InfoContext(c.Request.Context(), "Error decoding request", "error", err)
 		response.BadRequest(c, "Invalid request format")
 		return
 	}
@@ -44,15 +45,15 @@ func (h *AttachIotPolicyHandler) HandleGin(c *gin.Context) {
 	// Validate request
 	validationErrs := utils.Validate(request)
 	if validationErrs != nil {
-		log.Printf("Validation errors: %s", utils.ValidationErrorsToString(validationErrs))
+		slog.InfoContext(c.Request.Context(), "Validation errors", "errors", utils.ValidationErrorsToString(validationErrs))
 		response.ValidationErrors(c, utils.CreateDtoValidationErrors(validationErrs))
 		return
 	}
 
 	// Call service to attach policy
 	if err := h.policyService.AttachIoTPolicy(c.Request.Context(), request.IdentityID); err != nil {
-		log.Printf("Error attaching IoT policy: %v", err)
-		response.InternalError(c, "Failed to attach IoT policy")
+		slog.ErrorContext(c.Request.Context(), "Error attaching IoT policy", "error", err)
+		c.Error(err)
 		return
 	}
 
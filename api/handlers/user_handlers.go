@@ -1,7 +1,7 @@
 package handlers
 
 import (
-	"log"
+	"log/slog"
 
 	"github.com/gin-gonic/gin"
 
@@ -33,7 +33,8 @@ func NewUserHandler(userService *services.UserService) *UserHandler {
 // @Failure 404 {object} dto.ErrorResponse "User not found"
 // @Failure 500 {object} dto.ErrorResponse "Internal server error"
 // @Security ApiKeyAuth
-// @Router /user/details [get]
+// @Router /v1/user/details [get]
+// @Router /v2/users/me [get]
 func (h *UserHandler) HandleGetUserDetails(c *gin.Context) {
 	// Get user ID from context (set by auth middleware)
 	userID := middleware.GetUserIDFromGin(c)
@@ -45,8 +46,8 @@ func (h *UserHandler) HandleGetUserDetails(c *gin.Context) {
 	// Get user from service
 	user, err := h.userService.GetUserByID(c.Request.Context(), userID)
 	if err != nil {
-		log.Printf("Error retrieving user details: %v", err)
-		response.InternalError(c, "Failed to retrieve user details")
+		slog.ErrorContext(c.Request.Context(), "Error retrieving user details", "error", err)
+		c.Error(err)
 		return
 	}
 
@@ -66,14 +67,16 @@ func (h *UserHandler) HandleGetUserDetails(c *gin.Context) {
 // @Tags User Management
 // @Accept json
 // @Produce json
-// @Param X-User-ID header string true "User ID"
+// @Param X-Cognito-ID header string true "Cognito ID"
 // @Param user body dto.UserDetailsRequest true "User details"
 // @Success 200 {object} dto.Response{data=dto.UserResponse} "User details updated successfully"
 // @Failure 400 {object} dto.ErrorResponse "Validation error"
 // @Failure 401 {object} dto.ErrorResponse "User not authenticated"
+// @Failure 404 {object} dto.ErrorResponse "User not found"
 // @Failure 500 {object} dto.ErrorResponse "Internal server error"
 // @Security ApiKeyAuth
-// @Router /user/details [post]
+// @Router /v1/user/details [post]
+// @Router /v2/users/me [put]
 func (h *UserHandler) HandleUpdateUserDetails(c *gin.Context) {
 	// Get user ID from context (set by auth middleware)
 	userID := middleware.GetUserIDFromGin(c)
@@ -85,7 +88,7 @@ func (h *UserHandler) HandleUpdateUserDetails(c *gin.Context) {
 	// Parse request body
 	var request dto.UserDetailsRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		log.Printf("Error decoding request: %v", err)
+		slog.InfoContext(c.Request.Context(), "Error decoding request", "error", err)
 		response.BadRequest(c, "Invalid request format")
 		return
 	}
@@ -93,7 +96,7 @@ func (h *UserHandler) HandleUpdateUserDetails(c *gin.Context) {
 	// Validate request
 	validationErrs := utils.Validate(request)
 	if validationErrs != nil {
-		log.Printf("Validation errors: %s", utils.ValidationErrorsToString(validationErrs))
+		slog.InfoContext(c.Request.Context(), "Validation errors", "errors", utils.ValidationErrorsToString(validationErrs))
 
 		// Convert validation errors to DTO format
 		var validationErrDTOs []dto.ValidationError
@@ -111,8 +114,8 @@ func (h *UserHandler) HandleUpdateUserDetails(c *gin.Context) {
 	// Update user details
 	updatedUser, err := h.userService.UpdateUserDetails(c.Request.Context(), userID, &request)
 	if err != nil {
-		log.Printf("Error updating user details: %v", err)
-		response.InternalError(c, "Failed to update user details")
+		slog.ErrorContext(c.Request.Context(), "Error updating user details", "error", err)
+		c.Error(err)
 		return
 	}
 
@@ -126,11 +129,11 @@ func (h *UserHandler) HandleUpdateUserDetails(c *gin.Context) {
 // @Description Checks if the authenticated user has a parent ID set in their profile
 // @Tags User Management
 // @Produce json
-// @Param X-User-ID header string true "User ID"
+// @Param X-Cognito-ID header string true "Cognito ID"
 // @Success 200 {object} map[string]bool "Returns has_parent_id flag"
 // @Failure 400 {object} map[string]string "Error when user ID is not found in context"
 // @Failure 500 {object} map[string]string "Error when checking parent ID fails"
-// @Router /user/check-parent-id [get]
+// @Router /v1/user/check-parent-id [get]
 func (h *UserHandler) HandleCheckHasParentID(c *gin.Context) {
 	// Extract user ID from the request context
 	userID, exists := c.Get("userID")
@@ -142,8 +145,8 @@ func (h *UserHandler) HandleCheckHasParentID(c *gin.Context) {
 	// Check if the user has a parent ID
 	hasParentID, err := h.userService.CheckHasParentID(c.Request.Context(), userID.(string))
 	if err != nil {
-		log.Printf("Error checking parent ID: %v", err)
-		response.InternalError(c, "Failed to check parent ID")
+		slog.ErrorContext(c.Request.Context(), "Error checking parent ID", "error", err)
+		c.Error(err)
 		return
 	}
 
@@ -155,28 +158,52 @@ func (h *UserHandler) HandleCheckHasParentID(c *gin.Context) {
 // @Description Retrieve a list of users referred by the authenticated user
 // @Tags User Management
 // @Produce json
-// @Param X-User-ID header string true "User ID"
-// @Success 200 {object} dto.Response{data=[]dto.UserResponse} "Referred users retrieved successfully"
-// @Failure 400 {object} dto.ErrorResponse "User ID not found in context"
+// @Param X-Cognito-ID header string true "Cognito ID"
+// @Param page query int false "Page number, starting at 1 (default: 1)"
+// @Param pageSize query int false "Items per page (default: 20, max: 100)"
+// @Param cursor query string false "Cursor from a previous response's nextCursor"
+// @Param sort query string false "Sort field: email, firstName or createdAt; prefix with - for descending"
+// @Param role query string false "Filter by role" Enums(admin, user)
+// @Success 200 {object} dto.Response{data=dto.PaginatedResponse{items=[]dto.UserResponse}} "Page of referred users"
+// @Failure 400 {object} dto.ErrorResponse "Invalid request or user ID not found in context"
 // @Failure 500 {object} dto.ErrorResponse "Internal server error"
 // @Security ApiKeyAuth
-// @Router /user/referrals [get]
+// @Router /v1/user/referrals [get]
+// @Router /v2/users/me/referrals [get]
 func (h *UserHandler) HandleListReferredUsers(c *gin.Context) {
-	log.Printf("Error listing referred usersasd:")
 	userID, exists := c.Get("userID")
 	if !exists {
 		response.BadRequest(c, "User ID not found in context")
 		return
 	}
 
-	referredUsers, err := h.userService.ListReferredUsers(c.Request.Context(), userID.(string))
+	// Parse query parameters
+	var request dto.ListReferredUsersRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		response.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	// Validate request
+	validationErrs := utils.Validate(request)
+	if validationErrs != nil {
+		slog.InfoContext(c.Request.Context(), "Validation errors", "errors", utils.ValidationErrorsToString(validationErrs))
+		response.ValidationErrors(c, utils.CreateDtoValidationErrors(validationErrs))
+		return
+	}
+
+	opts := mappers.PaginationToListOptions(request.PaginationParams, map[string]string{
+		"role": request.Role,
+	})
+
+	page, err := h.userService.ListReferredUsers(c.Request.Context(), userID.(string), opts)
 	if err != nil {
-		log.Printf("Error listing referred users: %v", err)
-		response.InternalError(c, "Failed to list referred users")
+		slog.ErrorContext(c.Request.Context(), "Error listing referred users", "error", err)
+		c.Error(err)
 		return
 	}
 
-	response.OK(c, mappers.UsersToResponses(referredUsers), "Referred users retrieved successfully")
+	response.Paginated(c, mappers.UsersToResponses(page.Items), page.TotalItems, page.Page, page.PageSize, page.NextCursor)
 }
 
 
@@ -188,25 +215,23 @@ func (h *UserHandler) HandleListReferredUsers(c *gin.Context) {
 // @Tags User Management
 // @Accept json
 // @Produce json
-// @Param X-User-ID header string true "User ID"
 // @Param user body dto.UserDetailsRequest true "User details"
 // @Success 201 {object} dto.Response{data=dto.UserResponse} "User details created successfully"
 // @Failure 400 {object} dto.ErrorResponse "Validation error"
-// @Failure 401 {object} dto.ErrorResponse "User not authenticated"
 // @Failure 500 {object} dto.ErrorResponse "Internal server error"
-// @Security ApiKeyAuth
-// @Router /user/createUser [post]
+// @Router /v1/user/createUser [post]
+// @Router /v2/users [post]
 func (h *UserHandler) CreateUserDetails(c *gin.Context) {
 
 	var request dto.UserDetailsRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		log.Printf("Invalid request format: %v", err)
+		slog.InfoContext(c.Request.Context(), "Invalid request format", "error", err)
 		response.BadRequest(c, "Invalid request format")
 		return
 	}
 	// Validate input
 	if validationErrs := utils.Validate(request); validationErrs != nil {
-		log.Printf("Validation errors: %s", utils.ValidationErrorsToString(validationErrs))
+		slog.InfoContext(c.Request.Context(), "Validation errors", "errors", utils.ValidationErrorsToString(validationErrs))
 		var validationErrDTOs []dto.ValidationError
 		for _, ve := range validationErrs {
 			validationErrDTOs = append(validationErrDTOs, dto.ValidationError{
@@ -220,8 +245,8 @@ func (h *UserHandler) CreateUserDetails(c *gin.Context) {
 	// Call service to create user
 	createdUser, err := h.userService.CreateUser(c.Request.Context(), &request)
 	if err != nil {
-		log.Printf("Failed to create user: %v", err)
-		response.InternalError(c, "Failed to create user")
+		slog.ErrorContext(c.Request.Context(), "Failed to create user", "error", err)
+		c.Error(err)
 		return
 	}
 
@@ -0,0 +1,126 @@
+// Package routes registers the versioned API routes on a Gin engine. Each
+// version is a table of routes; the groups of a route select the middleware
+// it is served with, such as authentication and its rate limit.
+package routes
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/afreedicp/zolaris-backend-app/api/handlers"
+	"github.com/afreedicp/zolaris-backend-app/internal/middleware"
+)
+
+// LegacyDeprecated is when the unversioned routes were deprecated in favour of /v1
+var LegacyDeprecated = time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+
+// Group selects the middleware a route is served with. The public groups are
+// named after their rate limits.
+type Group string
+
+const (
+	Public        Group = "public"
+	Signup        Group = "signup"
+	Device        Group = "device"
+	Authenticated Group = "authenticated"
+	Admin         Group = "admin"
+)
+
+// Groups holds the middleware of every group
+type Groups map[Group][]gin.HandlerFunc
+
+// Route is one endpoint of a version, with its path relative to the version prefix
+type Route struct {
+	Method  string
+	Path    string
+	Group   Group
+	Handler gin.HandlerFunc
+}
+
+// Version is a table of routes served under a common prefix
+type Version struct {
+	Prefix string
+	Routes []Route
+}
+
+// Handlers are the handlers the routes are served by
+type Handlers struct {
+	Entity              *handlers.EntityHandler
+	EntityTransfer      *handlers.EntityTransferHandler
+	EntityMetrics       *handlers.EntityMetricsHandler
+	EntityHistory       *handlers.EntityHistoryHandler
+	Audit               *handlers.AuditHandler
+	User                *handlers.UserHandler
+	AddDevice           *handlers.AddDeviceHandler
+	AttachIotPolicy     *handlers.AttachIotPolicyHandler
+	GetDeviceSensorData *handlers.GetDeviceSensorDataHandler
+	ListUserDevices     *handlers.ListUserDevicesHandler
+	AddCategory         *handlers.AddCategoryHandler
+	GetCategoriesByType *handlers.GetCategoriesByTypeHandler
+	ListAllCategories   *handlers.ListAllCategoriesHandler
+}
+
+// Registry holds the versions of the API
+type Registry struct {
+	versions []Version
+	// legacySunset is announced on the unversioned copies of the v1 routes
+	legacySunset time.Time
+}
+
+// New creates the registry of every version of the API. The v1 routes are also
+// served without a prefix, as the API was first published, announcing
+// legacySunset as the time those copies stop working.
+func New(h Handlers, legacySunset time.Time) *Registry {
+	return &Registry{
+		versions: []Version{
+			{Prefix: "/v1", Routes: v1(h)},
+			{Prefix: "/v2", Routes: v2(h)},
+		},
+		legacySunset: legacySunset,
+	}
+}
+
+// Versions returns the versions of the API, oldest first
+func (r *Registry) Versions() []Version {
+	return r.versions
+}
+
+// Register adds every route to router, served with the middleware of its group
+func (r *Registry) Register(router gin.IRoutes, groups Groups) {
+	for _, version := range r.versions {
+		for _, route := range version.Routes {
+			router.Handle(route.Method, version.Prefix+route.Path, chain(groups, route)...)
+		}
+	}
+
+	// The unversioned paths keep working until they are sunset, pointing to /v1
+	deprecation := middleware.GinDeprecationMiddleware(LegacyDeprecated, r.legacySunset, "/v1")
+	for _, route := range r.versions[0].Routes {
+		router.Handle(route.Method, route.Path, append([]gin.HandlerFunc{deprecation}, chain(groups, route)...)...)
+	}
+}
+
+// chain returns the middleware of the group of route followed by its handler
+func chain(groups Groups, route Route) []gin.HandlerFunc {
+	middleware, ok := groups[route.Group]
+	if !ok {
+		panic("routes: no middleware for group " + string(route.Group) + " of " + route.Method + " " + route.Path)
+	}
+	return append(slices.Clone(middleware), route.Handler)
+}
+
+// get, post and put build the routes of the version tables
+func get(path string, group Group, handler gin.HandlerFunc) Route {
+	return Route{Method: http.MethodGet, Path: path, Group: group, Handler: handler}
+}
+
+func post(path string, group Group, handler gin.HandlerFunc) Route {
+	return Route{Method: http.MethodPost, Path: path, Group: group, Handler: handler}
+}
+
+func put(path string, group Group, handler gin.HandlerFunc) Route {
+	return Route{Method: http.MethodPut, Path: path, Group: group, Handler: handler}
+}
@@ -0,0 +1,112 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/afreedicp/zolaris-backend-app/docs"
+	"github.com/afreedicp/zolaris-backend-app/internal/openapi"
+)
+
+// newTestRouter registers the routes with group middleware that answers with
+// the group name instead of running the handlers
+func newTestRouter(t *testing.T, sunset time.Time) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	groups := Groups{}
+	for _, group := range []Group{Public, Signup, Device, Authenticated, Admin} {
+		groups[group] = []gin.HandlerFunc{func(c *gin.Context) {
+			c.String(http.StatusOK, string(group))
+			c.Abort()
+		}}
+	}
+
+	r := gin.New()
+	require.NotPanics(t, func() { New(Handlers{}, sunset).Register(r, groups) })
+	return r
+}
+
+func serve(r *gin.Engine, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+func TestRegister(t *testing.T) {
+	r := newTestRouter(t, time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC))
+
+	cases := []struct {
+		method, path, group string
+	}{
+		{http.MethodPost, "/v1/user/createUser", "signup"},
+		{http.MethodPost, "/v2/users", "signup"},
+		{http.MethodPost, "/v1/device/sensor-data", "device"},
+		{http.MethodGet, "/v2/devices/AA:00:00:00:00:01/sensor-data", "device"},
+		{http.MethodGet, "/v1/user/has-entity", "authenticated"},
+		{http.MethodGet, "/v2/entities/mine", "authenticated"},
+		{http.MethodPut, "/v2/users/me", "authenticated"},
+		{http.MethodPost, "/v2/entities/42/children", "authenticated"},
+		{http.MethodGet, "/v2/entities/42/children", "public"},
+		{http.MethodGet, "/v2/categories", "public"},
+		{http.MethodGet, "/v2/admin/audit-entries", "admin"},
+	}
+	for _, tc := range cases {
+		w := serve(r, tc.method, tc.path)
+		assert.Equal(t, http.StatusOK, w.Code, tc.path)
+		assert.Equal(t, tc.group, w.Body.String(), tc.path)
+		assert.Empty(t, w.Header().Get("Deprecation"), tc.path)
+	}
+
+	// Routes only exist in the versions that define them
+	assert.Equal(t, http.StatusNotFound, serve(r, http.MethodPost, "/v2/user/createUser").Code)
+	assert.Equal(t, http.StatusNotFound, serve(r, http.MethodGet, "/v2/category/all").Code)
+}
+
+func TestRegisterKeepsLegacyRoutes(t *testing.T) {
+	r := newTestRouter(t, time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC))
+
+	for _, route := range New(Handlers{}, time.Time{}).Versions()[0].Routes {
+		w := serve(r, route.Method, route.Path)
+		assert.Equal(t, string(route.Group), w.Body.String(), route.Path)
+		assert.NotEmpty(t, w.Header().Get("Deprecation"), route.Path)
+		assert.Equal(t, "Fri, 30 Apr 2027 00:00:00 GMT", w.Header().Get("Sunset"), route.Path)
+	}
+
+	w := serve(r, http.MethodGet, "/entity/42/hierarchy")
+	assert.Equal(t, `</v1/entity/42/hierarchy>; rel="successor-version"`, w.Header().Get("Link"))
+
+	// Only v1 routes have unversioned copies
+	assert.Equal(t, http.StatusNotFound, serve(r, http.MethodGet, "/entities/mine").Code)
+}
+
+func TestRegisterRequiresEveryGroup(t *testing.T) {
+	r := gin.New()
+	assert.Panics(t, func() {
+		New(Handlers{}, time.Time{}).Register(r, Groups{Public: nil})
+	})
+}
+
+// TestRoutesAreDocumented fails when a registered route has no operation in
+// the generated OpenAPI document: add the @Router annotation to its handler
+// and run go generate
+func TestRoutesAreDocumented(t *testing.T) {
+	r := newTestRouter(t, time.Time{})
+	doc, err := openapi.Load([]byte(docs.SwaggerInfo.ReadDoc()))
+	require.NoError(t, err)
+
+	for _, route := range r.Routes() {
+		// The unversioned routes are deprecated copies of /v1
+		if !strings.HasPrefix(route.Path, "/v1/") && !strings.HasPrefix(route.Path, "/v2/") {
+			continue
+		}
+		assert.NotNil(t, doc.Operation(route.Method, route.Path), "%s %s is not in the OpenAPI document", route.Method, route.Path)
+	}
+}
@@ -0,0 +1,42 @@
+package routes
+
+// v1 lists the routes as the API was first published
+func v1(h Handlers) []Route {
+	return []Route{
+		// Device endpoints
+		post("/device/add", Authenticated, h.AddDevice.HandleGin),
+		get("/user/devices", Authenticated, h.ListUserDevices.HandleGin),
+		post("/device/attach-policy", Device, h.AttachIotPolicy.HandleGin),
+		post("/device/sensor-data", Device, h.GetDeviceSensorData.HandleGin),
+
+		// User endpoints
+		post("/user/createUser", Signup, h.User.CreateUserDetails),
+		get("/user/check-parent-id", Authenticated, h.User.HandleCheckHasParentID),
+		post("/user/details", Authenticated, h.User.HandleUpdateUserDetails),
+		get("/user/details", Authenticated, h.User.HandleGetUserDetails),
+		get("/user/has-entity", Authenticated, h.Entity.HandleCheckEntityPresence),
+		get("/user/referrals", Authenticated, h.User.HandleListReferredUsers),
+
+		// Category endpoints
+		post("/category/add", Public, h.AddCategory.HandleGin),
+		get("/category/type/:type", Public, h.GetCategoriesByType.HandleGin),
+		get("/category/all", Public, h.ListAllCategories.HandleGin),
+
+		// Entity endpoints
+		post("/entity/root", Authenticated, h.Entity.HandleCreateRootEntity),
+		post("/entity/sub", Authenticated, h.Entity.HandleCreateSubEntity),
+		get("/entity/search", Authenticated, h.Entity.HandleSearchEntities),
+		post("/entity/import", Authenticated, h.EntityTransfer.HandleImportEntities),
+		get("/entity/:entity_id/export", Authenticated, h.EntityTransfer.HandleExportEntities),
+		get("/entity/:entity_id/metrics", Authenticated, h.EntityMetrics.HandleGetEntityMetrics),
+		get("/entity/:entity_id/history", Authenticated, h.EntityHistory.HandleGetEntityHistory),
+		get("/entity/:entity_id/diff", Authenticated, h.EntityHistory.HandleDiffEntityTree),
+		get("/entity/:entity_id/children", Public, h.Entity.HandleGetEntityChildren),
+		get("/entity/:entity_id/hierarchy", Public, h.Entity.HandleGetEntityHierarchy),
+		get("/entity/:entity_id/ancestors", Authenticated, h.Entity.HandleGetEntityAncestors),
+
+		// Admin endpoints
+		get("/admin/audit", Admin, h.Audit.HandleListAuditEntries),
+		get("/admin/audit/export", Admin, h.Audit.HandleExportAuditEntries),
+	}
+}
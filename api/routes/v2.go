@@ -0,0 +1,41 @@
+package routes
+
+// v2 lists the routes of the resource oriented API: plural nouns, reads are
+// GETs, and the authenticated user is "me"
+func v2(h Handlers) []Route {
+	return []Route{
+		// Device endpoints
+		post("/devices", Authenticated, h.AddDevice.HandleGin),
+		get("/devices", Authenticated, h.ListUserDevices.HandleGin),
+		get("/devices/:device_id/sensor-data", Device, h.GetDeviceSensorData.HandleGetSensorData),
+		post("/iot/policy-attachments", Device, h.AttachIotPolicy.HandleGin),
+
+		// User endpoints; the parent ID is part of the user
+		post("/users", Signup, h.User.CreateUserDetails),
+		get("/users/me", Authenticated, h.User.HandleGetUserDetails),
+		put("/users/me", Authenticated, h.User.HandleUpdateUserDetails),
+		get("/users/me/referrals", Authenticated, h.User.HandleListReferredUsers),
+
+		// Category endpoints, filtered by type with ?type=
+		post("/categories", Public, h.AddCategory.HandleGin),
+		get("/categories", Public, h.ListAllCategories.HandleGin),
+
+		// Entity endpoints
+		post("/entities", Authenticated, h.Entity.HandleCreateRootEntity),
+		get("/entities/mine", Authenticated, h.Entity.HandleCheckEntityPresence),
+		get("/entities/search", Authenticated, h.Entity.HandleSearchEntities),
+		post("/entities/import", Authenticated, h.EntityTransfer.HandleImportEntities),
+		post("/entities/:entity_id/children", Authenticated, h.Entity.HandleCreateChildEntity),
+		get("/entities/:entity_id/children", Public, h.Entity.HandleGetEntityChildren),
+		get("/entities/:entity_id/hierarchy", Public, h.Entity.HandleGetEntityHierarchy),
+		get("/entities/:entity_id/ancestors", Authenticated, h.Entity.HandleGetEntityAncestors),
+		get("/entities/:entity_id/export", Authenticated, h.EntityTransfer.HandleExportEntities),
+		get("/entities/:entity_id/metrics", Authenticated, h.EntityMetrics.HandleGetEntityMetrics),
+		get("/entities/:entity_id/history", Authenticated, h.EntityHistory.HandleGetEntityHistory),
+		get("/entities/:entity_id/diff", Authenticated, h.EntityHistory.HandleDiffEntityTree),
+
+		// Admin endpoints
+		get("/admin/audit-entries", Admin, h.Audit.HandleListAuditEntries),
+		get("/admin/audit-entries/export", Admin, h.Audit.HandleExportAuditEntries),
+	}
+}
@@ -0,0 +1,40 @@
+// Command openapi generates the OpenAPI document of the API in docs/ from the
+// swag annotations of main.go and the handlers. Run it from the repository
+// root, or with `go generate` or `make docs`.
+package main
+
+import (
+	"io"
+	"log"
+
+	"github.com/swaggo/swag"
+	"github.com/swaggo/swag/gen"
+)
+
+// outputDir is where the document is written, relative to the repository root
+const outputDir = "./docs"
+
+func main() {
+	if err := generate(outputDir); err != nil {
+		log.Fatalf("Failed to generate the OpenAPI document: %v", err)
+	}
+}
+
+// generate writes docs.go, swagger.json and swagger.yaml to dir
+func generate(dir string) error {
+	return gen.New().Build(&gen.Config{
+		SearchDir:          "./",
+		Excludes:           "./cmd",
+		MainAPIFile:        "main.go",
+		PropNamingStrategy: swag.CamelCase,
+		OutputDir:          dir,
+		PackageName:        "docs",
+		OutputTypes:        []string{"go", "json", "yaml"},
+		ParseInternal:      true,
+		ParseDepth:         100,
+		CollectionFormat:   "csv",
+		LeftTemplateDelim:  "{{",
+		RightTemplateDelim: "}}",
+		Debugger:           log.New(io.Discard, "", 0),
+	})
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDocumentIsUpToDate fails when the committed document in docs/ differs
+// from the one generated from the annotations: run go generate
+func TestDocumentIsUpToDate(t *testing.T) {
+	t.Chdir("../..")
+
+	dir := t.TempDir()
+	require.NoError(t, generate(dir))
+
+	for _, name := range []string{"docs.go", "swagger.json", "swagger.yaml"} {
+		generated, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		committed, err := os.ReadFile(filepath.Join(outputDir, name))
+		require.NoError(t, err)
+		assert.Equal(t, string(generated), string(committed), "docs/%s is out of date, run go generate", name)
+	}
+}
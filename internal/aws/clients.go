@@ -14,8 +14,9 @@ type Clients struct {
 	Iot      *iot.Client
 }
 
-func InitAWSClients(ctx context.Context) (*Clients, error) {
-	awsCfg, err := awsconfig.LoadDefaultConfig(ctx)
+// InitAWSClients creates the AWS clients from the default config, adjusted by optFns
+func InitAWSClients(ctx context.Context, optFns ...func(*awsconfig.LoadOptions) error) (*Clients, error) {
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, optFns...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
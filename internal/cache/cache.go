@@ -0,0 +1,156 @@
+// Package cache provides a read-through cache for hot lookups, backed by an
+// in-process LRU or by Redis.
+//
+// Values are stored as JSON. Cache failures never fail a request: they are
+// logged, counted and treated as a miss.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Store is a byte-oriented cache backend
+type Store interface {
+	// Get returns the value stored under key and whether it was found
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key; a zero ttl keeps it until it is evicted
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes the given keys
+	Delete(ctx context.Context, keys ...string) error
+	// DeletePrefix removes every key starting with prefix
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// Stats counts cache lookups since startup
+type Stats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+	Errors uint64 `json:"errors"`
+}
+
+// Cache stores JSON encoded values in a Store and keeps hit/miss statistics
+type Cache struct {
+	store  Store
+	hits   atomic.Uint64
+	misses atomic.Uint64
+	errors atomic.Uint64
+}
+
+// New creates a cache on top of the given store
+func New(store Store) *Cache {
+	return &Cache{store: store}
+}
+
+// Get decodes the value stored under key into dst and reports whether it was found
+func (c *Cache) Get(ctx context.Context, key string, dst any) bool {
+	data, ok, err := c.store.Get(ctx, key)
+	if err != nil {
+		c.errors.Add(1)
+		slog.WarnContext(ctx, "Cache get failed", "key", keyPrefix(key), "error", err)
+		ok = false
+	}
+	if ok {
+		if err := json.Unmarshal(data, dst); err != nil {
+			c.errors.Add(1)
+			slog.WarnContext(ctx, "Cache entry could not be decoded", "key", keyPrefix(key), "error", err)
+			ok = false
+		}
+	}
+
+	if !ok {
+		c.misses.Add(1)
+		return false
+	}
+	c.hits.Add(1)
+	return true
+}
+
+// Set stores value under key for ttl
+func (c *Cache) Set(ctx context.Context, key string, value any, ttl time.Duration) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		c.errors.Add(1)
+		slog.WarnContext(ctx, "Cache entry could not be encoded", "key", keyPrefix(key), "error", err)
+		return
+	}
+
+	if err := c.store.Set(ctx, key, data, ttl); err != nil {
+		c.errors.Add(1)
+		slog.WarnContext(ctx, "Cache set failed", "key", keyPrefix(key), "error", err)
+	}
+}
+
+// Invalidate removes the given keys
+func (c *Cache) Invalidate(ctx context.Context, keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+
+	if err := c.store.Delete(ctx, keys...); err != nil {
+		c.errors.Add(1)
+		slog.WarnContext(ctx, "Cache invalidation failed", "keys", len(keys), "key", keyPrefix(keys[0]), "error", err)
+	}
+}
+
+// InvalidatePrefix removes every key starting with prefix
+func (c *Cache) InvalidatePrefix(ctx context.Context, prefix string) {
+	if err := c.store.DeletePrefix(ctx, prefix); err != nil {
+		c.errors.Add(1)
+		slog.WarnContext(ctx, "Cache invalidation failed", "prefix", prefix, "error", err)
+	}
+}
+
+// keyPrefix strips the last segment of a key, which may be a personal identifier
+// such as a Cognito ID, so the key can be logged
+func keyPrefix(key string) string {
+	if i := strings.LastIndex(key, ":"); i >= 0 {
+		return key[:i+1] + "..."
+	}
+	return key
+}
+
+// Stats returns the lookup counters
+func (c *Cache) Stats() Stats {
+	return Stats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+		Errors: c.errors.Load(),
+	}
+}
+
+// Disabled reports whether the cache stores nothing, so every lookup misses
+func (c *Cache) Disabled() bool {
+	_, noop := c.store.(Noop)
+	return noop
+}
+
+// Close releases the connections of the underlying store, if it holds any
+func (c *Cache) Close() error {
+	if closer, ok := c.store.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// GetOrLoad returns the value cached under key, or calls load and caches its result for ttl.
+// Errors from load are returned as is and nothing is cached.
+func GetOrLoad[T any](ctx context.Context, c *Cache, key string, ttl time.Duration, load func(ctx context.Context) (T, error)) (T, error) {
+	var value T
+	if c.Get(ctx, key, &value) {
+		return value, nil
+	}
+
+	value, err := load(ctx)
+	if err != nil {
+		return value, err
+	}
+
+	c.Set(ctx, key, value, ttl)
+	return value, nil
+}
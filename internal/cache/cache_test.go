@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testValue struct {
+	Name  string
+	Count int
+}
+
+func TestGetOrLoad(t *testing.T) {
+	ctx := context.Background()
+	c := New(NewLRU(10))
+
+	loads := 0
+	load := func(context.Context) (*testValue, error) {
+		loads++
+		return &testValue{Name: "a", Count: loads}, nil
+	}
+
+	first, err := GetOrLoad(ctx, c, "k", time.Minute, load)
+	require.NoError(t, err)
+	second, err := GetOrLoad(ctx, c, "k", time.Minute, load)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, loads)
+	assert.Equal(t, first, second)
+	assert.Equal(t, Stats{Hits: 1, Misses: 1}, c.Stats())
+
+	c.Invalidate(ctx, "k")
+	third, err := GetOrLoad(ctx, c, "k", time.Minute, load)
+	require.NoError(t, err)
+	assert.Equal(t, 2, third.Count)
+}
+
+func TestGetOrLoadDoesNotCacheErrors(t *testing.T) {
+	ctx := context.Background()
+	c := New(NewLRU(10))
+	errLoad := errors.New("database down")
+
+	_, err := GetOrLoad(ctx, c, "k", time.Minute, func(context.Context) (string, error) {
+		return "", errLoad
+	})
+	assert.ErrorIs(t, err, errLoad)
+
+	value, err := GetOrLoad(ctx, c, "k", time.Minute, func(context.Context) (string, error) {
+		return "ok", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", value)
+}
+
+func TestCacheTreatsUndecodableEntriesAsMisses(t *testing.T) {
+	ctx := context.Background()
+	store := NewLRU(10)
+	require.NoError(t, store.Set(ctx, "k", []byte("not json"), 0))
+
+	c := New(store)
+	var value testValue
+	assert.False(t, c.Get(ctx, "k", &value))
+	assert.Equal(t, Stats{Misses: 1, Errors: 1}, c.Stats())
+}
+
+func TestNoopNeverHits(t *testing.T) {
+	ctx := context.Background()
+	c := New(NewNoop())
+
+	c.Set(ctx, "k", "v", time.Minute)
+	var value string
+	assert.False(t, c.Get(ctx, "k", &value))
+}
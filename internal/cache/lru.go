@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LRU is an in-process Store that evicts the least recently used entry once
+// it holds more than its capacity
+type LRU struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List // front is the most recently used
+	now      func() time.Time
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time // zero means no expiry
+}
+
+// NewLRU creates an in-process store holding at most capacity entries
+func NewLRU(capacity int) *LRU {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	return &LRU{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
+
+// Get returns the value stored under key unless it has expired
+func (l *LRU) Get(_ context.Context, key string) ([]byte, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	element, ok := l.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry := element.Value.(*lruEntry)
+	if !entry.expiresAt.IsZero() && !l.now().Before(entry.expiresAt) {
+		l.remove(element)
+		return nil, false, nil
+	}
+
+	l.order.MoveToFront(element)
+	return entry.value, true, nil
+}
+
+// Set stores value under key, evicting the least recently used entry if the store is full
+func (l *LRU) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = l.now().Add(ttl)
+	}
+
+	if element, ok := l.items[key]; ok {
+		entry := element.Value.(*lruEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		l.order.MoveToFront(element)
+		return nil
+	}
+
+	l.items[key] = l.order.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+	for l.order.Len() > l.capacity {
+		l.remove(l.order.Back())
+	}
+
+	return nil
+}
+
+// Delete removes the given keys
+func (l *LRU) Delete(_ context.Context, keys ...string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, key := range keys {
+		if element, ok := l.items[key]; ok {
+			l.remove(element)
+		}
+	}
+
+	return nil
+}
+
+// DeletePrefix removes every key starting with prefix
+func (l *LRU) DeletePrefix(_ context.Context, prefix string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, element := range l.items {
+		if strings.HasPrefix(key, prefix) {
+			l.remove(element)
+		}
+	}
+
+	return nil
+}
+
+// Len returns the number of stored entries, including expired ones not yet evicted
+func (l *LRU) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.order.Len()
+}
+
+func (l *LRU) remove(element *list.Element) {
+	l.order.Remove(element)
+	delete(l.items, element.Value.(*lruEntry).key)
+}
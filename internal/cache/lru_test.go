@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	lru := NewLRU(2)
+
+	require.NoError(t, lru.Set(ctx, "a", []byte("1"), 0))
+	require.NoError(t, lru.Set(ctx, "b", []byte("2"), 0))
+
+	// Reading a makes b the least recently used entry
+	_, ok, _ := lru.Get(ctx, "a")
+	require.True(t, ok)
+	require.NoError(t, lru.Set(ctx, "c", []byte("3"), 0))
+
+	_, ok, _ = lru.Get(ctx, "b")
+	assert.False(t, ok)
+	value, ok, _ := lru.Get(ctx, "a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), value)
+	assert.Equal(t, 2, lru.Len())
+}
+
+func TestLRUExpiresEntries(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	lru := NewLRU(10)
+	lru.now = func() time.Time { return now }
+
+	require.NoError(t, lru.Set(ctx, "short", []byte("1"), time.Minute))
+	require.NoError(t, lru.Set(ctx, "forever", []byte("2"), 0))
+
+	now = now.Add(time.Minute)
+
+	_, ok, _ := lru.Get(ctx, "short")
+	assert.False(t, ok)
+	_, ok, _ = lru.Get(ctx, "forever")
+	assert.True(t, ok)
+	assert.Equal(t, 1, lru.Len(), "expired entries are dropped when read")
+}
+
+func TestLRUDelete(t *testing.T) {
+	ctx := context.Background()
+	lru := NewLRU(10)
+	for _, key := range []string{"entity:1:a", "entity:1:b", "entity:10:a", "user:1"} {
+		require.NoError(t, lru.Set(ctx, key, []byte("x"), 0))
+	}
+
+	require.NoError(t, lru.DeletePrefix(ctx, "entity:1:"))
+	require.NoError(t, lru.Delete(ctx, "user:1", "missing"))
+
+	for key, want := range map[string]bool{"entity:1:a": false, "entity:1:b": false, "entity:10:a": true, "user:1": false} {
+		_, ok, _ := lru.Get(ctx, key)
+		assert.Equal(t, want, ok, key)
+	}
+}
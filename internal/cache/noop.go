@@ -0,0 +1,26 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Noop is a Store that stores nothing, for running without a cache
+type Noop struct{}
+
+// NewNoop creates a store that never hits
+func NewNoop() Noop {
+	return Noop{}
+}
+
+// Get always misses
+func (Noop) Get(context.Context, string) ([]byte, bool, error) { return nil, false, nil }
+
+// Set discards the value
+func (Noop) Set(context.Context, string, []byte, time.Duration) error { return nil }
+
+// Delete does nothing
+func (Noop) Delete(context.Context, ...string) error { return nil }
+
+// DeletePrefix does nothing
+func (Noop) DeletePrefix(context.Context, string) error { return nil }
@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/config"
+)
+
+// Backends selectable with CACHE_BACKEND
+const (
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+	BackendNone   = "none"
+)
+
+// redisKeyPrefix namespaces the keys of this service in a shared Redis
+const redisKeyPrefix = "zolaris:"
+
+// Open creates the cache selected by the configuration. The redis backend uses
+// client, opened by redisclient.Open.
+func Open(ctx context.Context, cfg *config.Config, client *redis.Client) (*Cache, error) {
+	switch cfg.Cache.Backend {
+	case BackendMemory, "":
+		slog.InfoContext(ctx, "Using in-process cache", "size", cfg.Cache.Size)
+		return New(NewLRU(cfg.Cache.Size)), nil
+	case BackendRedis:
+		if client == nil {
+			return nil, errors.New("redis cache requires a redis client")
+		}
+		slog.InfoContext(ctx, "Using redis cache")
+		return New(NewRedis(client, redisKeyPrefix)), nil
+	case BackendNone:
+		slog.InfoContext(ctx, "Caching is disabled")
+		return New(NewNoop()), nil
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q", cfg.Cache.Backend)
+	}
+}
@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedis(t *testing.T) (*Redis, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	store := NewRedis(client, "test:")
+
+	return store, server
+}
+
+func TestRedisStore(t *testing.T) {
+	ctx := context.Background()
+	store, server := newTestRedis(t)
+
+	require.NoError(t, store.Set(ctx, "a", []byte("1"), time.Minute))
+	assert.True(t, server.Exists("test:a"), "keys are namespaced")
+
+	value, ok, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), value)
+
+	_, ok, err = store.Get(ctx, "missing")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	server.FastForward(time.Minute)
+	_, ok, err = store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, ok, "entries expire after their ttl")
+}
+
+func TestRedisStoreDelete(t *testing.T) {
+	ctx := context.Background()
+	store, server := newTestRedis(t)
+
+	for _, key := range []string{"entity:*:a", "entity:*:b", "entity:1:a", "user:1"} {
+		require.NoError(t, store.Set(ctx, key, []byte("x"), 0))
+	}
+	require.NoError(t, server.Set("other:entity:*:a", "x"))
+
+	// The prefix is matched literally, not as a pattern
+	require.NoError(t, store.DeletePrefix(ctx, "entity:*:"))
+	require.NoError(t, store.Delete(ctx, "user:1"))
+
+	assert.Equal(t, []string{"other:entity:*:a", "test:entity:1:a"}, server.Keys())
+}
+
+func TestRedisStoreUnavailable(t *testing.T) {
+	store, server := newTestRedis(t)
+	server.Close()
+
+	c := New(store)
+	var value string
+	assert.False(t, c.Get(context.Background(), "a", &value))
+	assert.Equal(t, Stats{Misses: 1, Errors: 1}, c.Stats())
+}
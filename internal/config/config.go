@@ -1,18 +1,33 @@
+// Package config loads the application configuration.
+//
+// Every setting is read from, in increasing order of precedence: its built-in
+// default, the YAML or TOML file named by CONFIG_FILE, the .env files and the
+// process environment. The result is validated for the selected environment.
 package config
 
 import (
 	"fmt"
 	"os"
-	"strconv"
-
-	"github.com/joho/godotenv"
+	"time"
 )
 
 // Config represents the application configuration
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	AWS      AWSConfig
+	Server      ServerConfig
+	Database    DatabaseConfig
+	AWS         AWSConfig
+	Cache       CacheConfig
+	Secrets     SecretsConfig
+	CORS        CORSConfig
+	Logging     LoggingConfig
+	Tracing     TracingConfig
+	Health      HealthConfig
+	Shutdown    ShutdownConfig
+	RateLimit   RateLimitConfig
+	Idempotency IdempotencyConfig
+
+	// sources records where each setting, keyed by environment variable, was read from
+	sources map[string]Source
 }
 
 // ServerConfig holds server-related configuration
@@ -20,6 +35,20 @@ type ServerConfig struct {
 	Port        int
 	Environment string
 	ExternalURL string
+	// TrustedProxies are the addresses or CIDRs of the proxies whose
+	// X-Forwarded-For header gives the client IP. None are trusted by default.
+	TrustedProxies []string
+	// AdminUserIDs are the users allowed to read the audit log
+	AdminUserIDs []string
+	// JWTPublicKeySecret names the secret holding the PEM encoded RSA public key
+	// that JWTs are verified with
+	JWTPublicKeySecret string
+	// LegacyRoutesSunset is announced in the Sunset header of the unversioned
+	// routes as the time they stop working. Zero announces no date.
+	LegacyRoutesSunset time.Time
+	// OpenAPIValidation checks requests and responses against the OpenAPI
+	// document: off, log mismatches, or enforce by also rejecting invalid requests
+	OpenAPIValidation string
 }
 
 // DatabaseConfig holds database-related configuration
@@ -30,8 +59,28 @@ type DatabaseConfig struct {
 	PostgresPort     string
 	PostgresUser     string
 	PostgresPassword string
-	PostgresDBName   string
-	PostgresSSLMode  string
+	// PostgresPasswordSecret, if set, names the secret holding the password instead of PostgresPassword
+	PostgresPasswordSecret string
+	PostgresDBName         string
+	PostgresSSLMode        string
+	// PostgresDSN, if set, replaces the individual connection settings above
+	PostgresDSN string
+	Pool        PoolConfig
+	// ReplicaDSN optionally points at a read replica for read-only queries
+	ReplicaDSN string
+	// ReplicaMaxLag is the replication lag above which reads fall back to the primary
+	ReplicaMaxLag time.Duration
+	// ReplicaLagCheckPeriod is how often the replica lag is measured
+	ReplicaLagCheckPeriod time.Duration
+}
+
+// PoolConfig holds the PostgreSQL connection pool settings, applied to the primary and the replica
+type PoolConfig struct {
+	MaxConns          int32
+	MinConns          int32
+	MaxConnLifetime   time.Duration
+	MaxConnIdleTime   time.Duration
+	HealthCheckPeriod time.Duration
 }
 
 // AWSConfig holds AWS-related configuration
@@ -40,16 +89,184 @@ type AWSConfig struct {
 	IoTPolicy string
 }
 
-// LoadEnv loads environment variables from .env files
-func LoadEnv() error {
-	// Try to load environment-specific .env file first
-	environment := os.Getenv("ENVIRONMENT")
-	if environment == "" {
-		environment = "development" // Default to development
+// CacheConfig holds configuration of the lookup cache
+type CacheConfig struct {
+	Backend  string // memory, redis or none
+	Size     int    // maximum number of entries of the in-process cache
+	RedisURL string
+}
+
+// SecretsConfig holds configuration of the secrets provider
+type SecretsConfig struct {
+	Provider      string // env, file or aws
+	Dir           string // directory of the file provider
+	RefreshPeriod time.Duration
+}
+
+// CORSConfig holds the cross-origin resource sharing policy
+type CORSConfig struct {
+	// AllowedOrigins are exact origins such as https://app.example.com, or
+	// patterns such as https://*.example.com matching any subdomain
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+	// AllowLocalhost accepts any http://localhost or http://127.0.0.1 origin.
+	// It is only enabled in development.
+	AllowLocalhost bool
+}
+
+// LoggingConfig holds configuration of the structured logger
+type LoggingConfig struct {
+	Level  string // debug, info, warn or error
+	Format string // json or text
+	// RedactFields are the attribute keys whose values are replaced in log records
+	RedactFields []string
+}
+
+// TracingConfig holds configuration of the OpenTelemetry trace exporter
+type TracingConfig struct {
+	Exporter string // none, stdout or otlp
+	// OTLPEndpoint is the host:port of the OTLP/HTTP collector. Empty uses
+	// OTEL_EXPORTER_OTLP_ENDPOINT, or localhost:4318.
+	OTLPEndpoint string
+	OTLPInsecure bool
+	// SampleRatio is the fraction of new traces recorded; a sampled parent is always followed
+	SampleRatio float64
+	ServiceName string
+}
+
+// HealthConfig holds configuration of the readiness checks
+type HealthConfig struct {
+	// CheckTimeout bounds each dependency check
+	CheckTimeout time.Duration
+	// CacheTTL is how long a readiness report is reused before the checks run again
+	CacheTTL time.Duration
+}
+
+// ShutdownConfig bounds the phases of a graceful shutdown. Their sum should stay
+// below the grace period of the orchestrator, 30s by default on ECS and Kubernetes.
+type ShutdownConfig struct {
+	// DrainDelay is how long the instance keeps serving, with readiness failing,
+	// before it stops accepting connections
+	DrainDelay time.Duration
+	// HTTPTimeout bounds waiting for in-flight requests
+	HTTPTimeout time.Duration
+	// WorkerTimeout bounds waiting for the background workers to stop
+	WorkerTimeout time.Duration
+	// CloseTimeout bounds closing the database pool and other resources
+	CloseTimeout time.Duration
+}
+
+// RateLimitConfig holds the request limits of each route group, such as 60/m
+// for 60 requests at once and one more every second after that, or off
+type RateLimitConfig struct {
+	Store string // memory, redis or none
+	// Signup limits account creation per client IP
+	Signup string
+	// Device limits the device endpoints used by the IoT clients
+	Device string
+	// Public limits the other unauthenticated endpoints
+	Public string
+	// Authenticated limits the endpoints requiring a user, per user
+	Authenticated string
+	// AuthenticatedIP limits the same endpoints per client IP before the user
+	// is looked up, so requests with unknown users cannot flood the database
+	AuthenticatedIP string
+	// APIKeys are the X-API-Key values of trusted integrations, which are
+	// limited per key instead of sharing the limit of their IP
+	APIKeys []string
+}
+
+// IdempotencyConfig holds configuration of the Idempotency-Key support
+type IdempotencyConfig struct {
+	Store string // memory, redis or none
+	// TTL is how long a response is replayed for retries of its request
+	TTL time.Duration
+	// LockTTL bounds how long a request holds its key, in case its instance dies
+	LockTTL time.Duration
+	// WaitTimeout is how long a retry waits for the request holding its key
+	WaitTimeout time.Duration
+}
+
+// Source tells where the value of a setting came from
+type Source string
+
+// Sources in increasing order of precedence
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceDotEnv  Source = "dotenv"
+	SourceEnv     Source = "env"
+)
+
+// LoadOptions selects the files Load reads
+type LoadOptions struct {
+	// EnvFiles are the .env files to load; a variable set in an earlier file wins.
+	// nil loads .env.<ENVIRONMENT> and .env, an empty slice loads none.
+	EnvFiles []string
+	// ConfigFile is a YAML or TOML file, chosen by extension. Empty uses CONFIG_FILE, if set.
+	ConfigFile string
+}
+
+// Load reads the configuration from every source and validates it
+func Load(opts LoadOptions) (*Config, error) {
+	envFiles := opts.EnvFiles
+	if envFiles == nil {
+		envFiles = defaultEnvFiles()
+	}
+	// .env files are exported to the environment, so libraries such as the AWS SDK see them too
+	if err := loadEnvFiles(envFiles); err != nil {
+		// Not fatal, as the .env files might not exist in all environments
+		fmt.Printf("Warning: Error loading environment files: %v\n", err)
 	}
 
-	// Try environment specific file (.env.development, .env.production, etc)
-	err := godotenv.Load(".env."+environment, ".env")
+	configFile := opts.ConfigFile
+	if configFile == "" {
+		configFile = os.Getenv("CONFIG_FILE")
+	}
+	fileValues := map[string]string{}
+	if configFile != "" {
+		var err error
+		if fileValues, err = readConfigFile(configFile); err != nil {
+			return nil, err
+		}
+	}
+
+	config := &Config{sources: make(map[string]Source, len(settings))}
+	for _, s := range settings {
+		value, source := s.def, SourceDefault
+		if v, ok := fileValues[s.file]; ok {
+			value, source = v, SourceFile
+		}
+		// A variable set to an empty value still counts, so it can clear a default or a file value
+		if v, ok := os.LookupEnv(s.env); ok {
+			value, source = v, SourceEnv
+			if fromDotEnv(s.env) {
+				source = SourceDotEnv
+			}
+		}
+
+		if err := s.set(config, value); err != nil {
+			return nil, fmt.Errorf("invalid %s value: %v", s.env, err)
+		}
+		config.sources[s.env] = source
+	}
+
+	config.CORS.AllowLocalhost = config.Server.Environment == EnvDevelopment
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// LoadEnv loads environment variables from .env files
+func LoadEnv() error {
+	err := loadEnvFiles(defaultEnvFiles())
 	if err != nil {
 		// We'll just log the error and continue, as the .env file might not exist in all environments
 		fmt.Printf("Warning: Error loading environment files: %v\n", err)
@@ -57,78 +274,32 @@ func LoadEnv() error {
 	return err
 }
 
-// LoadConfig loads configuration from environment variables
+// LoadConfig loads configuration from the default .env files, the CONFIG_FILE and the environment
 func LoadConfig() (*Config, error) {
-	// Try to load environment variables from .env files
-	// We ignore the error as it's not fatal if .env files don't exist
-	_ = LoadEnv()
-
-	config := &Config{}
-
-	// Server config
-	portStr := getEnv("PORT", "8080")
-	port, err := strconv.Atoi(portStr)
-	if err != nil {
-		return nil, fmt.Errorf("invalid PORT value: %v", err)
-	}
-	config.Server.Port = port
-	config.Server.Environment = getEnv("ENVIRONMENT", "development")
-	config.Server.ExternalURL = getEnv("EXTERNAL_URL", "http://localhost:8080")
-
-	// Database config
-	config.Database.DeviceTableName = getEnv("DEVICE_TABLE_NAME", "DEVICE_TABLE_NAME")
-	config.Database.DataTableName = getEnv("DATA_TABLE_NAME", "machine_data_table")
-	config.Database.PostgresHost = getEnv("POSTGRES_HOST", "localhost")
-	config.Database.PostgresPort = getEnv("POSTGRES_PORT", "5432")
-	config.Database.PostgresUser = getEnv("POSTGRES_USER", "postgres")
-	config.Database.PostgresPassword = getEnv("POSTGRES_PASSWORD", "postgres")
-	config.Database.PostgresDBName = getEnv("POSTGRES_DB_NAME", "postgres")
-	config.Database.PostgresSSLMode = getEnv("POSTGRES_SSL_MODE", "disable")
-
-	// AWS config
-	config.AWS.Region = getEnv("AWS_REGION", "us-east-1")
-	config.AWS.IoTPolicy = getEnv("IOT_POLICY_NAME", "IOT_POLICY_NAME")
-
-	return config, nil
+	return Load(LoadOptions{})
 }
 
 // LoadConfigWithPath loads configuration using explicit .env file paths
 func LoadConfigWithPath(envPaths ...string) (*Config, error) {
-	// Load specified env files
-	if len(envPaths) > 0 {
-		err := godotenv.Load(envPaths...)
-		if err != nil {
-			fmt.Printf("Warning: Error loading specified environment files: %v\n", err)
-		}
+	if envPaths == nil {
+		envPaths = []string{}
 	}
+	return Load(LoadOptions{EnvFiles: envPaths})
+}
 
-	config := &Config{}
-
-	// Server config
-	portStr := getEnv("PORT", "8080")
-	port, err := strconv.Atoi(portStr)
-	if err != nil {
-		return nil, fmt.Errorf("invalid PORT value: %v", err)
+// Source returns where the setting read from the environment variable key came from
+func (c *Config) Source(key string) Source {
+	if source, ok := c.sources[key]; ok {
+		return source
 	}
-	config.Server.Port = port
-	config.Server.Environment = getEnv("ENVIRONMENT", "development")
-	config.Server.ExternalURL = getEnv("EXTERNAL_URL", "http://localhost:8080")
-
-	// Database config
-	config.Database.DeviceTableName = getEnv("DEVICE_TABLE_NAME", "machine_table")
-	config.Database.DataTableName = getEnv("DATA_TABLE_NAME", "machine_data_table")
-	config.Database.PostgresHost = getEnv("POSTGRES_HOST", "localhost")
-	config.Database.PostgresPort = getEnv("POSTGRES_PORT", "5432")
-	config.Database.PostgresUser = getEnv("POSTGRES_USER", "postgres")
-	config.Database.PostgresPassword = getEnv("POSTGRES_PASSWORD", "postgres")
-	config.Database.PostgresDBName = getEnv("POSTGRES_DB_NAME", "postgres")
-	config.Database.PostgresSSLMode = getEnv("POSTGRES_SSL_MODE", "disable")
-
-	// AWS config
-	config.AWS.Region = getEnv("AWS_REGION", "us-east-1")
-	config.AWS.IoTPolicy = getEnv("IOT_POLICY_NAME", "iot_p")
+	return SourceDefault
+}
 
-	return config, nil
+// defaultEnvFiles returns the environment specific .env file (.env.development,
+// .env.production, etc) followed by .env
+func defaultEnvFiles() []string {
+	environment := getEnv("ENVIRONMENT", EnvDevelopment)
+	return []string{".env." + environment, ".env"}
 }
 
 // getEnv retrieves an environment variable or returns a default value
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+
+	"github.com/joho/godotenv"
+)
+
+// dotEnv remembers the variables exported from .env files, so that loading the
+// configuration again picks up edited files while the process environment keeps
+// precedence over them
+var dotEnv = struct {
+	mu       sync.Mutex
+	exported map[string]string
+}{exported: map[string]string{}}
+
+// loadEnvFiles exports the variables of the given .env files that the process
+// environment does not set. A variable set in an earlier file wins. Missing
+// files are skipped and reported in the returned error.
+func loadEnvFiles(files []string) error {
+	values := map[string]string{}
+	var errs []error
+	for _, file := range files {
+		fileValues, err := godotenv.Read(file)
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("failed to read %s: %w", file, err)
+			}
+			errs = append(errs, err)
+			continue
+		}
+		for key, value := range fileValues {
+			if _, ok := values[key]; !ok {
+				values[key] = value
+			}
+		}
+	}
+
+	dotEnv.mu.Lock()
+	defer dotEnv.mu.Unlock()
+
+	// Withdraw variables removed from the files since the last load
+	for key, exported := range dotEnv.exported {
+		if _, ok := values[key]; ok {
+			continue
+		}
+		if os.Getenv(key) == exported {
+			os.Unsetenv(key)
+		}
+		delete(dotEnv.exported, key)
+	}
+
+	for key, value := range values {
+		current, set := os.LookupEnv(key)
+		if set && current != dotEnv.exported[key] {
+			// Set by the process environment
+			delete(dotEnv.exported, key)
+			continue
+		}
+		os.Setenv(key, value)
+		dotEnv.exported[key] = value
+	}
+
+	return errors.Join(errs...)
+}
+
+// fromDotEnv reports whether the current value of key was exported from a .env file
+func fromDotEnv(key string) bool {
+	dotEnv.mu.Lock()
+	defer dotEnv.mu.Unlock()
+
+	exported, ok := dotEnv.exported[key]
+	return ok && os.Getenv(key) == exported
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// readConfigFile reads a YAML or TOML config file into a map keyed by the dotted
+// setting keys, e.g. postgres.max_conns. Unknown keys are rejected, so a typo
+// does not silently leave a setting at its default.
+func readConfigFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var raw map[string]any
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return nil, fmt.Errorf("unsupported config file type %q, use .yaml, .yml or .toml", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	if err := flatten("", raw, values); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	var unknown []string
+	for key := range values {
+		if _, ok := fileKeys[key]; !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("invalid config file %s: unknown keys %s", path, strings.Join(unknown, ", "))
+	}
+
+	return values, nil
+}
+
+// flatten turns nested tables into dotted keys with scalar values formatted as strings
+func flatten(prefix string, raw map[string]any, values map[string]string) error {
+	for key, value := range raw {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		switch v := value.(type) {
+		case map[string]any:
+			if err := flatten(key, v, values); err != nil {
+				return err
+			}
+		case []any:
+			// Lists of scalars are read like a comma separated environment variable
+			items := make([]string, len(v))
+			for i, item := range v {
+				switch item.(type) {
+				case map[string]any, []any:
+					return fmt.Errorf("%s: only lists of plain values are supported", key)
+				}
+				items[i] = fmt.Sprint(item)
+			}
+			values[key] = strings.Join(items, ",")
+		case nil:
+			// An empty key leaves the setting to the other sources
+		default:
+			values[key] = fmt.Sprint(v)
+		}
+	}
+	return nil
+}
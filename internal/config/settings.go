@@ -0,0 +1,300 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// setting describes one configuration value and the keys it is read from
+type setting struct {
+	env  string // environment variable, also used in .env files
+	file string // dotted key in the config file
+	def  string // built-in default
+
+	// secret values are redacted when the configuration is printed
+	secret bool
+	// required values must be set explicitly in staging and production
+	required bool
+
+	set func(c *Config, value string) error
+	get func(c *Config) string
+}
+
+// settings lists every configuration value in the order it is printed
+var settings = []setting{
+	intVar("PORT", "server.port", "8080", func(c *Config) *int { return &c.Server.Port }),
+	stringVar("ENVIRONMENT", "server.environment", EnvDevelopment, func(c *Config) *string { return &c.Server.Environment }),
+	required(stringVar("EXTERNAL_URL", "server.external_url", "http://localhost:8080", func(c *Config) *string { return &c.Server.ExternalURL })),
+	listVar("TRUSTED_PROXIES", "server.trusted_proxies", "", func(c *Config) *[]string { return &c.Server.TrustedProxies }),
+	listVar("ADMIN_USER_IDS", "server.admin_user_ids", "", func(c *Config) *[]string { return &c.Server.AdminUserIDs }),
+	stringVar("JWT_PUBLIC_KEY_SECRET", "server.jwt_public_key_secret", "", func(c *Config) *string { return &c.Server.JWTPublicKeySecret }),
+	timeVar("LEGACY_ROUTES_SUNSET", "server.legacy_routes_sunset", "2027-04-30", func(c *Config) *time.Time { return &c.Server.LegacyRoutesSunset }),
+	stringVar("OPENAPI_VALIDATION", "server.openapi_validation", "off", func(c *Config) *string { return &c.Server.OpenAPIValidation }),
+
+	required(stringVar("DEVICE_TABLE_NAME", "database.device_table_name", "machine_table", func(c *Config) *string { return &c.Database.DeviceTableName })),
+	required(stringVar("DATA_TABLE_NAME", "database.data_table_name", "machine_data_table", func(c *Config) *string { return &c.Database.DataTableName })),
+
+	stringVar("POSTGRES_HOST", "postgres.host", "localhost", func(c *Config) *string { return &c.Database.PostgresHost }),
+	stringVar("POSTGRES_PORT", "postgres.port", "5432", func(c *Config) *string { return &c.Database.PostgresPort }),
+	stringVar("POSTGRES_USER", "postgres.user", "postgres", func(c *Config) *string { return &c.Database.PostgresUser }),
+	secret(stringVar("POSTGRES_PASSWORD", "postgres.password", "postgres", func(c *Config) *string { return &c.Database.PostgresPassword })),
+	stringVar("POSTGRES_PASSWORD_SECRET", "postgres.password_secret", "", func(c *Config) *string { return &c.Database.PostgresPasswordSecret }),
+	stringVar("POSTGRES_DB_NAME", "postgres.db_name", "postgres", func(c *Config) *string { return &c.Database.PostgresDBName }),
+	stringVar("POSTGRES_SSL_MODE", "postgres.ssl_mode", "disable", func(c *Config) *string { return &c.Database.PostgresSSLMode }),
+	secret(stringVar("POSTGRES_DSN", "postgres.dsn", "", func(c *Config) *string { return &c.Database.PostgresDSN })),
+	int32Var("POSTGRES_MAX_CONNS", "postgres.max_conns", "10", func(c *Config) *int32 { return &c.Database.Pool.MaxConns }),
+	int32Var("POSTGRES_MIN_CONNS", "postgres.min_conns", "2", func(c *Config) *int32 { return &c.Database.Pool.MinConns }),
+	durationVar("POSTGRES_MAX_CONN_LIFETIME", "postgres.max_conn_lifetime", "30m", func(c *Config) *time.Duration { return &c.Database.Pool.MaxConnLifetime }),
+	durationVar("POSTGRES_MAX_CONN_IDLE_TIME", "postgres.max_conn_idle_time", "5m", func(c *Config) *time.Duration { return &c.Database.Pool.MaxConnIdleTime }),
+	durationVar("POSTGRES_HEALTH_CHECK_PERIOD", "postgres.health_check_period", "1m", func(c *Config) *time.Duration { return &c.Database.Pool.HealthCheckPeriod }),
+	secret(stringVar("POSTGRES_REPLICA_DSN", "postgres.replica_dsn", "", func(c *Config) *string { return &c.Database.ReplicaDSN })),
+	durationVar("POSTGRES_REPLICA_MAX_LAG", "postgres.replica_max_lag", "5s", func(c *Config) *time.Duration { return &c.Database.ReplicaMaxLag }),
+	durationVar("POSTGRES_REPLICA_LAG_CHECK_PERIOD", "postgres.replica_lag_check_period", "5s", func(c *Config) *time.Duration { return &c.Database.ReplicaLagCheckPeriod }),
+
+	stringVar("AWS_REGION", "aws.region", "us-east-1", func(c *Config) *string { return &c.AWS.Region }),
+	required(stringVar("IOT_POLICY_NAME", "aws.iot_policy_name", "iot_p", func(c *Config) *string { return &c.AWS.IoTPolicy })),
+
+	stringVar("SECRETS_PROVIDER", "secrets.provider", "env", func(c *Config) *string { return &c.Secrets.Provider }),
+	stringVar("SECRETS_DIR", "secrets.dir", "/run/secrets", func(c *Config) *string { return &c.Secrets.Dir }),
+	durationVar("SECRETS_REFRESH_PERIOD", "secrets.refresh_period", "5m", func(c *Config) *time.Duration { return &c.Secrets.RefreshPeriod }),
+
+	listVar("CORS_ALLOWED_ORIGINS", "cors.allowed_origins", "", func(c *Config) *[]string { return &c.CORS.AllowedOrigins }),
+	listVar("CORS_ALLOWED_METHODS", "cors.allowed_methods", "GET,POST,PUT,PATCH,DELETE", func(c *Config) *[]string { return &c.CORS.AllowedMethods }),
+	listVar("CORS_ALLOWED_HEADERS", "cors.allowed_headers", "Origin,Content-Type,Accept,Authorization,X-Cognito-ID,Idempotency-Key", func(c *Config) *[]string { return &c.CORS.AllowedHeaders }),
+	listVar("CORS_EXPOSED_HEADERS", "cors.exposed_headers", "Content-Length,X-Request-ID,Retry-After,X-RateLimit-Limit,X-RateLimit-Remaining,X-RateLimit-Reset,Idempotent-Replayed,Deprecation,Sunset,Link", func(c *Config) *[]string { return &c.CORS.ExposedHeaders }),
+	boolVar("CORS_ALLOW_CREDENTIALS", "cors.allow_credentials", "true", func(c *Config) *bool { return &c.CORS.AllowCredentials }),
+	durationVar("CORS_MAX_AGE", "cors.max_age", "1h", func(c *Config) *time.Duration { return &c.CORS.MaxAge }),
+
+	stringVar("LOG_LEVEL", "logging.level", "info", func(c *Config) *string { return &c.Logging.Level }),
+	stringVar("LOG_FORMAT", "logging.format", "json", func(c *Config) *string { return &c.Logging.Format }),
+	listVar("LOG_REDACT_FIELDS", "logging.redact_fields", "cognito_id,identity_id,email,phone,password,token,authorization", func(c *Config) *[]string { return &c.Logging.RedactFields }),
+
+	stringVar("TRACING_EXPORTER", "tracing.exporter", "none", func(c *Config) *string { return &c.Tracing.Exporter }),
+	stringVar("TRACING_OTLP_ENDPOINT", "tracing.otlp_endpoint", "", func(c *Config) *string { return &c.Tracing.OTLPEndpoint }),
+	boolVar("TRACING_OTLP_INSECURE", "tracing.otlp_insecure", "false", func(c *Config) *bool { return &c.Tracing.OTLPInsecure }),
+	floatVar("TRACING_SAMPLE_RATIO", "tracing.sample_ratio", "1", func(c *Config) *float64 { return &c.Tracing.SampleRatio }),
+	stringVar("TRACING_SERVICE_NAME", "tracing.service_name", "zolaris-backend", func(c *Config) *string { return &c.Tracing.ServiceName }),
+
+	durationVar("HEALTH_CHECK_TIMEOUT", "health.check_timeout", "2s", func(c *Config) *time.Duration { return &c.Health.CheckTimeout }),
+	durationVar("HEALTH_CACHE_TTL", "health.cache_ttl", "5s", func(c *Config) *time.Duration { return &c.Health.CacheTTL }),
+
+	durationVar("SHUTDOWN_DRAIN_DELAY", "shutdown.drain_delay", "0s", func(c *Config) *time.Duration { return &c.Shutdown.DrainDelay }),
+	durationVar("SHUTDOWN_HTTP_TIMEOUT", "shutdown.http_timeout", "15s", func(c *Config) *time.Duration { return &c.Shutdown.HTTPTimeout }),
+	durationVar("SHUTDOWN_WORKER_TIMEOUT", "shutdown.worker_timeout", "5s", func(c *Config) *time.Duration { return &c.Shutdown.WorkerTimeout }),
+	durationVar("SHUTDOWN_CLOSE_TIMEOUT", "shutdown.close_timeout", "5s", func(c *Config) *time.Duration { return &c.Shutdown.CloseTimeout }),
+
+	stringVar("RATE_LIMIT_STORE", "rate_limit.store", "memory", func(c *Config) *string { return &c.RateLimit.Store }),
+	stringVar("RATE_LIMIT_SIGNUP", "rate_limit.signup", "30/h", func(c *Config) *string { return &c.RateLimit.Signup }),
+	stringVar("RATE_LIMIT_DEVICE", "rate_limit.device", "120/m", func(c *Config) *string { return &c.RateLimit.Device }),
+	stringVar("RATE_LIMIT_PUBLIC", "rate_limit.public", "120/m", func(c *Config) *string { return &c.RateLimit.Public }),
+	stringVar("RATE_LIMIT_AUTHENTICATED", "rate_limit.authenticated", "600/m", func(c *Config) *string { return &c.RateLimit.Authenticated }),
+	stringVar("RATE_LIMIT_AUTHENTICATED_IP", "rate_limit.authenticated_ip", "1200/m", func(c *Config) *string { return &c.RateLimit.AuthenticatedIP }),
+	secret(listVar("RATE_LIMIT_API_KEYS", "rate_limit.api_keys", "", func(c *Config) *[]string { return &c.RateLimit.APIKeys })),
+
+	stringVar("IDEMPOTENCY_STORE", "idempotency.store", "memory", func(c *Config) *string { return &c.Idempotency.Store }),
+	durationVar("IDEMPOTENCY_TTL", "idempotency.ttl", "24h", func(c *Config) *time.Duration { return &c.Idempotency.TTL }),
+	durationVar("IDEMPOTENCY_LOCK_TTL", "idempotency.lock_ttl", "1m", func(c *Config) *time.Duration { return &c.Idempotency.LockTTL }),
+	durationVar("IDEMPOTENCY_WAIT_TIMEOUT", "idempotency.wait_timeout", "10s", func(c *Config) *time.Duration { return &c.Idempotency.WaitTimeout }),
+
+	stringVar("CACHE_BACKEND", "cache.backend", "memory", func(c *Config) *string { return &c.Cache.Backend }),
+	intVar("CACHE_SIZE", "cache.size", "10000", func(c *Config) *int { return &c.Cache.Size }),
+	secret(stringVar("REDIS_URL", "cache.redis_url", "redis://localhost:6379/0", func(c *Config) *string { return &c.Cache.RedisURL })),
+}
+
+// fileKeys maps the config file keys to their settings
+var fileKeys = func() map[string]*setting {
+	keys := make(map[string]*setting, len(settings))
+	for i := range settings {
+		keys[settings[i].file] = &settings[i]
+	}
+	return keys
+}()
+
+func stringVar(env, file, def string, field func(c *Config) *string) setting {
+	return setting{
+		env:  env,
+		file: file,
+		def:  def,
+		set: func(c *Config, value string) error {
+			*field(c) = value
+			return nil
+		},
+		get: func(c *Config) string { return *field(c) },
+	}
+}
+
+func intVar(env, file, def string, field func(c *Config) *int) setting {
+	return setting{
+		env:  env,
+		file: file,
+		def:  def,
+		set: func(c *Config, value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return err
+			}
+			*field(c) = n
+			return nil
+		},
+		get: func(c *Config) string { return strconv.Itoa(*field(c)) },
+	}
+}
+
+func int32Var(env, file, def string, field func(c *Config) *int32) setting {
+	return setting{
+		env:  env,
+		file: file,
+		def:  def,
+		set: func(c *Config, value string) error {
+			n, err := strconv.ParseInt(value, 10, 32)
+			if err != nil {
+				return err
+			}
+			*field(c) = int32(n)
+			return nil
+		},
+		get: func(c *Config) string { return strconv.FormatInt(int64(*field(c)), 10) },
+	}
+}
+
+// durationVar reads a duration such as "30s" or "5m"
+func durationVar(env, file, def string, field func(c *Config) *time.Duration) setting {
+	return setting{
+		env:  env,
+		file: file,
+		def:  def,
+		set: func(c *Config, value string) error {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return err
+			}
+			*field(c) = d
+			return nil
+		},
+		get: func(c *Config) string { return field(c).String() },
+	}
+}
+
+// timeVar reads a date such as "2027-04-30", in UTC, or an RFC 3339 timestamp;
+// empty is the zero time
+func timeVar(env, file, def string, field func(c *Config) *time.Time) setting {
+	return setting{
+		env:  env,
+		file: file,
+		def:  def,
+		set: func(c *Config, value string) error {
+			if value == "" {
+				*field(c) = time.Time{}
+				return nil
+			}
+			t, err := time.Parse(time.DateOnly, value)
+			if err != nil {
+				if t, err = time.Parse(time.RFC3339, value); err != nil {
+					return fmt.Errorf("%q is neither a date nor an RFC 3339 timestamp", value)
+				}
+			}
+			*field(c) = t
+			return nil
+		},
+		get: func(c *Config) string {
+			if field(c).IsZero() {
+				return ""
+			}
+			return field(c).Format(time.RFC3339)
+		},
+	}
+}
+
+func floatVar(env, file, def string, field func(c *Config) *float64) setting {
+	return setting{
+		env:  env,
+		file: file,
+		def:  def,
+		set: func(c *Config, value string) error {
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return err
+			}
+			*field(c) = f
+			return nil
+		},
+		get: func(c *Config) string { return strconv.FormatFloat(*field(c), 'g', -1, 64) },
+	}
+}
+
+func boolVar(env, file, def string, field func(c *Config) *bool) setting {
+	return setting{
+		env:  env,
+		file: file,
+		def:  def,
+		set: func(c *Config, value string) error {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return err
+			}
+			*field(c) = b
+			return nil
+		},
+		get: func(c *Config) string { return strconv.FormatBool(*field(c)) },
+	}
+}
+
+// listVar reads a comma separated list; in the config file it may also be a list
+func listVar(env, file, def string, field func(c *Config) *[]string) setting {
+	return setting{
+		env:  env,
+		file: file,
+		def:  def,
+		set: func(c *Config, value string) error {
+			var items []string
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			*field(c) = items
+			return nil
+		},
+		get: func(c *Config) string { return strings.Join(*field(c), ",") },
+	}
+}
+
+func secret(s setting) setting {
+	s.secret = true
+	return s
+}
+
+func required(s setting) setting {
+	s.required = true
+	return s
+}
+
+// dsnPassword matches the password of a key=value connection string
+var dsnPassword = regexp.MustCompile(`(password\s*=\s*)('(?:[^'\\]|\\.)*'|\S+)`)
+
+// redact hides the credentials in a secret value. URLs keep everything but
+// their password, so the host a DSN points at can still be checked.
+func redact(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	if u, err := url.Parse(value); err == nil && u.Scheme != "" && u.Host != "" {
+		query := u.Query()
+		if query.Has("password") {
+			query.Set("password", "xxxxx")
+			u.RawQuery = query.Encode()
+		}
+		return u.Redacted()
+	}
+
+	if dsnPassword.MatchString(value) {
+		return dsnPassword.ReplaceAllString(value, "${1}xxxxx")
+	}
+
+	return "xxxxx"
+}
@@ -0,0 +1,269 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/google/uuid"
+)
+
+// Environments selectable with ENVIRONMENT
+const (
+	EnvDevelopment = "development"
+	EnvTesting     = "testing"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+)
+
+var (
+	environments      = []string{EnvDevelopment, EnvTesting, EnvStaging, EnvProduction}
+	cacheBackends     = []string{"memory", "redis", "none"}
+	secretsProviders  = []string{"env", "file", "aws"}
+	postgresSSLModes  = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+	logLevels         = []string{"debug", "info", "warn", "error"}
+	logFormats        = []string{"json", "text"}
+	traceExporters    = []string{"none", "stdout", "otlp"}
+	rateLimitStores   = []string{"memory", "redis", "none"}
+	idempotencyStores = []string{"memory", "redis", "none"}
+	openAPIModes      = []string{"off", "log", "enforce"}
+	// rateLimit matches a limit such as 60/m, as read by ratelimit.ParseLimit
+	rateLimit = regexp.MustCompile(`^(off|[1-9][0-9]*/[smh])$`)
+)
+
+// IsDeployed reports whether the configuration is for a shared environment,
+// where every required setting must be set explicitly
+func (c *Config) IsDeployed() bool {
+	return c.Server.Environment == EnvStaging || c.Server.Environment == EnvProduction
+}
+
+// UsesRedis reports whether the cache, the rate limit store or the idempotency store is kept in Redis
+func (c *Config) UsesRedis() bool {
+	return c.Cache.Backend == "redis" || c.RateLimit.Store == "redis" || c.Idempotency.Store == "redis"
+}
+
+// Validate checks the configuration and reports every problem at once
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if !slices.Contains(environments, c.Server.Environment) {
+		fail("ENVIRONMENT must be one of %v, got %q", environments, c.Server.Environment)
+	}
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		fail("PORT must be between 1 and 65535, got %d", c.Server.Port)
+	}
+	if u, err := url.Parse(c.Server.ExternalURL); err != nil || u.Scheme == "" || u.Host == "" {
+		fail("EXTERNAL_URL must be an absolute URL, got %q", c.Server.ExternalURL)
+	}
+
+	for _, s := range settings {
+		value := s.get(c)
+		// A value equal to its own name is a template placeholder that was never filled in
+		if value == s.env {
+			fail("%s is set to a placeholder", s.env)
+		}
+		if s.required && value == "" {
+			fail("%s is required", s.env)
+		}
+		if s.required && c.IsDeployed() && c.Source(s.env) == SourceDefault {
+			fail("%s must be set explicitly in %s", s.env, c.Server.Environment)
+		}
+	}
+
+	database := c.Database
+	if c.IsDeployed() && database.PostgresDSN == "" {
+		if c.Source("POSTGRES_HOST") == SourceDefault {
+			fail("POSTGRES_HOST or POSTGRES_DSN must be set explicitly in %s", c.Server.Environment)
+		}
+		if c.Source("POSTGRES_PASSWORD") == SourceDefault && database.PostgresPasswordSecret == "" {
+			fail("POSTGRES_PASSWORD, POSTGRES_PASSWORD_SECRET or POSTGRES_DSN must be set explicitly in %s", c.Server.Environment)
+		}
+	}
+	if !slices.Contains(postgresSSLModes, database.PostgresSSLMode) {
+		fail("POSTGRES_SSL_MODE must be one of %v, got %q", postgresSSLModes, database.PostgresSSLMode)
+	}
+	if database.Pool.MaxConns < 1 || database.Pool.MinConns < 0 || database.Pool.MinConns > database.Pool.MaxConns {
+		fail("invalid pool size: POSTGRES_MIN_CONNS=%d, POSTGRES_MAX_CONNS=%d", database.Pool.MinConns, database.Pool.MaxConns)
+	}
+	if database.ReplicaMaxLag < 0 {
+		fail("POSTGRES_REPLICA_MAX_LAG must not be negative")
+	}
+	if database.ReplicaLagCheckPeriod <= 0 {
+		fail("POSTGRES_REPLICA_LAG_CHECK_PERIOD must be positive")
+	}
+
+	if !slices.Contains(cacheBackends, c.Cache.Backend) {
+		fail("CACHE_BACKEND must be one of %v, got %q", cacheBackends, c.Cache.Backend)
+	}
+	if c.Cache.Backend == "memory" && c.Cache.Size < 1 {
+		fail("CACHE_SIZE must be positive, got %d", c.Cache.Size)
+	}
+
+	if !slices.Contains(secretsProviders, c.Secrets.Provider) {
+		fail("SECRETS_PROVIDER must be one of %v, got %q", secretsProviders, c.Secrets.Provider)
+	}
+	if c.Secrets.RefreshPeriod <= 0 {
+		fail("SECRETS_REFRESH_PERIOD must be positive")
+	}
+
+	for _, origin := range c.CORS.AllowedOrigins {
+		if err := validateOrigin(origin, c.CORS.AllowCredentials); err != nil {
+			fail("CORS_ALLOWED_ORIGINS: %v", err)
+		}
+	}
+	if len(c.CORS.AllowedMethods) == 0 {
+		fail("CORS_ALLOWED_METHODS must not be empty")
+	}
+	if c.CORS.MaxAge < 0 {
+		fail("CORS_MAX_AGE must not be negative")
+	}
+
+	if !slices.Contains(logLevels, strings.ToLower(c.Logging.Level)) {
+		fail("LOG_LEVEL must be one of %v, got %q", logLevels, c.Logging.Level)
+	}
+	if !slices.Contains(logFormats, c.Logging.Format) {
+		fail("LOG_FORMAT must be one of %v, got %q", logFormats, c.Logging.Format)
+	}
+
+	if !slices.Contains(traceExporters, c.Tracing.Exporter) {
+		fail("TRACING_EXPORTER must be one of %v, got %q", traceExporters, c.Tracing.Exporter)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		fail("TRACING_SAMPLE_RATIO must be between 0 and 1, got %v", c.Tracing.SampleRatio)
+	}
+
+	if c.Health.CheckTimeout <= 0 {
+		fail("HEALTH_CHECK_TIMEOUT must be positive")
+	}
+	if c.Health.CacheTTL < 0 {
+		fail("HEALTH_CACHE_TTL must not be negative")
+	}
+
+	if c.Shutdown.DrainDelay < 0 {
+		fail("SHUTDOWN_DRAIN_DELAY must not be negative")
+	}
+	if c.Shutdown.HTTPTimeout <= 0 {
+		fail("SHUTDOWN_HTTP_TIMEOUT must be positive")
+	}
+	if c.Shutdown.WorkerTimeout <= 0 {
+		fail("SHUTDOWN_WORKER_TIMEOUT must be positive")
+	}
+	if c.Shutdown.CloseTimeout <= 0 {
+		fail("SHUTDOWN_CLOSE_TIMEOUT must be positive")
+	}
+
+	if !slices.Contains(openAPIModes, c.Server.OpenAPIValidation) {
+		fail("OPENAPI_VALIDATION must be one of %v, got %q", openAPIModes, c.Server.OpenAPIValidation)
+	} else if c.Server.OpenAPIValidation != "off" && c.Server.Environment == EnvProduction {
+		fail("OPENAPI_VALIDATION must be off in production")
+	}
+
+	for _, id := range c.Server.AdminUserIDs {
+		if err := uuid.Validate(id); err != nil {
+			fail("ADMIN_USER_IDS: invalid user ID %q", id)
+		}
+	}
+
+	for _, proxy := range c.Server.TrustedProxies {
+		if err := validateProxy(proxy); err != nil {
+			fail("TRUSTED_PROXIES: %v", err)
+		}
+	}
+
+	if !slices.Contains(rateLimitStores, c.RateLimit.Store) {
+		fail("RATE_LIMIT_STORE must be one of %v, got %q", rateLimitStores, c.RateLimit.Store)
+	}
+	for _, limit := range []struct{ name, value string }{
+		{"RATE_LIMIT_SIGNUP", c.RateLimit.Signup},
+		{"RATE_LIMIT_DEVICE", c.RateLimit.Device},
+		{"RATE_LIMIT_PUBLIC", c.RateLimit.Public},
+		{"RATE_LIMIT_AUTHENTICATED", c.RateLimit.Authenticated},
+		{"RATE_LIMIT_AUTHENTICATED_IP", c.RateLimit.AuthenticatedIP},
+	} {
+		if !rateLimit.MatchString(limit.value) {
+			fail("%s must look like 60/m, with a unit of s, m or h, or be off, got %q", limit.name, limit.value)
+		}
+	}
+
+	if !slices.Contains(idempotencyStores, c.Idempotency.Store) {
+		fail("IDEMPOTENCY_STORE must be one of %v, got %q", idempotencyStores, c.Idempotency.Store)
+	}
+	if c.Idempotency.TTL <= 0 {
+		fail("IDEMPOTENCY_TTL must be positive")
+	}
+	if c.Idempotency.LockTTL <= 0 {
+		fail("IDEMPOTENCY_LOCK_TTL must be positive")
+	}
+	if c.Idempotency.WaitTimeout < 0 {
+		fail("IDEMPOTENCY_WAIT_TIMEOUT must not be negative")
+	}
+
+	if c.UsesRedis() && c.IsDeployed() && c.Source("REDIS_URL") == SourceDefault {
+		fail("REDIS_URL must be set explicitly in %s when CACHE_BACKEND, RATE_LIMIT_STORE or IDEMPOTENCY_STORE is redis", c.Server.Environment)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// validateProxy checks a trusted proxy: an IP address or a CIDR
+func validateProxy(proxy string) error {
+	if strings.Contains(proxy, "/") {
+		if _, _, err := net.ParseCIDR(proxy); err != nil {
+			return fmt.Errorf("invalid CIDR %q", proxy)
+		}
+		return nil
+	}
+	if net.ParseIP(proxy) == nil {
+		return fmt.Errorf("invalid IP address %q", proxy)
+	}
+	return nil
+}
+
+// validateOrigin checks an allowed origin: "*", an origin such as
+// https://app.example.com, or a subdomain pattern such as https://*.example.com
+func validateOrigin(origin string, allowCredentials bool) error {
+	if origin == "*" {
+		if allowCredentials {
+			return errors.New(`"*" cannot be combined with CORS_ALLOW_CREDENTIALS`)
+		}
+		return nil
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+		u.Path != "" || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return fmt.Errorf("%q is not an origin such as https://app.example.com", origin)
+	}
+
+	host := strings.TrimPrefix(u.Hostname(), "*.")
+	if strings.Contains(host, "*") || host == "" {
+		return fmt.Errorf("%q: a wildcard is only allowed as the first label, as in https://*.example.com", origin)
+	}
+	return nil
+}
+
+// Print writes the effective configuration with the source of every value.
+// Secrets are redacted.
+func (c *Config) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, s := range settings {
+		value := s.get(c)
+		if s.secret {
+			value = redact(value)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t(%s)\n", s.env, value, c.Source(s.env))
+	}
+	return tw.Flush()
+}
@@ -2,32 +2,52 @@ package db
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
 
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/afreedicp/zolaris-backend-app/internal/config"
+	"github.com/afreedicp/zolaris-backend-app/internal/secrets"
 )
 
 // Database holds the database clients and configuration
 type Database struct {
 	dynamoClient     *dynamodb.Client
 	postgresPool     *pgxpool.Pool
+	readRouter       *ReplicaRouter
 	deviceTable      string
 	machineDataTable string
 }
 
-// NewDatabase creates and initializes database clients
-func NewDatabase(ctx context.Context, dynamoClient *dynamodb.Client, cfg *config.Config) (*Database, error) {
+// NewDatabase creates and initializes database clients.
+// If postgresPassword is not nil, it replaces the configured PostgreSQL password.
+func NewDatabase(ctx context.Context, dynamoClient *dynamodb.Client, cfg *config.Config, postgresPassword *secrets.Secret) (*Database, error) {
 	// Initialize PostgreSQL
-	pgDB, err := NewPostgresDB(ctx, cfg)
+	pgDB, err := NewPostgresDB(ctx, cfg, postgresPassword)
 	if err != nil {
 		return nil, err
 	}
 
+	// Refuse to serve against a schema that is dirty or behind this binary
+	migrator, err := NewMigrator(pgDB.GetPool())
+	if err != nil {
+		pgDB.Close()
+		return nil, err
+	}
+	if err := migrator.Check(ctx); err != nil {
+		pgDB.Close()
+		return nil, fmt.Errorf("schema version check failed: %w", err)
+	}
+
+	readRouter := NewReplicaRouter(pgDB.GetPool(), openReplica(ctx, cfg.Database, postgresPassword), cfg.Database.ReplicaMaxLag)
+	readRouter.Start(ctx, cfg.Database.ReplicaLagCheckPeriod)
+
 	return &Database{
 		dynamoClient:     dynamoClient,
 		postgresPool:     pgDB.GetPool(),
+		readRouter:       readRouter,
 		deviceTable:      cfg.Database.DeviceTableName,
 		machineDataTable: cfg.Database.DataTableName,
 	}, nil
@@ -43,6 +63,20 @@ func (db *Database) GetPostgresPool() *pgxpool.Pool {
 	return db.postgresPool
 }
 
+// GetReadRouter returns the router that picks the pool for read-only queries
+func (db *Database) GetReadRouter() *ReplicaRouter {
+	return db.readRouter
+}
+
+// GetPools returns the PostgreSQL pools by role: primary, and replica when one is connected
+func (db *Database) GetPools() map[string]*pgxpool.Pool {
+	pools := map[string]*pgxpool.Pool{"primary": db.postgresPool}
+	if db.readRouter != nil && db.readRouter.replica != nil {
+		pools["replica"] = db.readRouter.replica
+	}
+	return pools
+}
+
 // GetDeviceTableName returns the device table name
 func (db *Database) GetDeviceTableName() string {
 	return db.deviceTable
@@ -55,8 +89,40 @@ func (db *Database) GetMachineDataTableName() string {
 
 // Close closes all database connections
 func (db *Database) Close() {
+	if db.readRouter != nil {
+		db.readRouter.Close()
+	}
+
 	// Close PostgreSQL connection if it's initialized
 	if db.postgresPool != nil {
 		db.postgresPool.Close()
 	}
 }
+
+// openReplica connects to the read replica if one is configured. The password
+// secret is used unless the replica DSN has its own password. An unreachable
+// replica is not fatal: its pool is kept, and the lag checks of the
+// ReplicaRouter route reads to the primary until it can be reached.
+func openReplica(ctx context.Context, database config.DatabaseConfig, password *secrets.Secret) *pgxpool.Pool {
+	if database.ReplicaDSN == "" {
+		return nil
+	}
+
+	pgConfig, err := parsePoolConfig(database.ReplicaDSN, database.Pool)
+	if err != nil {
+		slog.WarnContext(ctx, "PostgreSQL replica unavailable, routing reads to the primary", "error", err)
+		return nil
+	}
+	if password != nil && pgConfig.ConnConfig.Password == "" {
+		usePassword(pgConfig, password)
+	}
+
+	// The pool connects lazily, so this only fails on an invalid configuration
+	pool, err := pgxpool.NewWithConfig(ctx, pgConfig)
+	if err != nil {
+		slog.WarnContext(ctx, "PostgreSQL replica unavailable, routing reads to the primary", "error", err)
+		return nil
+	}
+
+	return pool
+}
@@ -0,0 +1,353 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/db/migrations"
+)
+
+// migrationLockID is the pg_advisory_lock key held while migrations run so
+// that concurrent deploys cannot apply the same migration twice
+const migrationLockID int64 = 7262783149
+
+// createSchemaMigrationsTable matches the table used by the golang-migrate CLI
+// so databases migrated with `make migrate-up` before the runner was embedded
+// keep their recorded version
+const createSchemaMigrationsTable = `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version bigint NOT NULL PRIMARY KEY,
+		dirty boolean NOT NULL
+	)
+`
+
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+var (
+	// ErrDirtySchema is returned when a previous migration failed part-way and
+	// the schema must be repaired by hand before running migrations again
+	ErrDirtySchema = errors.New("database schema is dirty")
+	// ErrUnknownSchemaVersion is returned when the database is at a version
+	// that this binary has no migration for
+	ErrUnknownSchemaVersion = errors.New("unknown database schema version")
+	// ErrSchemaOutOfDate is returned when the database has pending migrations
+	ErrSchemaOutOfDate = errors.New("database schema is out of date")
+)
+
+// Migration is a single versioned schema change
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus describes the schema version of a database
+type MigrationStatus struct {
+	Version uint
+	Dirty   bool
+	Latest  uint
+	Pending []Migration
+	// Known is set when Version is 0 or has a migration in this binary
+	Known bool
+}
+
+// Ahead reports whether the database was migrated past the latest known version
+func (s *MigrationStatus) Ahead() bool {
+	return s.Version > s.Latest
+}
+
+// Err returns an error if the database is dirty, behind the latest known
+// version or at an unknown version below it. A database that is ahead is
+// accepted: during a rolling deploy the new release migrates it while the
+// old one still serves, so migrations must stay compatible with the release
+// before them.
+func (s *MigrationStatus) Err() error {
+	switch {
+	case s.Dirty:
+		return fmt.Errorf("%w at version %d", ErrDirtySchema, s.Version)
+	case s.Ahead():
+		return nil
+	case !s.Known:
+		return fmt.Errorf("%w %d (latest known is %d)", ErrUnknownSchemaVersion, s.Version, s.Latest)
+	case s.Version < s.Latest:
+		return fmt.Errorf("%w: at version %d, expected %d; run `migrate up`", ErrSchemaOutOfDate, s.Version, s.Latest)
+	}
+	return nil
+}
+
+// Migrator applies the embedded migrations to a PostgreSQL database
+type Migrator struct {
+	pool       *pgxpool.Pool
+	migrations []Migration
+}
+
+// NewMigrator creates a Migrator for the migrations embedded in the binary
+func NewMigrator(pool *pgxpool.Pool) (*Migrator, error) {
+	list, err := LoadMigrations(migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{pool: pool, migrations: list}, nil
+}
+
+// LoadMigrations reads NNNNNN_name.{up,down}.sql pairs from fsys, ordered by version
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[uint]*Migration)
+	for _, entry := range entries {
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 32)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("invalid migration version in %s", entry.Name())
+		}
+
+		contents, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[uint(version)]
+		if !ok {
+			m = &Migration{Version: uint(version), Name: match[2]}
+			byVersion[uint(version)] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(contents)
+		} else {
+			m.Down = string(contents)
+		}
+	}
+
+	list := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s is missing its up or down file", m.Version, m.Name)
+		}
+		list = append(list, *m)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+
+	return list, nil
+}
+
+// Latest returns the highest known migration version
+func (m *Migrator) Latest() uint {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Status reports the current schema version and the migrations still to apply
+func (m *Migrator) Status(ctx context.Context) (*MigrationStatus, error) {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	version, dirty, err := readSchemaVersion(ctx, conn.Conn())
+	if err != nil {
+		return nil, err
+	}
+
+	status := &MigrationStatus{Version: version, Dirty: dirty, Latest: m.Latest(), Known: version == 0 || m.index(version) >= 0}
+	for _, migration := range m.migrations {
+		if migration.Version > version {
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+	return status, nil
+}
+
+// Check returns an error unless the database is clean and at or ahead of the
+// latest known version, as described by MigrationStatus.Err
+func (m *Migrator) Check(ctx context.Context) error {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	return status.Err()
+}
+
+// Up applies every pending migration
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.To(ctx, m.Latest())
+}
+
+// Down rolls back the given number of applied migrations
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	if steps < 1 {
+		return fmt.Errorf("steps must be at least 1")
+	}
+
+	return m.withLock(ctx, func(conn *pgx.Conn) error {
+		version, err := m.currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		idx := m.index(version)
+		return m.migrateTo(ctx, conn, version, m.versionAt(idx-steps))
+	})
+}
+
+// To migrates up or down until the schema is at the given version; 0 rolls back everything
+func (m *Migrator) To(ctx context.Context, target uint) error {
+	if target != 0 && m.index(target) < 0 {
+		return fmt.Errorf("%w %d", ErrUnknownSchemaVersion, target)
+	}
+
+	return m.withLock(ctx, func(conn *pgx.Conn) error {
+		version, err := m.currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		return m.migrateTo(ctx, conn, version, target)
+	})
+}
+
+// migrateTo walks the migration list from version to target one step at a time
+func (m *Migrator) migrateTo(ctx context.Context, conn *pgx.Conn, version, target uint) error {
+	for _, migration := range m.migrations {
+		if migration.Version > version && migration.Version <= target {
+			if err := m.apply(ctx, conn, migration.Version, migration.Name, migration.Up, migration.Version); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if migration.Version <= version && migration.Version > target {
+			if err := m.apply(ctx, conn, migration.Version, migration.Name, migration.Down, m.versionAt(i-1)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// apply runs one migration and records the resulting version in the same transaction
+func (m *Migrator) apply(ctx context.Context, conn *pgx.Conn, version uint, name, sql string, newVersion uint) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// No arguments, so pgx uses the simple protocol and multi-statement files run as written
+	if _, err := tx.Exec(ctx, sql); err != nil {
+		return fmt.Errorf("failed to apply migration %d_%s: %w", version, name, err)
+	}
+
+	if _, err := tx.Exec(ctx, "TRUNCATE schema_migrations"); err != nil {
+		return fmt.Errorf("failed to clear schema version: %w", err)
+	}
+	if newVersion != 0 {
+		if _, err := tx.Exec(ctx, "INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)", int64(newVersion)); err != nil {
+			return fmt.Errorf("failed to record schema version: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit migration %d_%s: %w", version, name, err)
+	}
+	return nil
+}
+
+// currentVersion reads the schema version and rejects dirty or unknown ones
+func (m *Migrator) currentVersion(ctx context.Context, conn *pgx.Conn) (uint, error) {
+	version, dirty, err := readSchemaVersion(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+	if dirty {
+		return 0, fmt.Errorf("%w at version %d", ErrDirtySchema, version)
+	}
+	if version != 0 && m.index(version) < 0 {
+		return 0, fmt.Errorf("%w %d", ErrUnknownSchemaVersion, version)
+	}
+	return version, nil
+}
+
+// withLock runs fn on a single connection holding the migration advisory lock
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgx.Conn) error) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.Exec(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", migrationLockID)
+
+	if _, err := conn.Exec(ctx, createSchemaMigrationsTable); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	return fn(conn.Conn())
+}
+
+// index returns the position of version in the migration list, or -1
+func (m *Migrator) index(version uint) int {
+	for i, migration := range m.migrations {
+		if migration.Version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+// versionAt returns the version at position i, or 0 before the first migration
+func (m *Migrator) versionAt(i int) uint {
+	if i < 0 {
+		return 0
+	}
+	return m.migrations[i].Version
+}
+
+// readSchemaVersion returns the recorded version, treating a missing table as version 0
+func readSchemaVersion(ctx context.Context, conn *pgx.Conn) (uint, bool, error) {
+	var exists bool
+	if err := conn.QueryRow(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists); err != nil {
+		return 0, false, fmt.Errorf("failed to check schema_migrations table: %w", err)
+	}
+	if !exists {
+		return 0, false, nil
+	}
+
+	var version int64
+	var dirty bool
+	err := conn.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return uint(version), dirty, nil
+}
@@ -0,0 +1,45 @@
+//go:build integration
+
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/db"
+	"github.com/afreedicp/zolaris-backend-app/internal/testutil/pgtest"
+)
+
+func TestMain(m *testing.M) {
+	pgtest.Main(m)
+}
+
+// TestMigrationsRoundTrip checks that every down migration undoes its up migration
+// cleanly enough for the schema to be rebuilt from scratch
+func TestMigrationsRoundTrip(t *testing.T) {
+	pool := pgtest.Pool(t)
+	ctx := context.Background()
+
+	migrator, err := db.NewMigrator(pool)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Check(ctx))
+
+	require.NoError(t, migrator.To(ctx, 0))
+
+	status, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint(0), status.Version)
+	assert.Equal(t, migrator.Latest(), uint(len(status.Pending)))
+	assert.ErrorIs(t, migrator.Check(ctx), db.ErrSchemaOutOfDate)
+
+	require.NoError(t, migrator.Up(ctx))
+
+	status, err = migrator.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, migrator.Latest(), status.Version)
+	assert.False(t, status.Dirty)
+	assert.Empty(t, status.Pending)
+}
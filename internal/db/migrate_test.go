@@ -0,0 +1,65 @@
+package db
+
+import (
+	"regexp"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/db/migrations"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"000002_second.up.sql":   {Data: []byte("CREATE TABLE b ();")},
+		"000002_second.down.sql": {Data: []byte("DROP TABLE b;")},
+		"000001_first.up.sql":    {Data: []byte("CREATE TABLE a ();")},
+		"000001_first.down.sql":  {Data: []byte("DROP TABLE a;")},
+		"README.md":              {Data: []byte("ignored")},
+	}
+
+	list, err := LoadMigrations(fsys)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+
+	assert.Equal(t, Migration{Version: 1, Name: "first", Up: "CREATE TABLE a ();", Down: "DROP TABLE a;"}, list[0])
+	assert.Equal(t, uint(2), list[1].Version)
+}
+
+func TestLoadMigrationsRejectsMissingDown(t *testing.T) {
+	fsys := fstest.MapFS{
+		"000001_first.up.sql": {Data: []byte("CREATE TABLE a ();")},
+	}
+
+	_, err := LoadMigrations(fsys)
+	assert.Error(t, err)
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	list, err := LoadMigrations(migrations.FS)
+	require.NoError(t, err)
+	require.NotEmpty(t, list)
+
+	// The runner wraps each migration in its own transaction
+	txControl := regexp.MustCompile(`(?im)^\s*(BEGIN|COMMIT|ROLLBACK)\s*;`)
+	for i, m := range list {
+		assert.Equal(t, uint(i+1), m.Version, "migration versions must be contiguous")
+		assert.False(t, txControl.MatchString(m.Up), "%06d_%s.up.sql must not manage its own transaction", m.Version, m.Name)
+		assert.False(t, txControl.MatchString(m.Down), "%06d_%s.down.sql must not manage its own transaction", m.Version, m.Name)
+	}
+}
+
+func TestMigrationStatusErr(t *testing.T) {
+	assert.NoError(t, (&MigrationStatus{Version: 3, Latest: 3, Known: true}).Err())
+	assert.ErrorIs(t, (&MigrationStatus{Version: 3, Latest: 3, Known: true, Dirty: true}).Err(), ErrDirtySchema)
+	assert.ErrorIs(t, (&MigrationStatus{Version: 2, Latest: 3, Known: true}).Err(), ErrSchemaOutOfDate)
+	assert.ErrorIs(t, (&MigrationStatus{Version: 2, Latest: 3}).Err(), ErrUnknownSchemaVersion)
+	assert.ErrorIs(t, (&MigrationStatus{Version: 4, Latest: 3, Dirty: true}).Err(), ErrDirtySchema)
+
+	// A newer release has migrated the database during a rolling deploy
+	ahead := &MigrationStatus{Version: 4, Latest: 3}
+	assert.True(t, ahead.Ahead())
+	assert.NoError(t, ahead.Err())
+}
@@ -1,4 +1,2 @@
-BEGIN;
 ALTER TABLE z_entity
 DROP CONSTRAINT IF EXISTS user_id_unique;
-COMMIT;
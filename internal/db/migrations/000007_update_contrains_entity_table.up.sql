@@ -1,3 +1,2 @@
-ALTER TABLE z_entity
-DROP CONSTRAINT user_id_unique;
-COMMIT;
\ No newline at end of file
+ALTER TABLE z_entity
+DROP CONSTRAINT user_id_unique;
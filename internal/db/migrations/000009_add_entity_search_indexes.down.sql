@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_entity_user_id;
+
+DROP INDEX IF EXISTS idx_entity_name_lower;
+
+DROP INDEX IF EXISTS idx_entity_details;
@@ -0,0 +1,5 @@
+CREATE INDEX IF NOT EXISTS idx_entity_details ON z_entity USING gin (details jsonb_path_ops);
+
+CREATE INDEX IF NOT EXISTS idx_entity_name_lower ON z_entity (lower(name) text_pattern_ops);
+
+CREATE INDEX IF NOT EXISTS idx_entity_user_id ON z_entity (user_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_device_entity_id;
+
+ALTER TABLE z_device
+    DROP COLUMN IF EXISTS entity_id;
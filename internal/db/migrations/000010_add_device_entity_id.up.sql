@@ -0,0 +1,4 @@
+ALTER TABLE z_device
+    ADD COLUMN IF NOT EXISTS entity_id uuid REFERENCES z_entity (entity_id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_device_entity_id ON z_device (entity_id);
@@ -0,0 +1,74 @@
+CREATE TABLE IF NOT EXISTS z_entity_history (
+    history_id bigserial PRIMARY KEY,
+    entity_id uuid NOT NULL,
+    action varchar(16) NOT NULL CHECK (action IN ('create', 'update', 'move', 'delete')),
+    actor_id uuid,
+    before jsonb,
+    after jsonb,
+    changed_at timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_entity_history_entity ON z_entity_history (entity_id, changed_at DESC, history_id DESC);
+
+CREATE INDEX idx_entity_history_changed_at ON z_entity_history (changed_at);
+
+-- History rows are never rewritten
+CREATE OR REPLACE FUNCTION reject_entity_history_change()
+RETURNS trigger
+AS $$
+BEGIN
+    RAISE EXCEPTION 'z_entity_history is append-only';
+END;
+$$
+LANGUAGE plpgsql;
+
+CREATE TRIGGER entity_history_append_only
+BEFORE UPDATE OR DELETE ON z_entity_history
+FOR EACH ROW
+EXECUTE FUNCTION reject_entity_history_change();
+
+-- The acting user is taken from the transaction-local setting zolaris.actor_id
+CREATE OR REPLACE FUNCTION record_entity_history()
+RETURNS trigger
+AS $$
+DECLARE
+    actor uuid := NULLIF(current_setting('zolaris.actor_id', TRUE), '')::uuid;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO z_entity_history (entity_id, action, actor_id, before, after)
+            VALUES (NEW.entity_id, 'create', actor, NULL, to_jsonb(NEW));
+        RETURN NEW;
+    END IF;
+    IF TG_OP = 'DELETE' THEN
+        INSERT INTO z_entity_history (entity_id, action, actor_id, before, after)
+            VALUES (OLD.entity_id, 'delete', actor, to_jsonb(OLD), NULL);
+        RETURN OLD;
+    END IF;
+    IF (to_jsonb(OLD) - 'updated_at') = (to_jsonb(NEW) - 'updated_at') THEN
+        RETURN NEW;
+    END IF;
+    INSERT INTO z_entity_history (entity_id, action, actor_id, before, after)
+        VALUES (NEW.entity_id, CASE WHEN OLD.parent_id IS DISTINCT FROM NEW.parent_id THEN
+                'move'
+            ELSE
+                'update'
+            END, actor, to_jsonb(OLD), to_jsonb(NEW));
+    RETURN NEW;
+END;
+$$
+LANGUAGE plpgsql;
+
+CREATE TRIGGER entity_history_record
+AFTER INSERT OR UPDATE OR DELETE ON z_entity
+FOR EACH ROW
+EXECUTE FUNCTION record_entity_history();
+
+-- Seed the history with the entities that already exist
+INSERT INTO z_entity_history (entity_id, action, after, changed_at)
+SELECT
+    e.entity_id,
+    'create',
+    to_jsonb(e),
+    COALESCE(e.created_at, CURRENT_TIMESTAMP)
+FROM
+    z_entity e;
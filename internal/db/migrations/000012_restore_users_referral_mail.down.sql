@@ -0,0 +1,2 @@
+-- Databases migrated before 000006 was written never lost the column, so it is kept
+DROP INDEX IF EXISTS idx_users_referral_mail;
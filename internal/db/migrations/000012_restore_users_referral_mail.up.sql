@@ -0,0 +1,5 @@
+-- 000006 dropped referral_mail, but sign-up and the referral list still use it
+ALTER TABLE z_users
+    ADD COLUMN IF NOT EXISTS referral_mail varchar(255) DEFAULT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_users_referral_mail ON z_users (referral_mail);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS z_audit_log;
+
+DROP FUNCTION IF EXISTS reject_audit_log_change();
@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS z_audit_log (
+    audit_id bigserial PRIMARY KEY,
+    occurred_at timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    actor_id uuid,
+    action varchar(32) NOT NULL,
+    resource_type varchar(32) NOT NULL,
+    resource_id varchar(255) NOT NULL,
+    changes jsonb NOT NULL DEFAULT '{}',
+    ip varchar(64),
+    request_id varchar(128)
+);
+
+CREATE INDEX idx_audit_log_occurred_at ON z_audit_log (occurred_at, audit_id);
+
+CREATE INDEX idx_audit_log_actor ON z_audit_log (actor_id, occurred_at);
+
+CREATE INDEX idx_audit_log_resource ON z_audit_log (resource_type, resource_id, occurred_at);
+
+-- Audit rows are never rewritten
+CREATE OR REPLACE FUNCTION reject_audit_log_change()
+RETURNS trigger
+AS $$
+BEGIN
+    RAISE EXCEPTION 'z_audit_log is append-only';
+END;
+$$
+LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only
+BEFORE UPDATE OR DELETE ON z_audit_log
+FOR EACH ROW
+EXECUTE FUNCTION reject_audit_log_change();
+
+-- TRUNCATE bypasses row triggers
+CREATE TRIGGER audit_log_no_truncate
+BEFORE TRUNCATE ON z_audit_log
+FOR EACH STATEMENT
+EXECUTE FUNCTION reject_audit_log_change();
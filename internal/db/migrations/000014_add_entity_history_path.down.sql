@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_entity_history_path;
+
+ALTER TABLE z_entity_history
+    DROP COLUMN IF EXISTS path;
@@ -0,0 +1,6 @@
+-- The path each history row recorded, so subtree snapshots can find the rows
+-- under a root without reading the history of every entity
+ALTER TABLE z_entity_history
+    ADD COLUMN path ltree GENERATED ALWAYS AS ((after ->> 'path')::ltree) STORED;
+
+CREATE INDEX idx_entity_history_path ON z_entity_history USING gist (path);
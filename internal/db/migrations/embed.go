@@ -0,0 +1,10 @@
+// Package migrations embeds the SQL schema migrations so the server binary
+// can apply and verify them without the files being present on disk.
+package migrations
+
+import "embed"
+
+// FS holds every NNNNNN_name.{up,down}.sql migration in this directory
+//
+//go:embed *.sql
+var FS embed.FS
@@ -3,11 +3,13 @@ package db
 import (
 	"context"
 	"fmt"
-	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/afreedicp/zolaris-backend-app/internal/config"
+	"github.com/afreedicp/zolaris-backend-app/internal/secrets"
+	"github.com/afreedicp/zolaris-backend-app/internal/tracing"
 )
 
 // PostgresDB represents a PostgreSQL database connection
@@ -15,32 +17,68 @@ type PostgresDB struct {
 	pool *pgxpool.Pool
 }
 
-// NewPostgresDB creates a new PostgreSQL database connection
-func NewPostgresDB(ctx context.Context, cfg *config.Config) (*PostgresDB, error) {
-	// Build the connection string
-	connString := fmt.Sprintf(
+// NewPostgresDB creates a new PostgreSQL database connection.
+// If password is not nil, it replaces the password of the connection settings.
+func NewPostgresDB(ctx context.Context, cfg *config.Config, password *secrets.Secret) (*PostgresDB, error) {
+	pgConfig, err := parsePoolConfig(primaryConnString(cfg.Database), cfg.Database.Pool)
+	if err != nil {
+		return nil, err
+	}
+	if password != nil {
+		usePassword(pgConfig, password)
+	}
+
+	pool, err := openPool(ctx, pgConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostgresDB{
+		pool: pool,
+	}, nil
+}
+
+// primaryConnString returns POSTGRES_DSN if it is set, or a connection string built from the individual settings
+func primaryConnString(database config.DatabaseConfig) string {
+	if database.PostgresDSN != "" {
+		return database.PostgresDSN
+	}
+
+	return fmt.Sprintf(
 		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		cfg.Database.PostgresHost,
-		cfg.Database.PostgresPort,
-		cfg.Database.PostgresUser,
-		cfg.Database.PostgresPassword,
-		cfg.Database.PostgresDBName,
-		cfg.Database.PostgresSSLMode,
+		database.PostgresHost,
+		database.PostgresPort,
+		database.PostgresUser,
+		database.PostgresPassword,
+		database.PostgresDBName,
+		database.PostgresSSLMode,
 	)
+}
 
-	// Configure connection pool
+// parsePoolConfig parses a connection string and applies the configured pool
+// settings. Every query of the pool is traced.
+func parsePoolConfig(connString string, poolCfg config.PoolConfig) (*pgxpool.Config, error) {
 	pgConfig, err := pgxpool.ParseConfig(connString)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse postgres config: %w", err)
 	}
+	applyPoolConfig(pgConfig, poolCfg)
+	pgConfig.ConnConfig.Tracer = tracing.QueryTracer{}
+	return pgConfig, nil
+}
 
-	// Set connection pool options
-	pgConfig.MaxConns = 10
-	pgConfig.MinConns = 2
-	pgConfig.MaxConnLifetime = 30 * time.Minute
-	pgConfig.MaxConnIdleTime = 5 * time.Minute
-	pgConfig.HealthCheckPeriod = 1 * time.Minute
+// usePassword makes every new connection authenticate with the current value of
+// password, so a rotated password is picked up without a restart. Connections
+// opened before a rotation stay authenticated until MaxConnLifetime replaces them.
+func usePassword(pgConfig *pgxpool.Config, password *secrets.Secret) {
+	pgConfig.BeforeConnect = func(_ context.Context, connConfig *pgx.ConnConfig) error {
+		connConfig.Password = password.Value()
+		return nil
+	}
+}
 
+// openPool opens a connection pool and checks that the server is reachable
+func openPool(ctx context.Context, pgConfig *pgxpool.Config) (*pgxpool.Pool, error) {
 	// Create the connection pool
 	pool, err := pgxpool.NewWithConfig(ctx, pgConfig)
 	if err != nil {
@@ -49,12 +87,20 @@ func NewPostgresDB(ctx context.Context, cfg *config.Config) (*PostgresDB, error)
 
 	// Test the connection
 	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
 		return nil, fmt.Errorf("failed to ping postgres: %w", err)
 	}
 
-	return &PostgresDB{
-		pool: pool,
-	}, nil
+	return pool, nil
+}
+
+// applyPoolConfig sets the connection pool options
+func applyPoolConfig(pgConfig *pgxpool.Config, poolCfg config.PoolConfig) {
+	pgConfig.MaxConns = poolCfg.MaxConns
+	pgConfig.MinConns = poolCfg.MinConns
+	pgConfig.MaxConnLifetime = poolCfg.MaxConnLifetime
+	pgConfig.MaxConnIdleTime = poolCfg.MaxConnIdleTime
+	pgConfig.HealthCheckPeriod = poolCfg.HealthCheckPeriod
 }
 
 // GetPool returns the underlying pgxpool.Pool
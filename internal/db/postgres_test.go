@@ -0,0 +1,56 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/config"
+	"github.com/afreedicp/zolaris-backend-app/internal/secrets"
+)
+
+// staticProvider returns the same value for every secret
+type staticProvider string
+
+func (p staticProvider) GetSecret(context.Context, string) (string, error) {
+	return string(p), nil
+}
+
+func TestParsePoolConfig(t *testing.T) {
+	pgConfig, err := parsePoolConfig("postgres://app:configured@db:5432/zolaris", config.PoolConfig{
+		MaxConns:          20,
+		MinConns:          4,
+		MaxConnLifetime:   time.Hour,
+		MaxConnIdleTime:   time.Minute,
+		HealthCheckPeriod: 30 * time.Second,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(20), pgConfig.MaxConns)
+	assert.Equal(t, int32(4), pgConfig.MinConns)
+	assert.Equal(t, time.Hour, pgConfig.MaxConnLifetime)
+	assert.Equal(t, time.Minute, pgConfig.MaxConnIdleTime)
+	assert.Equal(t, 30*time.Second, pgConfig.HealthCheckPeriod)
+	assert.Equal(t, "configured", pgConfig.ConnConfig.Password)
+	assert.Nil(t, pgConfig.BeforeConnect)
+}
+
+func TestUsePassword(t *testing.T) {
+	ctx := context.Background()
+	pgConfig, err := parsePoolConfig("postgres://app:configured@db:5432/zolaris", config.PoolConfig{MaxConns: 1})
+	require.NoError(t, err)
+
+	password, err := secrets.Watch(ctx, staticProvider("from-secret"), "db-password", 0)
+	require.NoError(t, err)
+	defer password.Close()
+
+	usePassword(pgConfig, password)
+
+	connConfig := pgConfig.ConnConfig.Copy()
+	require.NoError(t, pgConfig.BeforeConnect(ctx, connConfig))
+	assert.Equal(t, "from-secret", connConfig.Password)
+	assert.Equal(t, "configured", pgConfig.ConnConfig.Password, "the parsed settings are left alone")
+}
@@ -0,0 +1,152 @@
+package db
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// replicaLagQuery measures how far the replica's replay is behind the primary.
+// An idle primary sends no new WAL, so a replica that has replayed everything it
+// received reports no lag instead of the age of the last replayed transaction.
+// A server that is not in recovery has no lag at all.
+const replicaLagQuery = `
+	SELECT CASE
+		WHEN NOT pg_is_in_recovery() THEN 0
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+	END::float8
+`
+
+// ReplicaRouter picks the pool read-only queries run on. Reads go to the replica
+// while its measured replication lag stays within maxLag and fall back to the
+// primary otherwise, or when no replica is configured.
+type ReplicaRouter struct {
+	primary *pgxpool.Pool
+	replica *pgxpool.Pool
+	maxLag  time.Duration
+
+	usable atomic.Bool
+	lag    atomic.Int64 // last measured lag in nanoseconds
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewReplicaRouter creates a router over the given pools. replica may be nil, in
+// which case every read goes to the primary. The replica is not used until its lag
+// has been measured, see Start.
+func NewReplicaRouter(primary, replica *pgxpool.Pool, maxLag time.Duration) *ReplicaRouter {
+	return &ReplicaRouter{
+		primary: primary,
+		replica: replica,
+		maxLag:  maxLag,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// ReadPool returns the pool to run a read-only query on
+func (r *ReplicaRouter) ReadPool() *pgxpool.Pool {
+	if r.replica != nil && r.usable.Load() {
+		return r.replica
+	}
+	return r.primary
+}
+
+// ReadPoolWithin returns the pool for a read that tolerates at most maxLag of
+// replication lag: the replica if it is usable and its last measured lag is
+// within maxLag, the primary otherwise
+func (r *ReplicaRouter) ReadPoolWithin(maxLag time.Duration) *pgxpool.Pool {
+	if r.replica != nil && r.usable.Load() && r.Lag() <= maxLag {
+		return r.replica
+	}
+	return r.primary
+}
+
+// UsingReplica reports whether reads are currently routed to the replica
+func (r *ReplicaRouter) UsingReplica() bool {
+	return r.replica != nil && r.usable.Load()
+}
+
+// Lag returns the replication lag measured by the last check
+func (r *ReplicaRouter) Lag() time.Duration {
+	return time.Duration(r.lag.Load())
+}
+
+// Start measures the replica lag once and then every period until Close is called.
+// It does nothing when no replica is configured.
+func (r *ReplicaRouter) Start(ctx context.Context, period time.Duration) {
+	if r.replica == nil {
+		close(r.done)
+		return
+	}
+
+	// An unreachable replica must not hold up startup for longer than a check
+	checkCtx, cancel := context.WithTimeout(ctx, period)
+	r.check(checkCtx)
+	cancel()
+
+	go func() {
+		defer close(r.done)
+
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+				checkCtx, cancel := context.WithTimeout(context.Background(), period)
+				r.check(checkCtx)
+				cancel()
+			}
+		}
+	}()
+}
+
+// Close stops the lag monitor and closes the replica pool. The primary pool is left open.
+func (r *ReplicaRouter) Close() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+		if r.replica == nil {
+			return
+		}
+		<-r.done
+		r.replica.Close()
+	})
+}
+
+// check measures the replica lag and updates the routing decision
+func (r *ReplicaRouter) check(ctx context.Context) {
+	var seconds float64
+	err := r.replica.QueryRow(ctx, replicaLagQuery).Scan(&seconds)
+	r.update(time.Duration(seconds*float64(time.Second)), err)
+}
+
+// update records a lag measurement and logs when reads move between the replica and the primary
+func (r *ReplicaRouter) update(lag time.Duration, err error) {
+	usable := err == nil && lag <= r.maxLag
+	if err == nil {
+		r.lag.Store(int64(lag))
+	}
+
+	if r.usable.Swap(usable) == usable {
+		return
+	}
+
+	switch {
+	case usable:
+		slog.Info("Routing reads to the PostgreSQL replica", "lag", lag)
+	case err != nil:
+		slog.Warn("PostgreSQL replica lag check failed, routing reads to the primary", "error", err)
+	default:
+		slog.Warn("PostgreSQL replica lag exceeds the maximum, routing reads to the primary", "lag", lag, "max_lag", r.maxLag)
+	}
+}
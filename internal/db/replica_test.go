@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lazyPool creates a pool that never connects, since no query is run on it
+func lazyPool(t *testing.T, host string) *pgxpool.Pool {
+	t.Helper()
+
+	pool, err := pgxpool.New(context.Background(), "postgres://user@"+host+":5432/zolaris")
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func TestReplicaRouter(t *testing.T) {
+	primary := lazyPool(t, "primary")
+	replica := lazyPool(t, "replica")
+
+	t.Run("without a replica reads go to the primary", func(t *testing.T) {
+		router := NewReplicaRouter(primary, nil, time.Second)
+		router.Start(context.Background(), time.Second)
+		defer router.Close()
+
+		assert.Same(t, primary, router.ReadPool())
+		assert.False(t, router.UsingReplica())
+	})
+
+	t.Run("replica is not used before its lag is known", func(t *testing.T) {
+		router := NewReplicaRouter(primary, replica, time.Second)
+		assert.Same(t, primary, router.ReadPool())
+	})
+
+	t.Run("reads follow the measured lag", func(t *testing.T) {
+		router := NewReplicaRouter(primary, replica, time.Second)
+
+		router.update(200*time.Millisecond, nil)
+		assert.Same(t, replica, router.ReadPool())
+		assert.Equal(t, 200*time.Millisecond, router.Lag())
+
+		router.update(3*time.Second, nil)
+		assert.Same(t, primary, router.ReadPool())
+		assert.Equal(t, 3*time.Second, router.Lag())
+
+		router.update(time.Second, nil)
+		assert.Same(t, replica, router.ReadPool(), "lag equal to the threshold is acceptable")
+
+		router.update(0, errors.New("connection refused"))
+		assert.Same(t, primary, router.ReadPool())
+		assert.Equal(t, time.Second, router.Lag(), "a failed check keeps the last measured lag")
+	})
+
+	t.Run("reads within a tighter lag tolerance", func(t *testing.T) {
+		router := NewReplicaRouter(primary, replica, time.Second)
+		assert.Same(t, primary, router.ReadPoolWithin(time.Minute))
+
+		router.update(50*time.Millisecond, nil)
+		assert.Same(t, replica, router.ReadPoolWithin(100*time.Millisecond))
+
+		router.update(500*time.Millisecond, nil)
+		assert.Same(t, primary, router.ReadPoolWithin(100*time.Millisecond))
+		assert.Same(t, replica, router.ReadPool())
+	})
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Actions recorded in the audit log
+const (
+	AuditActionCreate       = "create"
+	AuditActionUpdate       = "update"
+	AuditActionImport       = "import"
+	AuditActionAttachPolicy = "attach_policy"
+)
+
+// Resource types recorded in the audit log
+const (
+	AuditResourceUser        = "user"
+	AuditResourceEntity      = "entity"
+	AuditResourceCategory    = "category"
+	AuditResourceDevice      = "device"
+	AuditResourceIoTIdentity = "iot_identity"
+)
+
+// AuditChange is the value of one field before and after an action. A field
+// that did not exist before or after is omitted on that side.
+type AuditChange struct {
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// AuditEntry is one action recorded in the append-only audit log
+type AuditEntry struct {
+	ID           int64                  `json:"id" db:"audit_id"`
+	OccurredAt   time.Time              `json:"occurredAt" db:"occurred_at"`
+	ActorID      *string                `json:"actorId,omitempty" db:"actor_id"`
+	Action       string                 `json:"action" db:"action"`
+	ResourceType string                 `json:"resourceType" db:"resource_type"`
+	ResourceID   string                 `json:"resourceId" db:"resource_id"`
+	Changes      map[string]AuditChange `json:"changes" db:"changes"`
+	IP           *string                `json:"ip,omitempty" db:"ip"`
+	RequestID    *string                `json:"requestId,omitempty" db:"request_id"`
+}
+
+// AuditFilter selects audit entries. Empty fields match everything; From is
+// inclusive and To exclusive.
+type AuditFilter struct {
+	ActorID      string
+	Action       string
+	ResourceType string
+	ResourceID   string
+	From         time.Time
+	To           time.Time
+}
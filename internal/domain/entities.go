@@ -37,6 +37,7 @@ type Address struct {
 type Device struct {
 	MacAddress  string    `json:"macAddress" db:"mac_address"`
 	UserID      string    `json:"userId" db:"user_id"`
+	EntityID    *string   `json:"entityId,omitempty" db:"entity_id"`
 	Name        string    `json:"name" db:"device_name"`
 	Category    *string   `json:"category,omitempty" db:"category"`
 	Description *string   `json:"description,omitempty" db:"description"`
@@ -102,6 +103,23 @@ type Entity struct {
 	UpdatedAt  time.Time       `json:"updatedAt" db:"updated_at"`
 }
 
+// EntityNode is an entity within a loaded hierarchy
+type EntityNode struct {
+	Entity
+	CategoryName string `json:"categoryName"`
+	CategoryType string `json:"categoryType"`
+	// ChildCount is the number of direct children stored, whether or not they were loaded
+	ChildCount int           `json:"childCount"`
+	Children   []*EntityNode `json:"children,omitempty"`
+}
+
+// EntityHierarchy is a depth-limited subtree rooted at a single entity
+type EntityHierarchy struct {
+	Root *EntityNode `json:"root"`
+	// NextCursor continues the listing of the root's direct children, empty on the last page
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
 // NewCategory creates a new Category with default values
 func NewCategory(name, categoryType string) *Category {
 	return &Category{
@@ -111,3 +129,134 @@ func NewCategory(name, categoryType string) *Category {
 		CreatedAt: time.Now(),
 	}
 }
+
+// Kinds of rows in a bulk entity import
+const (
+	ImportKindEntity = "entity"
+	ImportKindDevice = "device"
+)
+
+// EntityImportRow is one entity or device of a bulk import.
+// Rows reference their parent entity row by ParentRef; an empty ParentRef attaches
+// the row to the entity the import is rooted at.
+type EntityImportRow struct {
+	Row        int            `json:"row"`
+	Kind       string         `json:"kind"`
+	Ref        string         `json:"ref"`
+	ParentRef  string         `json:"parentRef,omitempty"`
+	Name       string         `json:"name"`
+	CategoryID string         `json:"categoryId,omitempty"`
+	Details    map[string]any `json:"details,omitempty"`
+	DeviceID   string         `json:"deviceId,omitempty"`
+}
+
+// EntityImportError describes why a single import row was rejected
+type EntityImportError struct {
+	Row     int    `json:"row"`
+	Ref     string `json:"ref,omitempty"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// EntityImportResult summarises a bulk import
+type EntityImportResult struct {
+	DryRun          bool `json:"dryRun"`
+	EntitiesCreated int  `json:"entitiesCreated"`
+	DevicesImported int  `json:"devicesImported"`
+	// EntityIDs maps each entity row reference to the ID it was created with
+	EntityIDs map[string]string   `json:"entityIds"`
+	Errors    []EntityImportError `json:"errors,omitempty"`
+}
+
+// EntitySubtree is a full subtree with the devices assigned to its entities
+type EntitySubtree struct {
+	// Entities are ordered so that every parent precedes its children; the first is the root
+	Entities []*EntityNode `json:"entities"`
+	Devices  []*Device     `json:"devices"`
+}
+
+// MetricSummary summarises the numeric readings of one sensor metric
+type MetricSummary struct {
+	Count int     `json:"count"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Avg   float64 `json:"avg"`
+}
+
+// SensorAggregate rolls up the sensor readings of every device within part of an entity tree
+type SensorAggregate struct {
+	DeviceCount  int            `json:"deviceCount"`
+	ReadingCount int            `json:"readingCount"`
+	Temperature  *MetricSummary `json:"temperature,omitempty"`
+	Humidity     *MetricSummary `json:"humidity,omitempty"`
+	Amperage     *MetricSummary `json:"amperage,omitempty"`
+}
+
+// EntityMetrics is the sensor aggregate of an entity's subtree
+type EntityMetrics struct {
+	EntityID   string          `json:"entityId"`
+	EntityName string          `json:"entityName"`
+	Aggregate  SensorAggregate `json:"aggregate"`
+}
+
+// SubtreeMetrics holds sensor aggregates for a whole subtree and for each direct child of its root
+type SubtreeMetrics struct {
+	Total     EntityMetrics   `json:"total"`
+	Children  []EntityMetrics `json:"children"`
+	StartTime int64           `json:"startTime"`
+	EndTime   int64           `json:"endTime"`
+	// FailedDevices lists devices whose readings could not be fetched and are missing from the aggregates
+	FailedDevices []string `json:"failedDevices,omitempty"`
+}
+
+// Actions recorded in the entity history
+const (
+	EntityActionCreate = "create"
+	EntityActionUpdate = "update"
+	EntityActionMove   = "move"
+	EntityActionDelete = "delete"
+)
+
+// EntityHistoryEntry is one recorded change to an entity row
+type EntityHistoryEntry struct {
+	ID        int64           `json:"id" db:"history_id"`
+	EntityID  string          `json:"entityId" db:"entity_id"`
+	Action    string          `json:"action" db:"action"`
+	ActorID   *string         `json:"actorId,omitempty" db:"actor_id"`
+	Before    json.RawMessage `json:"before,omitempty" db:"before"`
+	After     json.RawMessage `json:"after,omitempty" db:"after"`
+	ChangedAt time.Time       `json:"changedAt" db:"changed_at"`
+}
+
+// EntitySnapshot is the state of an entity at a point in time, as reconstructed from its history
+type EntitySnapshot struct {
+	EntityID string  `json:"entityId"`
+	Name     string  `json:"name"`
+	ParentID *string `json:"parentId,omitempty"`
+	Path     string  `json:"path"`
+}
+
+// EntityMove records an entity whose parent changed
+type EntityMove struct {
+	EntityID     string  `json:"entityId"`
+	Name         string  `json:"name"`
+	FromParentID *string `json:"fromParentId,omitempty"`
+	ToParentID   *string `json:"toParentId,omitempty"`
+}
+
+// EntityRename records an entity whose name changed
+type EntityRename struct {
+	EntityID string `json:"entityId"`
+	FromName string `json:"fromName"`
+	ToName   string `json:"toName"`
+}
+
+// EntityTreeDiff lists the differences in a subtree between two points in time
+type EntityTreeDiff struct {
+	From    time.Time        `json:"from"`
+	To      time.Time        `json:"to"`
+	Added   []EntitySnapshot `json:"added"`
+	Removed []EntitySnapshot `json:"removed"`
+	Moved   []EntityMove     `json:"moved"`
+	Renamed []EntityRename   `json:"renamed"`
+}
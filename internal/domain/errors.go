@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error kinds shared by repositories and services. Match them with errors.Is;
+// the HTTP layer maps each kind to a status code and a stable error code.
+var (
+	ErrNotFound    = errors.New("not found")
+	ErrConflict    = errors.New("conflict")
+	ErrForbidden   = errors.New("forbidden")
+	ErrValidation  = errors.New("validation failed")
+	ErrUnavailable = errors.New("service unavailable")
+)
+
+// Error is a failure of a known kind with a message that is safe to show to clients
+type Error struct {
+	Kind    error
+	Message string
+	Err     error
+}
+
+// Error returns the client-facing message, followed by the cause if there is one
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+// Is reports whether target is the kind of this error
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+// Unwrap returns the underlying cause
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NewNotFoundError returns an ErrNotFound error with a formatted message
+func NewNotFoundError(format string, args ...any) error {
+	return &Error{Kind: ErrNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+// NewConflictError returns an ErrConflict error with a formatted message
+func NewConflictError(format string, args ...any) error {
+	return &Error{Kind: ErrConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+// NewForbiddenError returns an ErrForbidden error with a formatted message
+func NewForbiddenError(format string, args ...any) error {
+	return &Error{Kind: ErrForbidden, Message: fmt.Sprintf(format, args...)}
+}
+
+// NewValidationError returns an ErrValidation error with a formatted message
+func NewValidationError(format string, args ...any) error {
+	return &Error{Kind: ErrValidation, Message: fmt.Sprintf(format, args...)}
+}
+
+// NewUnavailableError returns an ErrUnavailable error wrapping the failure of a backing service
+func NewUnavailableError(err error, format string, args ...any) error {
+	return &Error{Kind: ErrUnavailable, Message: fmt.Sprintf(format, args...), Err: err}
+}
+
+// ErrorMessage returns the client-facing message of a typed error, or fallback
+// when err carries no domain.Error
+func ErrorMessage(err error, fallback string) string {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Message
+	}
+	return fallback
+}
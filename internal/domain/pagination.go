@@ -0,0 +1,21 @@
+package domain
+
+// ListOptions selects one page of a list. A Cursor from a previous page takes
+// precedence over Page; Sort names a field, prefixed with "-" for descending order.
+type ListOptions struct {
+	Page     int
+	PageSize int
+	Cursor   string
+	Sort     string
+	Filters  map[string]string
+}
+
+// Page is one page of a list together with the total number of matching items.
+// NextCursor is empty on the last page; Page is 0 when the page was selected by cursor.
+type Page[T any] struct {
+	Items      []T
+	TotalItems int64
+	Page       int
+	PageSize   int
+	NextCursor string
+}
@@ -0,0 +1,98 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/iot"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/db"
+)
+
+// Pinger is implemented by *pgxpool.Pool
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// PostgresPing checks that a connection of the pool can reach the server
+func PostgresPing(pool Pinger) Check {
+	return func(ctx context.Context) error {
+		if err := pool.Ping(ctx); err != nil {
+			return fmt.Errorf("failed to ping postgres: %w", err)
+		}
+		return nil
+	}
+}
+
+// SchemaChecker is implemented by *db.Migrator
+type SchemaChecker interface {
+	Status(ctx context.Context) (*db.MigrationStatus, error)
+}
+
+// SchemaVersion checks that the database schema is clean and not behind this
+// binary. A schema migrated ahead by a newer release is reported without failing.
+func SchemaVersion(migrator SchemaChecker) Check {
+	return func(ctx context.Context) error {
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		if err := status.Err(); err != nil {
+			return err
+		}
+		if status.Ahead() {
+			return Notice(fmt.Sprintf("schema version %d is ahead of the latest known version %d", status.Version, status.Latest))
+		}
+		return nil
+	}
+}
+
+// TableDescriber is implemented by *dynamodb.Client
+type TableDescriber interface {
+	DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error)
+}
+
+// DynamoDBTables checks that every table exists and is active or being updated
+func DynamoDBTables(client TableDescriber, tables ...string) Check {
+	return func(ctx context.Context) error {
+		for _, table := range tables {
+			out, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(table)})
+			if err != nil {
+				return fmt.Errorf("failed to describe table %s: %w", table, err)
+			}
+			if out.Table == nil {
+				return fmt.Errorf("table %s not described", table)
+			}
+			if status := out.Table.TableStatus; status != types.TableStatusActive && status != types.TableStatusUpdating {
+				return fmt.Errorf("table %s is %s", table, status)
+			}
+		}
+		return nil
+	}
+}
+
+// IoTConfig checks that the IoT client has a region and credentials, and that
+// a policy to attach to devices is configured. It makes no call to AWS IoT.
+func IoTConfig(client *iot.Client, policyName string) Check {
+	return func(ctx context.Context) error {
+		if policyName == "" {
+			return errors.New("no IoT policy configured")
+		}
+		options := client.Options()
+		if options.Region == "" {
+			return errors.New("no AWS region configured")
+		}
+		if options.Credentials == nil {
+			return errors.New("no AWS credentials configured")
+		}
+		// Credentials are cached, so this only calls out when they need refreshing
+		if _, err := options.Credentials.Retrieve(ctx); err != nil {
+			return fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+		}
+		return nil
+	}
+}
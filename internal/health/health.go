@@ -0,0 +1,156 @@
+// Package health checks the dependencies of the application for the readiness probe.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Check reports whether a dependency is usable. It should return once ctx is done.
+type Check func(ctx context.Context) error
+
+// Notice is returned by a check that passes but has something to report,
+// which is shown as the detail of its result
+type Notice string
+
+func (n Notice) Error() string {
+	return string(n)
+}
+
+// Statuses of a check and of a report
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// CheckResult is the outcome of one check
+type CheckResult struct {
+	Status     string `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+	Detail     string `json:"detail,omitempty"`
+}
+
+// Report is the outcome of every check; it is up only if every check is
+type Report struct {
+	Status    string                 `json:"status"`
+	Checks    map[string]CheckResult `json:"checks"`
+	CheckedAt time.Time              `json:"checked_at"`
+}
+
+// Up reports whether every check passed
+func (r *Report) Up() bool {
+	return r.Status == StatusUp
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker runs the registered checks concurrently, each bounded by a timeout.
+// A report is reused for the cache TTL, so frequent probes do not load the
+// dependencies; probes arriving during a run wait for its result.
+type Checker struct {
+	timeout time.Duration
+	ttl     time.Duration
+	checks  []namedCheck
+
+	mu       sync.Mutex
+	last     *Report
+	draining atomic.Bool
+}
+
+// NewChecker creates a Checker giving each check timeout to complete and
+// caching its report for ttl
+func NewChecker(timeout, ttl time.Duration) *Checker {
+	return &Checker{timeout: timeout, ttl: ttl}
+}
+
+// Add registers a check under name. Checks must be added before the first Report.
+func (c *Checker) Add(name string, check Check) {
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// Drain makes every later report down without running the checks, so load
+// balancers stop routing requests to an instance that is shutting down
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+// Report returns the cached report, or runs the checks if it has expired
+func (c *Checker) Report(ctx context.Context) *Report {
+	if c.draining.Load() {
+		return &Report{
+			Status:    StatusDown,
+			Checks:    map[string]CheckResult{"shutdown": {Status: StatusDown, Error: "shutting down"}},
+			CheckedAt: time.Now(),
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.last != nil && time.Since(c.last.CheckedAt) < c.ttl {
+		return c.last
+	}
+	c.last = c.run(ctx)
+	return c.last
+}
+
+// run runs every check. The report is shared by other probes, so a check is
+// not cut short when the probe that triggered it goes away.
+func (c *Checker) run(ctx context.Context) *Report {
+	ctx = context.WithoutCancel(ctx)
+
+	results := make([]CheckResult, len(c.checks))
+	var wg sync.WaitGroup
+	for i, nc := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.runCheck(ctx, nc.check)
+		}()
+	}
+	wg.Wait()
+
+	report := &Report{Status: StatusUp, Checks: make(map[string]CheckResult, len(c.checks)), CheckedAt: time.Now()}
+	for i, nc := range c.checks {
+		report.Checks[nc.name] = results[i]
+		if results[i].Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+func (c *Checker) runCheck(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- check(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		// Do not wait for a check that ignores its deadline
+		err = fmt.Errorf("timed out after %s", c.timeout)
+	}
+
+	result := CheckResult{Status: StatusUp, DurationMs: time.Since(start).Milliseconds()}
+	var notice Notice
+	if errors.As(err, &notice) {
+		result.Detail = string(notice)
+	} else if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
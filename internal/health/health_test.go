@@ -0,0 +1,141 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/iot"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/db"
+)
+
+func TestCheckerReport(t *testing.T) {
+	checker := NewChecker(50*time.Millisecond, time.Minute)
+	checker.Add("postgres", func(ctx context.Context) error { return nil })
+	checker.Add("dynamodb", func(ctx context.Context) error { return errors.New("table not found") })
+	checker.Add("hanging", func(ctx context.Context) error {
+		time.Sleep(time.Second) // ignores its deadline
+		return nil
+	})
+
+	start := time.Now()
+	report := checker.Report(context.Background())
+	assert.Less(t, time.Since(start), time.Second)
+
+	assert.False(t, report.Up())
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, CheckResult{Status: StatusUp, DurationMs: report.Checks["postgres"].DurationMs}, report.Checks["postgres"])
+	assert.Equal(t, StatusDown, report.Checks["dynamodb"].Status)
+	assert.Equal(t, "table not found", report.Checks["dynamodb"].Error)
+	assert.Equal(t, "timed out after 50ms", report.Checks["hanging"].Error)
+}
+
+func TestCheckerCachesReport(t *testing.T) {
+	var calls atomic.Int32
+	checker := NewChecker(time.Second, time.Hour)
+	checker.Add("postgres", func(ctx context.Context) error {
+		calls.Add(1)
+		return nil
+	})
+
+	first := checker.Report(context.Background())
+	assert.True(t, first.Up())
+	assert.Same(t, first, checker.Report(context.Background()))
+	assert.Equal(t, int32(1), calls.Load())
+
+	checker.last.CheckedAt = time.Now().Add(-2 * time.Hour)
+	checker.Report(context.Background())
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestCheckerIgnoresCanceledProbe(t *testing.T) {
+	checker := NewChecker(time.Second, time.Hour)
+	checker.Add("postgres", func(ctx context.Context) error { return ctx.Err() })
+
+	// A probe that gave up must not leave a failed report for the others
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.True(t, checker.Report(ctx).Up())
+}
+
+type fakeTables map[string]types.TableStatus
+
+func (f fakeTables) DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+	status, ok := f[aws.ToString(params.TableName)]
+	if !ok {
+		return nil, &types.ResourceNotFoundException{Message: aws.String("Requested resource not found")}
+	}
+	return &dynamodb.DescribeTableOutput{Table: &types.TableDescription{TableStatus: status}}, nil
+}
+
+func TestDynamoDBTables(t *testing.T) {
+	tables := fakeTables{"devices": types.TableStatusActive, "data": types.TableStatusUpdating, "old": types.TableStatusDeleting}
+
+	assert.NoError(t, DynamoDBTables(tables, "devices", "data")(context.Background()))
+	assert.EqualError(t, DynamoDBTables(tables, "devices", "old")(context.Background()), "table old is DELETING")
+	assert.ErrorContains(t, DynamoDBTables(tables, "missing")(context.Background()), "failed to describe table missing")
+}
+
+type fakeSchema db.MigrationStatus
+
+func (f fakeSchema) Status(ctx context.Context) (*db.MigrationStatus, error) {
+	status := db.MigrationStatus(f)
+	return &status, nil
+}
+
+func TestSchemaVersion(t *testing.T) {
+	checker := NewChecker(time.Second, time.Hour)
+	checker.Add("current", SchemaVersion(fakeSchema{Version: 14, Latest: 14, Known: true}))
+	checker.Add("ahead", SchemaVersion(fakeSchema{Version: 15, Latest: 14}))
+	report := checker.Report(context.Background())
+
+	// An old instance stays ready while a newer release rolls out
+	assert.True(t, report.Up())
+	assert.Empty(t, report.Checks["current"].Detail)
+	assert.Equal(t, StatusUp, report.Checks["ahead"].Status)
+	assert.Equal(t, "schema version 15 is ahead of the latest known version 14", report.Checks["ahead"].Detail)
+
+	assert.ErrorIs(t, SchemaVersion(fakeSchema{Version: 13, Latest: 14, Known: true})(context.Background()), db.ErrSchemaOutOfDate)
+	assert.ErrorIs(t, SchemaVersion(fakeSchema{Version: 15, Latest: 14, Dirty: true})(context.Background()), db.ErrDirtySchema)
+}
+
+func TestIoTConfig(t *testing.T) {
+	client := iot.New(iot.Options{
+		Region:      "ap-south-1",
+		Credentials: credentials.NewStaticCredentialsProvider("key", "secret", ""),
+	})
+	require.NoError(t, IoTConfig(client, "device-policy")(context.Background()))
+	assert.EqualError(t, IoTConfig(client, "")(context.Background()), "no IoT policy configured")
+
+	noRegion := iot.New(iot.Options{Credentials: credentials.NewStaticCredentialsProvider("key", "secret", "")})
+	assert.EqualError(t, IoTConfig(noRegion, "device-policy")(context.Background()), "no AWS region configured")
+
+	noCredentials := iot.New(iot.Options{
+		Region: "ap-south-1",
+		Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return aws.Credentials{}, errors.New("no EC2 IMDS role found")
+		}),
+	})
+	assert.ErrorContains(t, IoTConfig(noCredentials, "device-policy")(context.Background()), "failed to retrieve AWS credentials")
+}
+
+func TestCheckerDrain(t *testing.T) {
+	checker := NewChecker(time.Second, time.Hour)
+	checker.Add("postgres", func(ctx context.Context) error { return nil })
+	require.True(t, checker.Report(context.Background()).Up())
+
+	// Draining takes effect at once, even with a cached report
+	checker.Drain()
+	report := checker.Report(context.Background())
+	assert.False(t, report.Up())
+	assert.Equal(t, CheckResult{Status: StatusDown, Error: "shutting down"}, report.Checks["shutdown"])
+}
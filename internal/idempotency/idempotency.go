@@ -0,0 +1,40 @@
+// Package idempotency stores the responses of requests sent with an
+// Idempotency-Key, so retries of a request are answered without running it again.
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// Response is the stored response of a request
+type Response struct {
+	// Fingerprint identifies the request, so a key reused for another request is detected
+	Fingerprint string `json:"fingerprint"`
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body"`
+}
+
+// Store keeps the responses and the locks serializing requests with the same key
+type Store interface {
+	// Get returns the response stored under key, or nil if there is none
+	Get(ctx context.Context, key string) (*Response, error)
+	// Put stores the response under key for ttl
+	Put(ctx context.Context, key string, response *Response, ttl time.Duration) error
+	// Lock claims key for at most ttl. It returns the token to unlock it with,
+	// or "" if another request holds it.
+	Lock(ctx context.Context, key string, ttl time.Duration) (string, error)
+	// Unlock releases key if it is still held with token
+	Unlock(ctx context.Context, key, token string) error
+}
+
+// Fingerprint identifies a request by its method, URI and body
+func Fingerprint(method, uri string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + uri + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
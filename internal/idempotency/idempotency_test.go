@@ -0,0 +1,98 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testStore runs the checks shared by every store. advance moves its clock.
+func testStore(t *testing.T, store Store, advance func(time.Duration)) {
+	ctx := context.Background()
+	response := &Response{Fingerprint: Fingerprint("POST", "/entity/sub", []byte(`{"name":"Hall"}`)), Status: 201, ContentType: "application/json", Body: []byte(`{"id":"42"}`)}
+
+	t.Run("PutAndGet", func(t *testing.T) {
+		got, err := store.Get(ctx, "user-1:key")
+		require.NoError(t, err)
+		assert.Nil(t, got)
+
+		require.NoError(t, store.Put(ctx, "user-1:key", response, time.Hour))
+		got, err = store.Get(ctx, "user-1:key")
+		require.NoError(t, err)
+		assert.Equal(t, response, got)
+
+		advance(time.Hour + time.Second)
+		got, err = store.Get(ctx, "user-1:key")
+		require.NoError(t, err)
+		assert.Nil(t, got, "responses expire")
+	})
+
+	t.Run("Lock", func(t *testing.T) {
+		token, err := store.Lock(ctx, "user-1:lock", time.Minute)
+		require.NoError(t, err)
+		require.NotEmpty(t, token)
+
+		other, err := store.Lock(ctx, "user-1:lock", time.Minute)
+		require.NoError(t, err)
+		assert.Empty(t, other, "a held key cannot be locked")
+
+		require.NoError(t, store.Unlock(ctx, "user-1:lock", token))
+		token, err = store.Lock(ctx, "user-1:lock", time.Minute)
+		require.NoError(t, err)
+		assert.NotEmpty(t, token)
+	})
+
+	t.Run("LockExpires", func(t *testing.T) {
+		stale, err := store.Lock(ctx, "user-1:expiring", time.Minute)
+		require.NoError(t, err)
+
+		advance(time.Minute + time.Second)
+		token, err := store.Lock(ctx, "user-1:expiring", time.Minute)
+		require.NoError(t, err)
+		require.NotEmpty(t, token)
+
+		// The request whose lock expired does not release the new one
+		require.NoError(t, store.Unlock(ctx, "user-1:expiring", stale))
+		other, err := store.Lock(ctx, "user-1:expiring", time.Minute)
+		require.NoError(t, err)
+		assert.Empty(t, other)
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	testStore(t, store, func(d time.Duration) { now = now.Add(d) })
+
+	// Expired entries are swept
+	now = now.Add(2 * time.Hour)
+	store.Get(context.Background(), "other")
+	assert.Empty(t, store.responses)
+	assert.Empty(t, store.locks)
+}
+
+func TestRedisStore(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	store := NewRedisStore(client, "test:")
+	t.Cleanup(func() { client.Close() })
+
+	testStore(t, store, server.FastForward)
+
+	require.NoError(t, store.Put(context.Background(), "user-1:key", &Response{Status: 200}, time.Hour))
+	assert.True(t, server.Exists("test:response:user-1:key"), "keys are namespaced")
+}
+
+func TestFingerprint(t *testing.T) {
+	fingerprint := Fingerprint("POST", "/entity/sub", []byte(`{"name":"Hall"}`))
+	assert.Equal(t, fingerprint, Fingerprint("POST", "/entity/sub", []byte(`{"name":"Hall"}`)))
+	assert.NotEqual(t, fingerprint, Fingerprint("POST", "/entity/sub", []byte(`{"name":"Lab"}`)))
+	assert.NotEqual(t, fingerprint, Fingerprint("POST", "/entity/root", []byte(`{"name":"Hall"}`)))
+}
@@ -0,0 +1,106 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/rand"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often expired entries are dropped from a MemoryStore
+const sweepInterval = time.Minute
+
+type memoryEntry struct {
+	response *Response
+	expires  time.Time
+}
+
+type memoryLock struct {
+	token   string
+	expires time.Time
+}
+
+// MemoryStore keeps the responses in process, so retries must reach the same instance
+type MemoryStore struct {
+	mu        sync.Mutex
+	responses map[string]memoryEntry
+	locks     map[string]memoryLock
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		responses: make(map[string]memoryEntry),
+		locks:     make(map[string]memoryLock),
+		now:       time.Now,
+	}
+}
+
+// Get returns the response stored under key, or nil if there is none
+func (s *MemoryStore) Get(ctx context.Context, key string) (*Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+	entry, ok := s.responses[key]
+	if !ok || !now.Before(entry.expires) {
+		return nil, nil
+	}
+	return entry.response, nil
+}
+
+// Put stores the response under key for ttl
+func (s *MemoryStore) Put(ctx context.Context, key string, response *Response, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.responses[key] = memoryEntry{response: response, expires: s.now().Add(ttl)}
+	return nil
+}
+
+// Lock claims key for at most ttl
+func (s *MemoryStore) Lock(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if lock, ok := s.locks[key]; ok && now.Before(lock.expires) {
+		return "", nil
+	}
+	token := rand.Text()
+	s.locks[key] = memoryLock{token: token, expires: now.Add(ttl)}
+	return token, nil
+}
+
+// Unlock releases key if it is still held with token
+func (s *MemoryStore) Unlock(ctx context.Context, key, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if lock, ok := s.locks[key]; ok && lock.token == token {
+		delete(s.locks, key)
+	}
+	return nil
+}
+
+// sweep drops the expired responses and locks
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, entry := range s.responses {
+		if !now.Before(entry.expires) {
+			delete(s.responses, key)
+		}
+	}
+	for key, lock := range s.locks {
+		if !now.Before(lock.expires) {
+			delete(s.locks, key)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/config"
+)
+
+// Stores selectable with IDEMPOTENCY_STORE
+const (
+	StoreMemory = "memory"
+	StoreRedis  = "redis"
+	StoreNone   = "none"
+)
+
+// redisKeyPrefix namespaces the responses of this service in a shared Redis
+const redisKeyPrefix = "zolaris:idempotency:"
+
+// Open creates the store selected by the configuration. The redis store uses
+// client, opened by redisclient.Open. With none it returns nil, which disables
+// Idempotency-Key support.
+func Open(ctx context.Context, cfg *config.Config, client *redis.Client) (Store, error) {
+	switch cfg.Idempotency.Store {
+	case StoreMemory, "":
+		slog.InfoContext(ctx, "Storing idempotent responses per instance")
+		return NewMemoryStore(), nil
+	case StoreRedis:
+		if client == nil {
+			return nil, errors.New("redis idempotency store requires a redis client")
+		}
+		slog.InfoContext(ctx, "Storing idempotent responses across instances with redis")
+		return NewRedisStore(client, redisKeyPrefix), nil
+	case StoreNone:
+		slog.InfoContext(ctx, "Idempotency keys are ignored")
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown idempotency store %q", cfg.Idempotency.Store)
+	}
+}
@@ -0,0 +1,82 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// unlockScript deletes the lock at KEYS[1] only if it still holds the token
+// ARGV[1], so a request whose lock expired cannot release the lock of the next one
+var unlockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// RedisStore keeps the responses in Redis, so retries may reach any instance
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore creates a store on the given client. Every key is namespaced with prefix.
+func NewRedisStore(client *redis.Client, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// Get returns the response stored under key, or nil if there is none
+func (s *RedisStore) Get(ctx context.Context, key string) (*Response, error) {
+	data, err := s.client.Get(ctx, s.prefix+"response:"+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get response: %w", err)
+	}
+
+	var response Response
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &response, nil
+}
+
+// Put stores the response under key for ttl
+func (s *RedisStore) Put(ctx context.Context, key string, response *Response, ttl time.Duration) error {
+	data, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("failed to encode response: %w", err)
+	}
+	if err := s.client.Set(ctx, s.prefix+"response:"+key, data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to put response: %w", err)
+	}
+	return nil
+}
+
+// Lock claims key for at most ttl
+func (s *RedisStore) Lock(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	token := rand.Text()
+	ok, err := s.client.SetNX(ctx, s.prefix+"lock:"+key, token, ttl).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to lock: %w", err)
+	}
+	if !ok {
+		return "", nil
+	}
+	return token, nil
+}
+
+// Unlock releases key if it is still held with token
+func (s *RedisStore) Unlock(ctx context.Context, key, token string) error {
+	if err := unlockScript.Run(ctx, s.client, []string{s.prefix + "lock:" + key}, token).Err(); err != nil {
+		return fmt.Errorf("failed to unlock: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+// Package lifecycle shuts the application down in phases when it is asked to
+// stop: it turns readiness off, drains the HTTP servers, stops the background
+// workers and finally closes the resources they used, such as the database pool.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/config"
+)
+
+// ErrForced is returned by Run when a second signal cut the shutdown short
+var ErrForced = errors.New("shutdown forced by a second signal")
+
+type closer struct {
+	name  string
+	close func(ctx context.Context) error
+}
+
+// Manager starts the servers and workers of the application and stops them
+// in order. Everything must be registered before Run is called.
+type Manager struct {
+	cfg config.ShutdownConfig
+
+	onDrain []func()
+	servers []*http.Server
+	closers []closer
+
+	workerCtx    context.Context
+	stopWorkers  context.CancelFunc
+	workers      sync.WaitGroup
+	serverErrors chan error
+}
+
+// New creates a Manager whose shutdown phases are bounded by cfg
+func New(cfg config.ShutdownConfig) *Manager {
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	return &Manager{
+		cfg:          cfg,
+		workerCtx:    workerCtx,
+		stopWorkers:  stopWorkers,
+		serverErrors: make(chan error, 1),
+	}
+}
+
+// OnDrain registers fn to run first when shutting down, such as failing the
+// readiness probe so load balancers stop sending new requests
+func (m *Manager) OnDrain(fn func()) {
+	m.onDrain = append(m.onDrain, fn)
+}
+
+// Serve starts server in the background. It is shut down gracefully, letting
+// in-flight requests complete; if it fails to start, the application shuts down.
+func (m *Manager) Serve(server *http.Server) {
+	m.servers = append(m.servers, server)
+	go func() {
+		slog.Info("Server listening", "addr", server.Addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			select {
+			case m.serverErrors <- fmt.Errorf("server %s failed: %w", server.Addr, err):
+			default:
+			}
+		}
+	}()
+}
+
+// Go runs worker in the background until its context is canceled, once the
+// HTTP servers are drained. The worker must return promptly after that.
+func (m *Manager) Go(name string, worker func(ctx context.Context)) {
+	m.workers.Add(1)
+	go func() {
+		defer m.workers.Done()
+		worker(m.workerCtx)
+		slog.Debug("Worker stopped", "worker", name)
+	}()
+}
+
+// OnClose registers close to run after the workers have stopped. Closers run
+// in the reverse order of registration, so a resource is closed before those
+// it was built on.
+func (m *Manager) OnClose(name string, close func(ctx context.Context) error) {
+	m.closers = append(m.closers, closer{name: name, close: close})
+}
+
+// Run blocks until SIGINT or SIGTERM is received or a server fails, then
+// shuts down. A second signal abandons the shutdown and returns ErrForced.
+func (m *Manager) Run() error {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	var cause error
+	select {
+	case sig := <-signals:
+		slog.Info("Shutting down", "signal", sig.String())
+	case cause = <-m.serverErrors:
+		slog.Error("Shutting down", "error", cause)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- m.Shutdown() }()
+
+	select {
+	case err := <-done:
+		return errors.Join(cause, err)
+	case sig := <-signals:
+		slog.Warn("Shutdown abandoned", "signal", sig.String())
+		return ErrForced
+	}
+}
+
+// Shutdown runs the shutdown phases, each bounded by its configured timeout.
+// A phase that times out is logged and the next one starts anyway.
+func (m *Manager) Shutdown() error {
+	var errs []error
+
+	// Phase 1: fail readiness and give the load balancers time to notice
+	for _, fn := range m.onDrain {
+		fn()
+	}
+	if m.cfg.DrainDelay > 0 {
+		slog.Info("Waiting for load balancers to stop routing requests", "delay", m.cfg.DrainDelay)
+		time.Sleep(m.cfg.DrainDelay)
+	}
+
+	// Phase 2: stop accepting connections and let in-flight requests finish
+	errs = append(errs, m.phase("http", m.cfg.HTTPTimeout, func(ctx context.Context) error {
+		var wg sync.WaitGroup
+		serverErrs := make([]error, len(m.servers))
+		for i, server := range m.servers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := server.Shutdown(ctx); err != nil {
+					serverErrs[i] = fmt.Errorf("server %s: %w", server.Addr, err)
+				}
+			}()
+		}
+		wg.Wait()
+		return errors.Join(serverErrs...)
+	}))
+
+	// Phase 3: stop the background workers
+	m.stopWorkers()
+	errs = append(errs, m.phase("workers", m.cfg.WorkerTimeout, func(ctx context.Context) error {
+		stopped := make(chan struct{})
+		go func() {
+			m.workers.Wait()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}))
+
+	// Phase 4: close the resources, such as the database pool
+	errs = append(errs, m.phase("close", m.cfg.CloseTimeout, func(ctx context.Context) error {
+		closed := make(chan error, 1)
+		go func() {
+			var closeErrs []error
+			for i := len(m.closers) - 1; i >= 0; i-- {
+				c := m.closers[i]
+				if err := c.close(ctx); err != nil {
+					closeErrs = append(closeErrs, fmt.Errorf("failed to close %s: %w", c.name, err))
+				}
+			}
+			closed <- errors.Join(closeErrs...)
+		}()
+		// Some closers, such as pgxpool.Pool.Close, cannot be interrupted
+		select {
+		case err := <-closed:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}))
+
+	err := errors.Join(errs...)
+	if err == nil {
+		slog.Info("Shutdown complete")
+	}
+	return err
+}
+
+// phase runs fn with a context that expires after timeout
+func (m *Manager) phase(name string, timeout time.Duration, fn func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := fn(ctx)
+	if err != nil {
+		slog.Error("Shutdown phase failed", "phase", name, "duration_ms", time.Since(start).Milliseconds(), "error", err)
+		return fmt.Errorf("%s phase: %w", name, err)
+	}
+	slog.Info("Shutdown phase complete", "phase", name, "duration_ms", time.Since(start).Milliseconds())
+	return nil
+}
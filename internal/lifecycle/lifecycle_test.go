@@ -0,0 +1,125 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/config"
+)
+
+var testConfig = config.ShutdownConfig{
+	HTTPTimeout:   time.Second,
+	WorkerTimeout: 100 * time.Millisecond,
+	CloseTimeout:  100 * time.Millisecond,
+}
+
+// events records the order in which the shutdown steps happen
+type events struct {
+	mu   sync.Mutex
+	list []string
+}
+
+func (e *events) add(event string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.list = append(e.list, event)
+}
+
+func (e *events) get() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.list...)
+}
+
+func TestShutdownDrainsInOrder(t *testing.T) {
+	var ev events
+	m := New(testConfig)
+
+	// A request that is in flight when the shutdown starts
+	started, release := make(chan struct{}), make(chan struct{})
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		ev.add("request done")
+	})}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	m.servers = append(m.servers, server)
+	go server.Serve(ln)
+
+	m.OnDrain(func() { ev.add("drain") })
+	m.Go("worker", func(ctx context.Context) {
+		<-ctx.Done()
+		ev.add("worker stopped")
+	})
+	m.OnClose("database", func(context.Context) error {
+		ev.add("database closed")
+		return nil
+	})
+	m.OnClose("cache", func(context.Context) error {
+		ev.add("cache closed")
+		return nil
+	})
+
+	response := make(chan error, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String())
+		if err == nil {
+			resp.Body.Close()
+		}
+		response <- err
+	}()
+	<-started
+
+	done := make(chan error, 1)
+	go func() { done <- m.Shutdown() }()
+
+	// The shutdown waits for the request
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, []string{"drain"}, ev.get())
+	close(release)
+
+	require.NoError(t, <-done)
+	require.NoError(t, <-response)
+	assert.Equal(t, []string{"drain", "request done", "worker stopped", "cache closed", "database closed"}, ev.get())
+}
+
+func TestShutdownContinuesAfterPhaseTimeout(t *testing.T) {
+	m := New(testConfig)
+	m.Go("stuck", func(ctx context.Context) { time.Sleep(time.Second) })
+	closed := false
+	m.OnClose("database", func(context.Context) error {
+		closed = true
+		return nil
+	})
+	m.OnClose("cache", func(context.Context) error { return errors.New("connection reset") })
+
+	err := m.Shutdown()
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "workers phase")
+	assert.ErrorContains(t, err, "failed to close cache: connection reset")
+	assert.True(t, closed)
+}
+
+func TestRunShutsDownWhenServerFails(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	m := New(testConfig)
+	drained := false
+	m.OnDrain(func() { drained = true })
+	m.Serve(&http.Server{Addr: ln.Addr().String()})
+
+	err = m.Run()
+	assert.ErrorContains(t, err, "address already in use")
+	assert.True(t, drained)
+}
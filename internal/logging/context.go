@@ -0,0 +1,51 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Attribute keys set by the HTTP middleware
+const (
+	RequestIDKey = "request_id"
+	UserIDKey    = "user_id"
+	RouteKey     = "route"
+	TraceIDKey   = "trace_id"
+)
+
+type contextKey struct{}
+
+// With returns a copy of ctx whose log records carry the given attributes in
+// addition to those already stored in ctx. Arguments are key-value pairs or
+// slog.Attr values, as for slog.Logger.Info.
+func With(ctx context.Context, args ...any) context.Context {
+	attrs := slog.Group("", args...).Value.Group()
+	if len(attrs) == 0 {
+		return ctx
+	}
+
+	parent := contextAttrs(ctx)
+	merged := make([]slog.Attr, 0, len(parent)+len(attrs))
+	merged = append(merged, parent...)
+	merged = append(merged, attrs...)
+	return context.WithValue(ctx, contextKey{}, merged)
+}
+
+// RequestID returns the request ID stored in ctx, or "" if there is none
+func RequestID(ctx context.Context) string {
+	attrs := contextAttrs(ctx)
+	for i := len(attrs) - 1; i >= 0; i-- {
+		if attrs[i].Key == RequestIDKey {
+			return attrs[i].Value.String()
+		}
+	}
+	return ""
+}
+
+func contextAttrs(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(contextKey{}).([]slog.Attr)
+	return attrs
+}
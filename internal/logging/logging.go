@@ -0,0 +1,71 @@
+// Package logging provides the structured logger of the application. Records are
+// written as JSON with the request attributes carried by the context, such as the
+// request ID, user ID and route, and with personal data redacted.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/config"
+)
+
+// Redacted replaces the value of a redacted attribute
+const Redacted = "[REDACTED]"
+
+// New creates a logger writing to w in the configured format. Records below
+// level are dropped; pass a *slog.LevelVar to change the level later.
+func New(w io.Writer, cfg config.LoggingConfig, level slog.Leveler) *slog.Logger {
+	redact := make(map[string]bool, len(cfg.RedactFields))
+	for _, field := range cfg.RedactFields {
+		redact[strings.ToLower(field)] = true
+	}
+
+	opts := &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if redact[strings.ToLower(a.Key)] {
+				return slog.String(a.Key, Redacted)
+			}
+			return a
+		},
+	}
+
+	var handler slog.Handler
+	if cfg.Format == "text" {
+		handler = slog.NewTextHandler(w, opts)
+	} else {
+		handler = slog.NewJSONHandler(w, opts)
+	}
+	return slog.New(contextHandler{handler})
+}
+
+// ParseLevel parses a level name such as "debug" or "warn"
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	err := level.UnmarshalText([]byte(name))
+	return level, err
+}
+
+// contextHandler adds the attributes stored in the context by With to every record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if attrs := contextAttrs(ctx); len(attrs) > 0 {
+		r = r.Clone()
+		r.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
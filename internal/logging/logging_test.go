@@ -0,0 +1,106 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/config"
+)
+
+func decodeRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var record map[string]any
+		require.NoError(t, dec.Decode(&record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestLoggerAddsContextAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, config.LoggingConfig{Format: "json"}, slog.LevelInfo)
+
+	ctx := With(context.Background(), RequestIDKey, "req-1", RouteKey, "/v1/things/:id")
+	ctx = With(ctx, UserIDKey, "user-1")
+	logger.InfoContext(ctx, "Doing things", "count", 3)
+	logger.Info("No request")
+
+	records := decodeRecords(t, &buf)
+	require.Len(t, records, 2)
+	assert.Equal(t, "Doing things", records[0]["msg"])
+	assert.Equal(t, "req-1", records[0][RequestIDKey])
+	assert.Equal(t, "/v1/things/:id", records[0][RouteKey])
+	assert.Equal(t, "user-1", records[0][UserIDKey])
+	assert.Equal(t, float64(3), records[0]["count"])
+	assert.NotContains(t, records[1], RequestIDKey)
+
+	assert.Equal(t, "req-1", RequestID(ctx))
+	assert.Equal(t, "", RequestID(context.Background()))
+}
+
+func TestWithDoesNotModifyParent(t *testing.T) {
+	parent := With(context.Background(), "a", 1)
+	first := With(parent, "b", 2)
+	second := With(parent, "c", 3)
+
+	assert.Len(t, contextAttrs(parent), 1)
+	assert.Equal(t, "b", contextAttrs(first)[1].Key)
+	assert.Equal(t, "c", contextAttrs(second)[1].Key)
+}
+
+func TestLoggerRedactsFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, config.LoggingConfig{Format: "json", RedactFields: []string{"cognito_id", "Email"}}, slog.LevelInfo)
+
+	ctx := With(context.Background(), "email", "jane@example.com")
+	logger.InfoContext(ctx, "Signing in", "cognito_id", "eu-west-1:abc", slog.Group("user", "email", "jane@example.com", "id", "u1"))
+
+	out := buf.String()
+	assert.NotContains(t, out, "jane@example.com")
+	assert.NotContains(t, out, "eu-west-1:abc")
+
+	records := decodeRecords(t, &buf)
+	require.Len(t, records, 1)
+	assert.Equal(t, Redacted, records[0]["cognito_id"])
+	assert.Equal(t, Redacted, records[0]["email"])
+	assert.Equal(t, map[string]any{"email": Redacted, "id": "u1"}, records[0]["user"])
+}
+
+func TestLoggerLevel(t *testing.T) {
+	var buf bytes.Buffer
+	var level slog.LevelVar
+	level.Set(slog.LevelWarn)
+	logger := New(&buf, config.LoggingConfig{Format: "text"}, &level)
+
+	logger.Info("dropped")
+	logger.Warn("kept")
+	level.Set(slog.LevelDebug)
+	logger.Debug("kept too")
+
+	out := buf.String()
+	assert.NotContains(t, out, "dropped")
+	assert.Contains(t, out, "msg=kept")
+	assert.Contains(t, out, `msg="kept too"`)
+}
+
+func TestParseLevel(t *testing.T) {
+	level, err := ParseLevel("warn")
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelWarn, level)
+
+	level, err = ParseLevel("DEBUG")
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelDebug, level)
+
+	_, err = ParseLevel("verbose")
+	assert.Error(t, err)
+}
@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// AWSLoadOption instruments every client created from the loaded AWS config,
+// e.g. awsconfig.LoadDefaultConfig(ctx, m.AWSLoadOption())
+func (m *Metrics) AWSLoadOption() func(*awsconfig.LoadOptions) error {
+	return awsconfig.WithAPIOptions([]func(*middleware.Stack) error{m.addAWSMiddleware})
+}
+
+// addAWSMiddleware times each operation around its retries and counts it by error code
+func (m *Metrics) addAWSMiddleware(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("ZolarisMetrics",
+		func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			start := time.Now()
+			out, metadata, err := next.HandleInitialize(ctx, in)
+
+			service, operation := awsmiddleware.GetServiceID(ctx), awsmiddleware.GetOperationName(ctx)
+			m.awsRequestTimes.WithLabelValues(service, operation).Observe(time.Since(start).Seconds())
+			m.awsRequests.WithLabelValues(service, operation, awsErrorCode(err)).Inc()
+
+			return out, metadata, err
+		}), middleware.After)
+}
+
+// awsErrorCode returns the API error code of err, such as ResourceNotFoundException
+func awsErrorCode(err error) string {
+	if err == nil {
+		return "none"
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode()
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timeout"
+	}
+	if errors.Is(err, context.Canceled) {
+		return "canceled"
+	}
+	return "other"
+}
@@ -0,0 +1,190 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/cache"
+)
+
+var (
+	poolAcquiredConns = prometheus.NewDesc(namespace+"_db_pool_acquired_connections", "Connections in use.", []string{"pool"}, nil)
+	poolIdleConns     = prometheus.NewDesc(namespace+"_db_pool_idle_connections", "Idle connections.", []string{"pool"}, nil)
+	poolTotalConns    = prometheus.NewDesc(namespace+"_db_pool_total_connections", "Open connections.", []string{"pool"}, nil)
+	poolMaxConns      = prometheus.NewDesc(namespace+"_db_pool_max_connections", "Maximum size of the pool.", []string{"pool"}, nil)
+	poolAcquires      = prometheus.NewDesc(namespace+"_db_pool_acquires_total", "Successful connection acquisitions.", []string{"pool"}, nil)
+	poolEmptyAcquires = prometheus.NewDesc(namespace+"_db_pool_empty_acquires_total", "Acquisitions that had to wait for a connection.", []string{"pool"}, nil)
+	poolAcquireTime   = prometheus.NewDesc(namespace+"_db_pool_acquire_seconds_total", "Time spent acquiring connections.", []string{"pool"}, nil)
+	poolCanceled      = prometheus.NewDesc(namespace+"_db_pool_canceled_acquires_total", "Acquisitions canceled by their context.", []string{"pool"}, nil)
+)
+
+// poolCollector reports the statistics of PostgreSQL pools, keyed by pool name
+type poolCollector struct {
+	pools map[string]*pgxpool.Pool
+}
+
+// NewPoolCollector reports the statistics of the given pools, keyed by a name
+// such as primary or replica. Nil pools are skipped.
+func NewPoolCollector(pools map[string]*pgxpool.Pool) prometheus.Collector {
+	c := &poolCollector{pools: make(map[string]*pgxpool.Pool, len(pools))}
+	for name, pool := range pools {
+		if pool != nil {
+			c.pools[name] = pool
+		}
+	}
+	return c
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{poolAcquiredConns, poolIdleConns, poolTotalConns, poolMaxConns, poolAcquires, poolEmptyAcquires, poolAcquireTime, poolCanceled} {
+		ch <- desc
+	}
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	for name, pool := range c.pools {
+		stat := pool.Stat()
+		ch <- prometheus.MustNewConstMetric(poolAcquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()), name)
+		ch <- prometheus.MustNewConstMetric(poolIdleConns, prometheus.GaugeValue, float64(stat.IdleConns()), name)
+		ch <- prometheus.MustNewConstMetric(poolTotalConns, prometheus.GaugeValue, float64(stat.TotalConns()), name)
+		ch <- prometheus.MustNewConstMetric(poolMaxConns, prometheus.GaugeValue, float64(stat.MaxConns()), name)
+		ch <- prometheus.MustNewConstMetric(poolAcquires, prometheus.CounterValue, float64(stat.AcquireCount()), name)
+		ch <- prometheus.MustNewConstMetric(poolEmptyAcquires, prometheus.CounterValue, float64(stat.EmptyAcquireCount()), name)
+		ch <- prometheus.MustNewConstMetric(poolAcquireTime, prometheus.CounterValue, stat.AcquireDuration().Seconds(), name)
+		ch <- prometheus.MustNewConstMetric(poolCanceled, prometheus.CounterValue, float64(stat.CanceledAcquireCount()), name)
+	}
+}
+
+// NewCacheCollector reports the lookup counters of the cache
+func NewCacheCollector(c *cache.Cache) prometheus.Collector {
+	return &cacheCollector{cache: c}
+}
+
+var (
+	cacheHits   = prometheus.NewDesc(namespace+"_cache_hits_total", "Cache lookups that found a value.", nil, nil)
+	cacheMisses = prometheus.NewDesc(namespace+"_cache_misses_total", "Cache lookups that found no value.", nil, nil)
+	cacheErrors = prometheus.NewDesc(namespace+"_cache_errors_total", "Failed cache operations.", nil, nil)
+)
+
+type cacheCollector struct {
+	cache *cache.Cache
+}
+
+func (c *cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- cacheHits
+	ch <- cacheMisses
+	ch <- cacheErrors
+}
+
+func (c *cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.cache.Stats()
+	ch <- prometheus.MustNewConstMetric(cacheHits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(cacheMisses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(cacheErrors, prometheus.CounterValue, float64(stats.Errors))
+}
+
+// ReplicaStatus reports the state of the read replica. It is implemented by db.ReplicaRouter.
+type ReplicaStatus interface {
+	UsingReplica() bool
+	Lag() time.Duration
+}
+
+// NewReplicaCollectors report whether reads go to the replica and its measured lag
+func NewReplicaCollectors(replica ReplicaStatus) []prometheus.Collector {
+	return []prometheus.Collector{
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "db_replica_in_use",
+			Help:      "1 if read-only queries are routed to the replica, 0 otherwise.",
+		}, func() float64 {
+			if replica.UsingReplica() {
+				return 1
+			}
+			return 0
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "db_replica_lag_seconds",
+			Help:      "Replication lag measured by the last check.",
+		}, func() float64 { return replica.Lag().Seconds() }),
+	}
+}
+
+// BusinessStats counts the registered devices and entities. It is implemented
+// by repositories.StatsRepository.
+type BusinessStats interface {
+	CountDevices(ctx context.Context) (int64, error)
+	CountEntitiesByCategoryType(ctx context.Context) (map[string]int64, error)
+}
+
+var (
+	devicesDesc  = prometheus.NewDesc(namespace+"_devices", "Registered devices.", nil, nil)
+	entitiesDesc = prometheus.NewDesc(namespace+"_entities", "Entities by category type.", []string{"category_type"}, nil)
+)
+
+// businessQueryTimeout bounds the counting queries run during a scrape
+const businessQueryTimeout = 5 * time.Second
+
+// businessCollector reports the business gauges. The counts are queried at most
+// once per ttl, so frequent scrapes do not load the database.
+type businessCollector struct {
+	stats BusinessStats
+	ttl   time.Duration
+
+	mu        sync.Mutex
+	updatedAt time.Time
+	devices   int64
+	entities  map[string]int64
+}
+
+// NewBusinessCollector reports the counts of stats, refreshed at most once per ttl
+func NewBusinessCollector(stats BusinessStats, ttl time.Duration) prometheus.Collector {
+	return &businessCollector{stats: stats, ttl: ttl}
+}
+
+func (c *businessCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- devicesDesc
+	ch <- entitiesDesc
+}
+
+func (c *businessCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.updatedAt.IsZero() || time.Since(c.updatedAt) >= c.ttl {
+		c.refresh()
+	}
+	if c.updatedAt.IsZero() {
+		// Never counted successfully; report nothing rather than zeros
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(devicesDesc, prometheus.GaugeValue, float64(c.devices))
+	for categoryType, count := range c.entities {
+		ch <- prometheus.MustNewConstMetric(entitiesDesc, prometheus.GaugeValue, float64(count), categoryType)
+	}
+}
+
+// refresh queries the counts; on failure the previous counts are kept
+func (c *businessCollector) refresh() {
+	ctx, cancel := context.WithTimeout(context.Background(), businessQueryTimeout)
+	defer cancel()
+
+	devices, err := c.stats.CountDevices(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to count devices for metrics", "error", err)
+		return
+	}
+	entities, err := c.stats.CountEntitiesByCategoryType(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to count entities for metrics", "error", err)
+		return
+	}
+
+	c.devices, c.entities, c.updatedAt = devices, entities, time.Now()
+}
@@ -0,0 +1,113 @@
+// Package metrics exposes Prometheus metrics of the HTTP API, the repositories,
+// the PostgreSQL pools, the AWS calls and a few business gauges.
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+)
+
+const namespace = "zolaris"
+
+// Metrics holds the collectors of the application in its own registry
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests    *prometheus.HistogramVec
+	httpInFlight    prometheus.Gauge
+	repositoryCalls *prometheus.HistogramVec
+	awsRequests     *prometheus.CounterVec
+	awsRequestTimes *prometheus.HistogramVec
+}
+
+// New creates the metrics, including the Go runtime and process collectors
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Duration of HTTP requests by route and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		httpInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "http_requests_in_flight",
+			Help:      "Number of HTTP requests being served.",
+		}),
+		repositoryCalls: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "repository_call_duration_seconds",
+			Help:      "Duration of repository calls by method and outcome (ok, not_found or error).",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+		}, []string{"repository", "method", "outcome"}),
+		awsRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "aws_requests_total",
+			Help:      "AWS API calls by service, operation and error code (none on success).",
+		}, []string{"service", "operation", "error"}),
+		awsRequestTimes: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "aws_request_duration_seconds",
+			Help:      "Duration of AWS API calls, including retries, by service and operation.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"service", "operation"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpInFlight,
+		m.repositoryCalls,
+		m.awsRequests,
+		m.awsRequestTimes,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Register adds further collectors to the registry
+func (m *Metrics) Register(cs ...prometheus.Collector) {
+	m.registry.MustRegister(cs...)
+}
+
+// StartHTTPRequest counts a request in flight until the returned function is
+// called with its route and status code
+func (m *Metrics) StartHTTPRequest() func(method, route string, status int) {
+	start := time.Now()
+	m.httpInFlight.Inc()
+	return func(method, route string, status int) {
+		m.httpInFlight.Dec()
+		m.httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Observe(time.Since(start).Seconds())
+	}
+}
+
+// ObserveRepositoryCall records a repository call; it implements repositories.CallObserver
+func (m *Metrics) ObserveRepositoryCall(repository, method string, duration time.Duration, err error) {
+	m.repositoryCalls.WithLabelValues(repository, method, outcome(err)).Observe(duration.Seconds())
+}
+
+// outcome classifies the error of a call. A missing row is an answer, not a failure.
+func outcome(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, domain.ErrNotFound):
+		return "not_found"
+	default:
+		return "error"
+	}
+}
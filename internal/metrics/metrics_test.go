@@ -0,0 +1,139 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/cache"
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+)
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	body, err := io.ReadAll(w.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestObserveRepositoryCall(t *testing.T) {
+	m := New()
+	m.ObserveRepositoryCall("entity", "GetSubtree", 3*time.Millisecond, nil)
+	m.ObserveRepositoryCall("entity", "GetSubtree", 3*time.Millisecond, fmt.Errorf("wrapped: %w", domain.NewNotFoundError("missing")))
+	m.ObserveRepositoryCall("entity", "GetSubtree", 3*time.Millisecond, errors.New("connection reset"))
+
+	assert.Equal(t, 3, testutil.CollectAndCount(m.repositoryCalls))
+	out := scrape(t, m)
+	for _, outcome := range []string{"ok", "not_found", "error"} {
+		assert.Contains(t, out, fmt.Sprintf(`zolaris_repository_call_duration_seconds_count{method="GetSubtree",outcome=%q,repository="entity"} 1`, outcome))
+	}
+}
+
+func TestStartHTTPRequest(t *testing.T) {
+	m := New()
+
+	done := m.StartHTTPRequest()
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.httpInFlight))
+	done(http.MethodGet, "/entity/:id", http.StatusOK)
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.httpInFlight))
+
+	assert.Contains(t, scrape(t, m), `zolaris_http_request_duration_seconds_count{method="GET",route="/entity/:id",status="200"} 1`)
+}
+
+func TestAWSErrorCode(t *testing.T) {
+	assert.Equal(t, "none", awsErrorCode(nil))
+	assert.Equal(t, "ResourceNotFoundException", awsErrorCode(fmt.Errorf("operation error: %w", &smithy.GenericAPIError{Code: "ResourceNotFoundException"})))
+	assert.Equal(t, "timeout", awsErrorCode(context.DeadlineExceeded))
+	assert.Equal(t, "canceled", awsErrorCode(context.Canceled))
+	assert.Equal(t, "other", awsErrorCode(errors.New("dial tcp: connection refused")))
+}
+
+func TestCacheCollector(t *testing.T) {
+	c := cache.New(cache.NewLRU(10))
+	c.Set(context.Background(), "k", "v", time.Minute)
+	var v string
+	c.Get(context.Background(), "k", &v)
+	c.Get(context.Background(), "missing", &v)
+
+	expected := `
+# HELP zolaris_cache_hits_total Cache lookups that found a value.
+# TYPE zolaris_cache_hits_total counter
+zolaris_cache_hits_total 1
+# HELP zolaris_cache_misses_total Cache lookups that found no value.
+# TYPE zolaris_cache_misses_total counter
+zolaris_cache_misses_total 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(NewCacheCollector(c), strings.NewReader(expected), "zolaris_cache_hits_total", "zolaris_cache_misses_total"))
+}
+
+type fakeStats struct {
+	calls int
+	err   error
+}
+
+func (s *fakeStats) CountDevices(ctx context.Context) (int64, error) {
+	s.calls++
+	return 7, s.err
+}
+
+func (s *fakeStats) CountEntitiesByCategoryType(ctx context.Context) (map[string]int64, error) {
+	return map[string]int64{"user": 3, "office": 2}, nil
+}
+
+func TestBusinessCollector(t *testing.T) {
+	stats := &fakeStats{}
+	collector := NewBusinessCollector(stats, time.Hour)
+
+	expected := `
+# HELP zolaris_devices Registered devices.
+# TYPE zolaris_devices gauge
+zolaris_devices 7
+# HELP zolaris_entities Entities by category type.
+# TYPE zolaris_entities gauge
+zolaris_entities{category_type="office"} 2
+zolaris_entities{category_type="user"} 3
+`
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+
+	// Counts are reused within the TTL, and kept when a refresh fails
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+	assert.Equal(t, 1, stats.calls)
+
+	collector.(*businessCollector).updatedAt = time.Now().Add(-2 * time.Hour)
+	stats.err = errors.New("database down")
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+	assert.Equal(t, 2, stats.calls)
+}
+
+func TestBusinessCollectorReportsNothingUntilCounted(t *testing.T) {
+	collector := NewBusinessCollector(&fakeStats{err: errors.New("database down")}, time.Hour)
+	assert.Equal(t, 0, testutil.CollectAndCount(collector))
+}
+
+type fakeReplica struct{}
+
+func (fakeReplica) UsingReplica() bool { return true }
+func (fakeReplica) Lag() time.Duration { return 1500 * time.Millisecond }
+
+func TestReplicaCollectors(t *testing.T) {
+	m := New()
+	m.Register(NewReplicaCollectors(fakeReplica{})...)
+
+	out := scrape(t, m)
+	assert.Contains(t, out, "zolaris_db_replica_in_use 1")
+	assert.Contains(t, out, "zolaris_db_replica_lag_seconds 1.5")
+}
@@ -3,9 +3,10 @@ package middleware
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
 
+	"github.com/afreedicp/zolaris-backend-app/internal/logging"
 	"github.com/afreedicp/zolaris-backend-app/internal/transport/dto"
 )
 
@@ -39,11 +40,10 @@ func AuthMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		// Log authentication
-		log.Printf("Authenticated request for user: %s", userID)
-
-		// Add user ID to request context
+		// Add user ID to request context, and to the log records of the request
 		ctx := context.WithValue(r.Context(), UserIDKey, userID)
+		ctx = logging.With(ctx, logging.UserIDKey, userID)
+		slog.DebugContext(ctx, "Authenticated request")
 
 		// Call the next handler with the updated context
 		next.ServeHTTP(w, r.WithContext(ctx))
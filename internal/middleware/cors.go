@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"net/url"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/config"
+)
+
+// CORS applies the configured cross-origin policy. The policy can be replaced
+// while the server runs, e.g. when the configuration is reloaded.
+type CORS struct {
+	handler atomic.Pointer[gin.HandlerFunc]
+}
+
+// NewCORS creates the middleware with the given policy
+func NewCORS(cfg config.CORSConfig) *CORS {
+	c := &CORS{}
+	c.Update(cfg)
+	return c
+}
+
+// Update replaces the policy; requests already in flight finish with the old one
+func (c *CORS) Update(cfg config.CORSConfig) {
+	handler := cors.New(corsConfig(cfg))
+	c.handler.Store(&handler)
+}
+
+// Handler returns the Gin middleware
+func (c *CORS) Handler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		(*c.handler.Load())(ctx)
+	}
+}
+
+// corsConfig translates the policy into the configuration of the cors middleware.
+// Origins are matched here rather than by the middleware, so wildcard patterns only
+// ever match whole subdomain labels.
+func corsConfig(cfg config.CORSConfig) cors.Config {
+	matcher := newOriginMatcher(cfg.AllowedOrigins, cfg.AllowLocalhost)
+
+	return cors.Config{
+		AllowOriginFunc:  matcher.allowed,
+		AllowMethods:     cfg.AllowedMethods,
+		AllowHeaders:     cfg.AllowedHeaders,
+		ExposeHeaders:    cfg.ExposedHeaders,
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           cfg.MaxAge,
+	}
+}
+
+// originMatcher decides whether a request origin is allowed
+type originMatcher struct {
+	any            bool
+	exact          map[string]bool
+	wildcards      []wildcardOrigin
+	allowLocalhost bool
+}
+
+// wildcardOrigin matches any subdomain of suffix, e.g. https://*.example.com
+type wildcardOrigin struct {
+	scheme string
+	suffix string // ".example.com"
+	port   string
+}
+
+// newOriginMatcher builds a matcher from origins validated by config.Validate
+func newOriginMatcher(origins []string, allowLocalhost bool) *originMatcher {
+	m := &originMatcher{
+		exact:          make(map[string]bool, len(origins)),
+		allowLocalhost: allowLocalhost,
+	}
+
+	for _, origin := range origins {
+		if origin == "*" {
+			m.any = true
+			continue
+		}
+
+		u, err := url.Parse(strings.ToLower(origin))
+		if err != nil {
+			continue
+		}
+		if host, ok := strings.CutPrefix(u.Hostname(), "*"); ok {
+			m.wildcards = append(m.wildcards, wildcardOrigin{scheme: u.Scheme, suffix: host, port: u.Port()})
+			continue
+		}
+		m.exact[u.Scheme+"://"+u.Host] = true
+	}
+
+	return m
+}
+
+// allowed reports whether a request from origin may read the response
+func (m *originMatcher) allowed(origin string) bool {
+	if m.any {
+		return true
+	}
+
+	u, err := url.Parse(strings.ToLower(origin))
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if m.exact[u.Scheme+"://"+u.Host] {
+		return true
+	}
+
+	host := u.Hostname()
+	if m.allowLocalhost && u.Scheme == "http" && (host == "localhost" || host == "127.0.0.1") {
+		return true
+	}
+
+	for _, w := range m.wildcards {
+		// The wildcard stands for at least one label, so https://*.example.com does not match https://example.com
+		if u.Scheme == w.scheme && u.Port() == w.port && len(host) > len(w.suffix) && strings.HasSuffix(host, w.suffix) {
+			return true
+		}
+	}
+
+	return false
+}
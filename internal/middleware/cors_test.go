@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/config"
+)
+
+func TestOriginMatcher(t *testing.T) {
+	m := newOriginMatcher([]string{"https://app.example.com", "https://*.preview.example.com", "http://10.0.0.1"}, false)
+
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{"https://app.example.com", true},
+		{"https://APP.example.com", true},
+		{"http://app.example.com", false},
+		{"https://app.example.com:8443", false},
+		{"https://pr-12.preview.example.com", true},
+		{"https://a.b.preview.example.com", true},
+		{"https://preview.example.com", false},
+		{"https://evilpreview.example.com", false},
+		{"http://pr-12.preview.example.com", false},
+		{"http://10.0.0.1", true},
+		{"http://localhost:3000", false},
+		{"null", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.allowed, m.allowed(tt.origin), tt.origin)
+	}
+}
+
+func TestOriginMatcherLocalhost(t *testing.T) {
+	m := newOriginMatcher(nil, true)
+
+	assert.True(t, m.allowed("http://localhost"))
+	assert.True(t, m.allowed("http://localhost:3000"))
+	assert.True(t, m.allowed("http://127.0.0.1:5173"))
+	assert.False(t, m.allowed("http://localhost.evil.com"))
+	assert.False(t, m.allowed("http://localhostevil.com"))
+	assert.False(t, m.allowed("https://example.com"))
+
+	assert.False(t, newOriginMatcher(nil, false).allowed("http://localhost:3000"))
+}
+
+func TestOriginMatcherAny(t *testing.T) {
+	m := newOriginMatcher([]string{"*"}, false)
+	assert.True(t, m.allowed("https://anything.example.org"))
+}
+
+func TestCORSUpdate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	policy := NewCORS(config.CORSConfig{
+		AllowedOrigins:   []string{"https://old.example.com"},
+		AllowedMethods:   []string{"GET", "PATCH"},
+		AllowedHeaders:   []string{"Authorization"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	})
+
+	r := gin.New()
+	r.Use(policy.Handler())
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	preflight := func(origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodOptions, "/", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", "PATCH")
+		req.Header.Set("Access-Control-Request-Headers", "Authorization")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := preflight("https://old.example.com")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://old.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Methods"), "PATCH")
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "Authorization")
+
+	policy.Update(config.CORSConfig{
+		AllowedOrigins: []string{"https://new.example.com"},
+		AllowedMethods: []string{"GET", "PATCH"},
+		AllowedHeaders: []string{"Authorization"},
+	})
+
+	assert.Equal(t, http.StatusForbidden, preflight("https://old.example.com").Code)
+	assert.Equal(t, "https://new.example.com", preflight("https://new.example.com").Header().Get("Access-Control-Allow-Origin"))
+}
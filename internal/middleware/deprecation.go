@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GinDeprecationMiddleware marks the responses of deprecated routes with the
+// Deprecation header (RFC 9745), the Sunset header (RFC 8594) unless sunset is
+// zero, and a Link to the same path under successorPrefix, e.g. "/v1"
+func GinDeprecationMiddleware(deprecated, sunset time.Time, successorPrefix string) gin.HandlerFunc {
+	deprecation := fmt.Sprintf("@%d", deprecated.Unix())
+	sunsetDate := ""
+	if !sunset.IsZero() {
+		sunsetDate = sunset.UTC().Format(http.TimeFormat)
+	}
+
+	return func(c *gin.Context) {
+		c.Header("Deprecation", deprecation)
+		if sunsetDate != "" {
+			c.Header("Sunset", sunsetDate)
+		}
+		c.Header("Link", fmt.Sprintf(`<%s%s>; rel="successor-version"`, successorPrefix, c.Request.URL.Path))
+		c.Next()
+	}
+}
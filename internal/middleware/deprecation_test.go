@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGinDeprecationMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	deprecated := time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+	serve := func(sunset time.Time) *httptest.ResponseRecorder {
+		r := gin.New()
+		r.Use(GinDeprecationMiddleware(deprecated, sunset, "/v1"))
+		r.GET("/entity/:entity_id/children", func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/entity/42/children?recursive=true", nil))
+		return w
+	}
+
+	w := serve(time.Date(2027, time.April, 30, 0, 0, 0, 0, time.FixedZone("CEST", 2*3600)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "@1792281600", w.Header().Get("Deprecation"))
+	assert.Equal(t, "Thu, 29 Apr 2027 22:00:00 GMT", w.Header().Get("Sunset"))
+	assert.Equal(t, `</v1/entity/42/children>; rel="successor-version"`, w.Header().Get("Link"))
+
+	w = serve(time.Time{})
+	assert.NotEmpty(t, w.Header().Get("Deprecation"))
+	assert.NotContains(t, w.Header(), "Sunset")
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+	"github.com/afreedicp/zolaris-backend-app/internal/transport/response"
+)
+
+// GinErrorMiddleware writes the error response for the last error a handler
+// attached with c.Error, mapping the domain error kinds to status codes
+func GinErrorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		writeErrorResponse(c)
+	}
+}
+
+// writeErrorResponse writes the response for the last error of c, unless a
+// response was written already
+func writeErrorResponse(c *gin.Context) {
+	if len(c.Errors) == 0 || c.Writer.Written() {
+		return
+	}
+
+	err := c.Errors.Last().Err
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		response.NotFound(c, domain.ErrorMessage(err, "Resource not found"))
+	case errors.Is(err, domain.ErrConflict):
+		response.Conflict(c, domain.ErrorMessage(err, "Resource already exists"))
+	case errors.Is(err, domain.ErrForbidden):
+		response.Forbidden(c, domain.ErrorMessage(err, "Access denied"))
+	case errors.Is(err, domain.ErrValidation):
+		response.Error(c, http.StatusBadRequest, domain.ErrorMessage(err, "Validation failed"), "VALIDATION_ERROR")
+	case errors.Is(err, domain.ErrUnavailable):
+		slog.ErrorContext(c.Request.Context(), "Dependency unavailable", "error", err)
+		response.ServiceUnavailable(c, "Service temporarily unavailable")
+	default:
+		slog.ErrorContext(c.Request.Context(), "Unhandled error", "error", err)
+		response.InternalError(c, "Internal server error")
+	}
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+	"github.com/afreedicp/zolaris-backend-app/internal/transport/dto"
+)
+
+func TestGinErrorMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		code    string
+		message string
+	}{
+		{"not found", domain.NewNotFoundError("entity with ID %s not found", "e1"), http.StatusNotFound, "NOT_FOUND", "entity with ID e1 not found"},
+		{"wrapped conflict", fmt.Errorf("failed to add: %w", domain.NewConflictError("already exists")), http.StatusConflict, "CONFLICT", "already exists"},
+		{"forbidden", domain.NewForbiddenError("entity is not accessible"), http.StatusForbidden, "FORBIDDEN", "entity is not accessible"},
+		{"validation", domain.NewValidationError("invalid cursor"), http.StatusBadRequest, "VALIDATION_ERROR", "invalid cursor"},
+		{"unavailable", domain.NewUnavailableError(errors.New("timeout"), "failed to query"), http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "Service temporarily unavailable"},
+		{"untyped", errors.New("connection reset"), http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(GinErrorMiddleware())
+			r.GET("/", func(c *gin.Context) { c.Error(tt.err) })
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			var body dto.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.code, body.Code)
+			assert.Equal(t, tt.message, body.Error)
+		})
+	}
+}
+
+func TestGinErrorMiddlewareKeepsWrittenResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(GinErrorMiddleware())
+	r.GET("/", func(c *gin.Context) {
+		c.Error(errors.New("logged only"))
+		c.String(http.StatusOK, "OK")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "OK", w.Body.String())
+}
@@ -1,23 +1,43 @@
 package middleware
 
 import (
-	"log"
+	"log/slog"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 
+	"github.com/afreedicp/zolaris-backend-app/internal/logging"
 	"github.com/afreedicp/zolaris-backend-app/internal/services"
+	"github.com/afreedicp/zolaris-backend-app/internal/transport/response"
+	"github.com/afreedicp/zolaris-backend-app/internal/utils"
 )
 
-// GinAuthMiddleware checks for user authentication
-// This is a simplified version - in a real app, use JWT or OAuth2
-func GinAuthMiddleware(userService *services.UserService) gin.HandlerFunc {
+// RequestIDHeader carries the ID correlating the log records of a request
+const RequestIDHeader = "X-Request-ID"
+
+// GinAuthMiddleware checks for user authentication. With a verifier, the
+// Cognito ID is the subject of the JWT in the Authorization header; without
+// one, it is taken as is from the X-Cognito-ID header.
+func GinAuthMiddleware(userService *services.UserService, verifier *utils.JWTVerifier) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		cogntioId := c.GetHeader("X-Cognito-ID")
+		if verifier != nil {
+			var err error
+			tokenString, _ := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+			if cogntioId, err = verifier.GetUserId(tokenString); err != nil {
+				slog.InfoContext(c.Request.Context(), "Rejected invalid token", "error", err)
+				c.JSON(401, gin.H{"status": false, "message": "Unauthorized: Invalid or missing bearer token."})
+				c.Abort()
+				return
+			}
+		}
 
 		userID, err := userService.GetUserIdByCognitoId(c.Request.Context(), cogntioId)
 		if err != nil {
-			log.Printf("Error retrieving user ID by Cognito ID: %v", err)
+			slog.ErrorContext(c.Request.Context(), "Error retrieving user ID by Cognito ID", "error", err)
 			c.JSON(500, gin.H{"status": false, "message": "Internal server error"})
 			c.Abort()
 			return
@@ -30,39 +50,93 @@ func GinAuthMiddleware(userService *services.UserService) gin.HandlerFunc {
 			return
 		}
 
-		// Log authentication
-		log.Printf("Authenticated request for user: %s", userID)
-
-		// Add user ID to request context
+		// Add user ID to request context, and to the log records of the request
 		c.Set(string(UserIDKey), userID)
+		c.Request = c.Request.WithContext(logging.With(c.Request.Context(), logging.UserIDKey, userID))
+		slog.DebugContext(c.Request.Context(), "Authenticated request")
 
 		c.Next()
 	}
 }
 
-// GinLoggerMiddleware logs request details
+// GinAdminMiddleware lets only the users in adminUserIDs through. It must run
+// after GinAuthMiddleware.
+func GinAdminMiddleware(adminUserIDs []string) gin.HandlerFunc {
+	admins := make(map[string]bool, len(adminUserIDs))
+	for _, id := range adminUserIDs {
+		admins[strings.ToLower(id)] = true
+	}
+
+	return func(c *gin.Context) {
+		if !admins[strings.ToLower(GetUserIDFromGin(c))] {
+			slog.WarnContext(c.Request.Context(), "Rejected non-admin request")
+			response.Forbidden(c, "Admin access required")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// GinLoggerMiddleware logs every request. It takes the request ID from the
+// X-Request-ID header, or generates one, echoes it in the response and adds it
+// and the route to the context, so every record logged for the request carries
+// them. The client IP is added too, for the audit log.
 func GinLoggerMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Start timer
 		startTime := time.Now()
 
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(logging.With(c.Request.Context(),
+			logging.RequestIDKey, requestID,
+			logging.RouteKey, c.FullPath(),
+		))
+		c.Request = c.Request.WithContext(services.WithClientIP(c.Request.Context(), c.ClientIP()))
+
 		// Process request
 		c.Next()
 
-		// Calculate latency
-		latency := time.Since(startTime)
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
 
-		// Log request details
-		log.Printf("%s | %s | %d | %s | %s",
-			c.Request.Method,
-			c.Request.URL.Path,
-			c.Writer.Status(),
-			latency,
-			c.ClientIP(),
+		// Handlers further down may have added the user ID to the request context
+		slog.Log(c.Request.Context(), level, "Request",
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"status", status,
+			"latency_ms", time.Since(startTime).Milliseconds(),
+			"client_ip", c.ClientIP(),
+			"size", c.Writer.Size(),
 		)
 	}
 }
 
+// validRequestID accepts client supplied request IDs that are safe to log and echo
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 // GetUserIDFromGin extracts the user ID from the Gin context
 func GetUserIDFromGin(c *gin.Context) string {
 	userID, exists := c.Get(string(UserIDKey))
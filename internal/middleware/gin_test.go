@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/cache"
+	"github.com/afreedicp/zolaris-backend-app/internal/config"
+	"github.com/afreedicp/zolaris-backend-app/internal/logging"
+	"github.com/afreedicp/zolaris-backend-app/internal/repositories"
+	"github.com/afreedicp/zolaris-backend-app/internal/services"
+	"github.com/afreedicp/zolaris-backend-app/internal/utils"
+)
+
+func TestGinLoggerMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(logging.New(&buf, config.LoggingConfig{Format: "json"}, slog.LevelInfo))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	var handlerRequestID string
+	r := gin.New()
+	r.Use(GinLoggerMiddleware())
+	r.GET("/things/:id", func(c *gin.Context) {
+		// Stands in for the auth middleware
+		c.Request = c.Request.WithContext(logging.With(c.Request.Context(), logging.UserIDKey, "user-1"))
+		handlerRequestID = logging.RequestID(c.Request.Context())
+		c.Status(http.StatusNotFound)
+	})
+
+	serve := func(requestID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/things/42", nil)
+		if requestID != "" {
+			req.Header.Set(RequestIDHeader, requestID)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("KeepsClientRequestID", func(t *testing.T) {
+		buf.Reset()
+		w := serve("abc-123")
+
+		assert.Equal(t, "abc-123", w.Header().Get(RequestIDHeader))
+		assert.Equal(t, "abc-123", handlerRequestID)
+
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		assert.Equal(t, "WARN", record["level"])
+		assert.Equal(t, "abc-123", record[logging.RequestIDKey])
+		assert.Equal(t, "/things/:id", record[logging.RouteKey])
+		assert.Equal(t, "user-1", record[logging.UserIDKey])
+		assert.Equal(t, "/things/42", record["path"])
+		assert.Equal(t, float64(http.StatusNotFound), record["status"])
+	})
+
+	t.Run("GeneratesMissingOrInvalidRequestID", func(t *testing.T) {
+		for _, requestID := range []string{"", "bad id\n", string(make([]byte, 200))} {
+			w := serve(requestID)
+			generated := w.Header().Get(RequestIDHeader)
+			assert.Len(t, generated, 36)
+			assert.Equal(t, generated, handlerRequestID)
+		}
+	})
+}
+
+func TestGinAdminMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(string(UserIDKey), c.GetHeader("X-Test-User"))
+	})
+	r.Use(GinAdminMiddleware([]string{"6F1C2A9E-4B7D-4C1A-9D2E-3F5A6B7C8D9E"}))
+	r.GET("/admin/audit", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for user, status := range map[string]int{
+		"6f1c2a9e-4b7d-4c1a-9d2e-3f5a6b7c8d9e": http.StatusOK,
+		"0b8e5c1d-2f3a-4b6c-8d9e-1a2b3c4d5e6f": http.StatusForbidden,
+		"":                                     http.StatusForbidden,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/admin/audit", nil)
+		req.Header.Set("X-Test-User", user)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code, user)
+	}
+}
+
+// cognitoUsers resolves the Cognito IDs it holds and nothing else
+type cognitoUsers struct {
+	repositories.UserRepositoryInterface
+	ids map[string]string
+}
+
+func (r cognitoUsers) GetUserIdByCognitoId(ctx context.Context, cognitoID string) (string, error) {
+	return r.ids[cognitoID], nil
+}
+
+func TestGinAuthMiddlewareVerifiesTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	verifier, err := utils.NewJWTVerifier(string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})))
+	require.NoError(t, err)
+
+	userService := services.NewUserService(cognitoUsers{ids: map[string]string{"cognito-1": "user-1"}}, cache.New(cache.NewNoop()), nil)
+	r := gin.New()
+	r.Use(GinAuthMiddleware(userService, verifier))
+	r.GET("/user/details", func(c *gin.Context) {
+		c.String(http.StatusOK, GetUserIDFromGin(c))
+	})
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"sub": "cognito-1",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString(key)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/user/details", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "user-1", w.Body.String())
+
+	// The X-Cognito-ID header is not trusted once tokens are verified
+	req = httptest.NewRequest(http.MethodGet, "/user/details", nil)
+	req.Header.Set("X-Cognito-ID", "cognito-1")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
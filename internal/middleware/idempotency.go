@@ -0,0 +1,196 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/config"
+	"github.com/afreedicp/zolaris-backend-app/internal/idempotency"
+	"github.com/afreedicp/zolaris-backend-app/internal/transport/response"
+)
+
+// IdempotencyKeyHeader lets clients retry a POST request without running it twice
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// ReplayedHeader marks a response replayed for a retried request
+const ReplayedHeader = "Idempotent-Replayed"
+
+// maxIdempotencyKeyLength bounds the keys clients may send
+const maxIdempotencyKeyLength = 255
+
+// maxBufferedBodyBytes bounds the request bodies read into memory by the
+// middleware, which must allow the largest body a handler accepts, the 10 MiB
+// of an entity import
+const maxBufferedBodyBytes = 10 << 20
+
+// idempotencyPollInterval is how often a retry checks whether the request
+// holding its key has finished
+const idempotencyPollInterval = 50 * time.Millisecond
+
+// responseRecorder copies the body written by the handlers, to be stored
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// GinIdempotencyMiddleware stores the response of each POST request sent with
+// an Idempotency-Key, keyed by the user and the key, and replays it for
+// retries with the same method, URI and body. Reusing a key for a different
+// request gets 422, and a retry arriving while the request is still running
+// waits for it. Server errors are not stored, so those requests can be
+// retried. It must run after GinAuthMiddleware; a nil store disables it, and
+// if the store fails, requests are run as if they had no key.
+func GinIdempotencyMiddleware(store idempotency.Store, cfg config.IdempotencyConfig) gin.HandlerFunc {
+	if store == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if c.Request.Method != http.MethodPost || key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			response.BadRequest(c, fmt.Sprintf("%s must be at most %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLength))
+			c.Abort()
+			return
+		}
+
+		ctx := c.Request.Context()
+		body, ok := bufferBody(c)
+		if !ok {
+			return
+		}
+
+		fingerprint := idempotency.Fingerprint(c.Request.Method, c.Request.URL.RequestURI(), body)
+		// Keys are only unique per user, and only a hash of them is kept
+		storeKey := GetUserIDFromGin(c) + ":" + hashKey(key)
+
+		stored, token, err := acquireIdempotencyKey(ctx, store, storeKey, cfg)
+		switch {
+		case err != nil:
+			slog.WarnContext(ctx, "Idempotency store failed, running the request", "error", err)
+			c.Next()
+			return
+		case stored != nil:
+			replayResponse(c, stored, fingerprint)
+			return
+		case token == "":
+			response.Error(c, http.StatusConflict, "A request with this Idempotency-Key is still in progress", "IDEMPOTENCY_KEY_IN_USE")
+			c.Abort()
+			return
+		}
+		defer func() {
+			if err := store.Unlock(context.WithoutCancel(ctx), storeKey, token); err != nil {
+				slog.WarnContext(ctx, "Failed to unlock idempotency key", "error", err)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+		// Write the error response here rather than in GinErrorMiddleware, so it is stored too
+		writeErrorResponse(c)
+
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+		stored = &idempotency.Response{
+			Fingerprint: fingerprint,
+			Status:      status,
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		}
+		if err := store.Put(context.WithoutCancel(ctx), storeKey, stored, cfg.TTL); err != nil {
+			slog.WarnContext(ctx, "Failed to store idempotent response", "error", err)
+		}
+	}
+}
+
+// bufferBody reads the request body, up to maxBufferedBodyBytes, and puts it
+// back for the handlers. If it cannot be read, it writes an error response,
+// 413 for an oversized body, aborts and returns false.
+func bufferBody(c *gin.Context) ([]byte, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBufferedBodyBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.PayloadTooLarge(c, fmt.Sprintf("Request body must be at most %d bytes", tooLarge.Limit))
+		} else {
+			response.BadRequest(c, "Failed to read request body")
+		}
+		c.Abort()
+		return nil, false
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	return body, true
+}
+
+// acquireIdempotencyKey locks key, waiting up to cfg.WaitTimeout while another
+// request holds it. It returns the stored response if that request has
+// finished, the token of the lock if this request may run, or neither if the
+// wait timed out.
+func acquireIdempotencyKey(ctx context.Context, store idempotency.Store, key string, cfg config.IdempotencyConfig) (*idempotency.Response, string, error) {
+	deadline := time.Now().Add(cfg.WaitTimeout)
+	for {
+		token, err := store.Lock(ctx, key, cfg.LockTTL)
+		if err != nil {
+			return nil, "", err
+		}
+
+		// Responses are stored before their lock is released, so a request
+		// holding the lock sees the response of any earlier one
+		stored, err := store.Get(ctx, key)
+		if token != "" && (err != nil || stored != nil) {
+			if err := store.Unlock(ctx, key, token); err != nil {
+				slog.WarnContext(ctx, "Failed to unlock idempotency key", "error", err)
+			}
+			token = ""
+		}
+		if err != nil || stored != nil || token != "" {
+			return stored, token, err
+		}
+
+		if time.Now().After(deadline) {
+			return nil, "", nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, "", nil
+		case <-time.After(idempotencyPollInterval):
+		}
+	}
+}
+
+// replayResponse writes the stored response, unless the key was used for a different request
+func replayResponse(c *gin.Context, stored *idempotency.Response, fingerprint string) {
+	if stored.Fingerprint != fingerprint {
+		response.Error(c, http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different request", "IDEMPOTENCY_KEY_REUSED")
+		c.Abort()
+		return
+	}
+
+	c.Header(ReplayedHeader, "true")
+	c.Data(stored.Status, stored.ContentType, stored.Body)
+	c.Abort()
+}
@@ -0,0 +1,167 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/config"
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+	"github.com/afreedicp/zolaris-backend-app/internal/idempotency"
+)
+
+var testIdempotencyConfig = config.IdempotencyConfig{TTL: time.Hour, LockTTL: time.Minute, WaitTimeout: time.Second}
+
+// newIdempotentRouter serves POST /entity/sub with handler, which by default
+// creates a new entity on every run. It returns the number of runs.
+func newIdempotentRouter(store idempotency.Store, cfg config.IdempotencyConfig, handler gin.HandlerFunc) (*gin.Engine, *atomic.Int32) {
+	gin.SetMode(gin.TestMode)
+	var runs atomic.Int32
+	r := gin.New()
+	r.Use(GinErrorMiddleware())
+	r.Use(func(c *gin.Context) {
+		c.Set(string(UserIDKey), c.GetHeader("X-Test-User"))
+	})
+	r.Use(GinIdempotencyMiddleware(store, cfg))
+	r.POST("/entity/sub", func(c *gin.Context) {
+		id := runs.Add(1)
+		if handler != nil {
+			handler(c)
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"id": strconv.Itoa(int(id))})
+	})
+	return r, &runs
+}
+
+func post(r *gin.Engine, user, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/entity/sub", strings.NewReader(body))
+	req.Header.Set("X-Test-User", user)
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestGinIdempotencyMiddleware(t *testing.T) {
+	r, runs := newIdempotentRouter(idempotency.NewMemoryStore(), testIdempotencyConfig, nil)
+
+	first := post(r, "user-1", "retry-1", `{"name":"Hall"}`)
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Empty(t, first.Header().Get(ReplayedHeader))
+
+	// A retry gets the same response without running the handler
+	retry := post(r, "user-1", "retry-1", `{"name":"Hall"}`)
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, "application/json; charset=utf-8", retry.Header().Get("Content-Type"))
+	assert.Equal(t, "true", retry.Header().Get(ReplayedHeader))
+	assert.Equal(t, int32(1), runs.Load())
+
+	// Reusing the key for another request is rejected
+	w := post(r, "user-1", "retry-1", `{"name":"Lab"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "IDEMPOTENCY_KEY_REUSED")
+
+	// Keys are scoped to the user, and requests without a key always run
+	assert.Equal(t, http.StatusCreated, post(r, "user-2", "retry-1", `{"name":"Lab"}`).Code)
+	assert.Equal(t, http.StatusCreated, post(r, "user-1", "", `{"name":"Hall"}`).Code)
+	assert.Equal(t, http.StatusCreated, post(r, "user-1", "", `{"name":"Hall"}`).Code)
+	assert.Equal(t, int32(4), runs.Load())
+
+	w = post(r, "user-1", strings.Repeat("k", 256), `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGinIdempotencyMiddlewareSerializesConcurrentRequests(t *testing.T) {
+	release := make(chan struct{})
+	r, runs := newIdempotentRouter(idempotency.NewMemoryStore(), testIdempotencyConfig, func(c *gin.Context) {
+		<-release
+		c.JSON(http.StatusCreated, gin.H{"id": "1"})
+	})
+
+	var wg sync.WaitGroup
+	responses := make([]*httptest.ResponseRecorder, 3)
+	for i := range responses {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			responses[i] = post(r, "user-1", "retry-1", `{"name":"Hall"}`)
+		}()
+	}
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), runs.Load())
+	replayed := 0
+	for _, w := range responses {
+		assert.Equal(t, http.StatusCreated, w.Code)
+		if w.Header().Get(ReplayedHeader) == "true" {
+			replayed++
+		}
+	}
+	assert.Equal(t, 2, replayed)
+}
+
+func TestGinIdempotencyMiddlewareGivesUpWaiting(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	cfg := testIdempotencyConfig
+	cfg.WaitTimeout = 100 * time.Millisecond
+	r, _ := newIdempotentRouter(idempotency.NewMemoryStore(), cfg, func(c *gin.Context) {
+		<-release
+		c.Status(http.StatusCreated)
+	})
+
+	go post(r, "user-1", "retry-1", `{}`)
+	time.Sleep(50 * time.Millisecond)
+
+	w := post(r, "user-1", "retry-1", `{}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "IDEMPOTENCY_KEY_IN_USE")
+}
+
+func TestGinIdempotencyMiddlewareStoresErrorResponses(t *testing.T) {
+	var fail atomic.Value
+	fail.Store(domain.ErrConflict)
+	r, runs := newIdempotentRouter(idempotency.NewMemoryStore(), testIdempotencyConfig, func(c *gin.Context) {
+		c.Error(fail.Load().(error))
+	})
+
+	// Errors handled by GinErrorMiddleware are stored
+	assert.Equal(t, http.StatusConflict, post(r, "user-1", "retry-1", `{}`).Code)
+	w := post(r, "user-1", "retry-1", `{}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "true", w.Header().Get(ReplayedHeader))
+	assert.Equal(t, int32(1), runs.Load())
+
+	// Server errors are not, so the request can be retried
+	fail.Store(domain.ErrUnavailable)
+	assert.Equal(t, http.StatusServiceUnavailable, post(r, "user-1", "retry-2", `{}`).Code)
+	assert.Equal(t, http.StatusServiceUnavailable, post(r, "user-1", "retry-2", `{}`).Code)
+	assert.Equal(t, int32(3), runs.Load())
+}
+
+func TestGinIdempotencyMiddlewareRejectsOversizedBodies(t *testing.T) {
+	r, runs := newIdempotentRouter(idempotency.NewMemoryStore(), testIdempotencyConfig, nil)
+
+	w := post(r, "user-1", "retry-1", strings.Repeat("x", maxBufferedBodyBytes+1))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "PAYLOAD_TOO_LARGE")
+	assert.Equal(t, int32(0), runs.Load())
+
+	// A body at the limit is still accepted
+	w = post(r, "user-1", "retry-2", strings.Repeat("x", maxBufferedBodyBytes))
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/metrics"
+)
+
+// GinMetricsMiddleware records the duration of every request by route. Requests
+// that match no route share one label, and methods outside of the standard ones
+// share "OTHER", so scanners cannot create new series.
+func GinMetricsMiddleware(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		done := m.StartHTTPRequest()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		done(methodLabel(c.Request.Method), route, c.Writer.Status())
+	}
+}
+
+// methodLabel returns method if it is a standard HTTP method, or "OTHER"
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+	return "OTHER"
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/metrics"
+)
+
+func TestGinMetricsMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	m := metrics.New()
+	r := gin.New()
+	r.Use(GinMetricsMiddleware(m))
+	r.GET("/things/:id", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	for _, path := range []string{"/things/1", "/things/2", "/wp-login.php"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("PROPFIND", "/things/1", nil))
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, err := io.ReadAll(w.Body)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(body), `zolaris_http_request_duration_seconds_count{method="GET",route="/things/:id",status="204"} 2`)
+	assert.Contains(t, string(body), `zolaris_http_request_duration_seconds_count{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, string(body), `zolaris_http_request_duration_seconds_count{method="OTHER",route="unmatched",status="404"} 1`)
+	assert.NotContains(t, string(body), "wp-login")
+	assert.NotContains(t, string(body), "PROPFIND")
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-openapi/spec"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/openapi"
+	"github.com/afreedicp/zolaris-backend-app/internal/transport/response"
+)
+
+// legacyPrefix is the version the unversioned routes are aliases of
+const legacyPrefix = "/v1"
+
+// GinOpenAPIMiddleware checks every request and response against doc, for
+// development and testing. Mismatches are logged; with enforce, invalid
+// requests are also rejected with 400 before reaching the handlers. Responses
+// are only logged, as they have been sent by then. Routes missing from doc,
+// such as /metrics, are passed through, and the unversioned routes are checked
+// against their /v1 operation. It must run before GinErrorMiddleware, so the
+// error responses it writes are checked too.
+func GinOpenAPIMiddleware(doc *openapi.Document, enforce bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		op := operation(doc, c)
+		if op == nil {
+			c.Next()
+			return
+		}
+		ctx := c.Request.Context()
+
+		var body []byte
+		if c.Request.Body != nil {
+			var ok bool
+			if body, ok = bufferBody(c); !ok {
+				return
+			}
+		}
+
+		params := make(map[string]string, len(c.Params))
+		for _, param := range c.Params {
+			params[param.Key] = param.Value
+		}
+		if err := doc.ValidateRequest(op, c.Request, params, body); err != nil {
+			slog.WarnContext(ctx, "Request does not match the OpenAPI document", "error", err)
+			if enforce {
+				response.Error(c, http.StatusBadRequest, err.Error(), "OPENAPI_REQUEST_INVALID")
+				c.Abort()
+				return
+			}
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		if err := doc.ValidateResponse(op, recorder.Status(), recorder.Header().Get("Content-Type"), recorder.body.Bytes()); err != nil {
+			slog.ErrorContext(ctx, "Response does not match the OpenAPI document", "status", recorder.Status(), "error", err)
+		}
+	}
+}
+
+// operation returns the documented operation of the route of c, or nil
+func operation(doc *openapi.Document, c *gin.Context) *spec.Operation {
+	path := c.FullPath()
+	if path == "" {
+		return nil
+	}
+	if op := doc.Operation(c.Request.Method, path); op != nil {
+		return op
+	}
+	return doc.Operation(c.Request.Method, legacyPrefix+path)
+}
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/config"
+	"github.com/afreedicp/zolaris-backend-app/internal/logging"
+	"github.com/afreedicp/zolaris-backend-app/internal/openapi"
+)
+
+const testOpenAPIDocument = `{
+	"swagger": "2.0",
+	"paths": {
+		"/v1/entity/{entity_id}": {
+			"put": {
+				"parameters": [
+					{"name": "entity_id", "in": "path", "type": "integer", "required": true},
+					{"name": "entity", "in": "body", "required": true, "schema": {
+						"type": "object", "required": ["name"], "properties": {"name": {"type": "string"}}
+					}}
+				],
+				"responses": {"200": {"schema": {
+					"type": "object", "required": ["success"], "properties": {"success": {"type": "boolean"}}
+				}}}
+			}
+		}
+	}
+}`
+
+// newOpenAPIRouter serves PUT /entity/:entity_id and its /v1 copy with a
+// handler that echoes the request body, logging to the returned buffer
+func newOpenAPIRouter(t *testing.T, enforce bool, reply string) (*gin.Engine, *bytes.Buffer) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(logging.New(&buf, config.LoggingConfig{Format: "json"}, slog.LevelInfo))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	doc, err := openapi.Load([]byte(testOpenAPIDocument))
+	require.NoError(t, err)
+
+	handler := func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.Header("X-Echo", string(body))
+		c.Data(http.StatusOK, "application/json", []byte(reply))
+	}
+	r := gin.New()
+	r.Use(GinOpenAPIMiddleware(doc, enforce))
+	r.PUT("/v1/entity/:entity_id", handler)
+	r.PUT("/entity/:entity_id", handler)
+	r.GET("/metrics", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	return r, &buf
+}
+
+func serveOpenAPI(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestGinOpenAPIMiddleware(t *testing.T) {
+	r, logs := newOpenAPIRouter(t, true, `{"success": true}`)
+
+	// Valid requests reach the handler with their body intact
+	w := serveOpenAPI(r, http.MethodPut, "/v1/entity/42", `{"name": "Hall"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"name": "Hall"}`, w.Header().Get("X-Echo"))
+	assert.Empty(t, logs.String())
+
+	// The unversioned copies are checked against /v1
+	w = serveOpenAPI(r, http.MethodPut, "/entity/42", `{"floor": 2}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "OPENAPI_REQUEST_INVALID")
+	assert.Contains(t, w.Body.String(), "body.name is required")
+
+	w = serveOpenAPI(r, http.MethodPut, "/v1/entity/hall", `{"name": "Hall"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, logs.String(), "Request does not match the OpenAPI document")
+
+	w = serveOpenAPI(r, http.MethodPut, "/v1/entity/42", strings.Repeat("x", maxBufferedBodyBytes+1))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "PAYLOAD_TOO_LARGE")
+
+	// Undocumented routes are passed through
+	w = serveOpenAPI(r, http.MethodGet, "/metrics", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestGinOpenAPIMiddlewareLogsOnly(t *testing.T) {
+	r, logs := newOpenAPIRouter(t, false, `{"ok": true}`)
+
+	w := serveOpenAPI(r, http.MethodPut, "/v1/entity/42", `{"name": 1}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"ok": true}`, w.Body.String())
+	assert.Contains(t, logs.String(), "Request does not match the OpenAPI document")
+	assert.Contains(t, logs.String(), "Response does not match the OpenAPI document")
+	assert.Contains(t, logs.String(), "response.success is required")
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/ratelimit"
+)
+
+// failingStore stands in for an unreachable Redis
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("connection refused")
+}
+
+func newRateLimitedRouter(store ratelimit.Store, limit ratelimit.Limit, apiKeys []string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if userID := c.GetHeader("X-Test-User"); userID != "" {
+			c.Set(string(UserIDKey), userID)
+		}
+	})
+	r.Use(GinRateLimitMiddleware(store, "test", limit, RateLimitKey(apiKeys)))
+	r.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r
+}
+
+func serve(r *gin.Engine, remoteAddr string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.RemoteAddr = remoteAddr
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestGinRateLimitMiddleware(t *testing.T) {
+	r := newRateLimitedRouter(ratelimit.NewMemoryStore(), ratelimit.Limit{Requests: 2, Per: time.Minute}, nil)
+
+	w := serve(r, "10.0.0.1:1234", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "30", w.Header().Get("X-RateLimit-Reset"))
+
+	serve(r, "10.0.0.1:1234", nil)
+	w = serve(r, "10.0.0.1:1234", nil)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), `"RATE_LIMITED"`)
+
+	// Another IP is not affected
+	w = serve(r, "10.0.0.2:1234", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRateLimitKey(t *testing.T) {
+	r := newRateLimitedRouter(ratelimit.NewMemoryStore(), ratelimit.Limit{Requests: 1, Per: time.Minute}, []string{"partner-key"})
+
+	assert.Equal(t, http.StatusOK, serve(r, "10.0.0.1:1234", nil).Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve(r, "10.0.0.1:1234", nil).Code)
+
+	// Users and known API keys have their own buckets, even behind the same IP
+	assert.Equal(t, http.StatusOK, serve(r, "10.0.0.1:1234", map[string]string{"X-Test-User": "user-1"}).Code)
+	assert.Equal(t, http.StatusOK, serve(r, "10.0.0.1:1234", map[string]string{APIKeyHeader: "partner-key"}).Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve(r, "10.0.0.1:1234", map[string]string{APIKeyHeader: "partner-key"}).Code)
+
+	// Unknown API keys are counted against the IP
+	assert.Equal(t, http.StatusTooManyRequests, serve(r, "10.0.0.1:1234", map[string]string{APIKeyHeader: "made-up"}).Code)
+}
+
+func TestGinRateLimitMiddlewareAllowsRequestsWhenStoreFails(t *testing.T) {
+	r := newRateLimitedRouter(failingStore{}, ratelimit.Limit{Requests: 1, Per: time.Minute}, nil)
+
+	for range 3 {
+		w := serve(r, "10.0.0.1:1234", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("X-RateLimit-Limit"))
+	}
+}
+
+func TestGinRateLimitMiddlewareDisabled(t *testing.T) {
+	r := newRateLimitedRouter(nil, ratelimit.Limit{Requests: 1, Per: time.Minute}, nil)
+	serve(r, "10.0.0.1:1234", nil)
+	assert.Equal(t, http.StatusOK, serve(r, "10.0.0.1:1234", nil).Code)
+
+	r = newRateLimitedRouter(ratelimit.NewMemoryStore(), ratelimit.Limit{}, nil)
+	serve(r, "10.0.0.1:1234", nil)
+	assert.Equal(t, http.StatusOK, serve(r, "10.0.0.1:1234", nil).Code)
+}
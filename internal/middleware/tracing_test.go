@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/config"
+	"github.com/afreedicp/zolaris-backend-app/internal/logging"
+	"github.com/afreedicp/zolaris-backend-app/internal/testutil/spantest"
+	"github.com/afreedicp/zolaris-backend-app/internal/tracing"
+)
+
+func TestGinTracingMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := spantest.Record(t)
+	previousPropagator := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(previousPropagator) })
+
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(logging.New(&buf, config.LoggingConfig{Format: "json"}, slog.LevelInfo))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	r := gin.New()
+	r.Use(GinLoggerMiddleware())
+	r.Use(GinTracingMiddleware())
+	r.GET("/entity/:entity_id/hierarchy", func(c *gin.Context) {
+		// Stands in for a service call
+		_, span := tracing.Start(c.Request.Context(), "EntityService.GetEntityHierarchy")
+		span.End()
+		c.Status(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/entity/42/hierarchy", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set(RequestIDHeader, "abc-123")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	server := spantest.Find(recorder, "GET /entity/:entity_id/hierarchy")
+	require.NotNil(t, server)
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+	assert.True(t, server.Parent().IsRemote())
+	assert.Equal(t, codes.Error, server.Status().Code)
+	assert.Contains(t, server.Attributes(), attribute.Int("http.response.status_code", http.StatusInternalServerError))
+	assert.Contains(t, server.Attributes(), attribute.String(logging.RequestIDKey, "abc-123"))
+
+	service := spantest.Find(recorder, "EntityService.GetEntityHierarchy")
+	require.NotNil(t, service)
+	assert.Equal(t, server.SpanContext().SpanID(), service.Parent().SpanID())
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", record[logging.TraceIDKey])
+}
+
+func TestGinTracingMiddlewareUnmatchedRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := spantest.Record(t)
+
+	r := gin.New()
+	r.Use(GinTracingMiddleware())
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/wp-admin", nil))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "GET", spans[0].Name())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+}
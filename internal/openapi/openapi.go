@@ -0,0 +1,305 @@
+// Package openapi checks requests and responses against the OpenAPI (Swagger
+// 2.0) document generated from the handler annotations. It covers what the
+// document of this API uses: parameters in the path, query and headers, JSON
+// bodies, and schemas built from types, required properties, enums, arrays,
+// references and allOf. JSON null is accepted for any value, as the document
+// does not mark nullable fields.
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-openapi/spec"
+)
+
+// Document is a parsed OpenAPI document
+type Document struct {
+	swagger *spec.Swagger
+}
+
+// Load parses the OpenAPI document in data
+func Load(data []byte) (*Document, error) {
+	var swagger spec.Swagger
+	if err := json.Unmarshal(data, &swagger); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+	if swagger.Paths == nil {
+		return nil, errors.New("OpenAPI document has no paths")
+	}
+	return &Document{swagger: &swagger}, nil
+}
+
+// Operation returns the operation documented for method on path, given with
+// Gin's :param placeholders, or nil if there is none
+func (d *Document) Operation(method, path string) *spec.Operation {
+	item, ok := d.swagger.Paths.Paths[documentPath(path)]
+	if !ok {
+		return nil
+	}
+
+	switch method {
+	case http.MethodGet:
+		return item.Get
+	case http.MethodPost:
+		return item.Post
+	case http.MethodPut:
+		return item.Put
+	case http.MethodPatch:
+		return item.Patch
+	case http.MethodDelete:
+		return item.Delete
+	case http.MethodHead:
+		return item.Head
+	case http.MethodOptions:
+		return item.Options
+	}
+	return nil
+}
+
+// ValidateRequest checks the parameters and body of req against op. pathParams
+// holds the values of the path placeholders; body is the request body.
+func (d *Document) ValidateRequest(op *spec.Operation, req *http.Request, pathParams map[string]string, body []byte) error {
+	var errs []error
+	query := req.URL.Query()
+
+	for _, param := range op.Parameters {
+		var (
+			value   string
+			present bool
+		)
+		switch param.In {
+		case "path":
+			value, present = pathParams[param.Name]
+		case "query":
+			present = query.Has(param.Name)
+			value = query.Get(param.Name)
+		case "header":
+			value = req.Header.Get(param.Name)
+			present = value != ""
+		case "body":
+			errs = append(errs, d.validateBody(param, req.Header.Get("Content-Type"), body)...)
+			continue
+		default:
+			continue
+		}
+
+		if !present {
+			if param.Required {
+				errs = append(errs, fmt.Errorf("%s parameter %q is required", param.In, param.Name))
+			}
+			continue
+		}
+		if err := validateParameter(param, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s parameter %q %w", param.In, param.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidateResponse checks that status is documented for op and, for JSON
+// responses, that body matches the documented schema
+func (d *Document) ValidateResponse(op *spec.Operation, status int, contentType string, body []byte) error {
+	if op.Responses == nil {
+		return fmt.Errorf("status %d is not documented", status)
+	}
+
+	response, ok := op.Responses.StatusCodeResponses[status]
+	if !ok {
+		if op.Responses.Default == nil {
+			return fmt.Errorf("status %d is not documented", status)
+		}
+		response = *op.Responses.Default
+	}
+
+	if response.Schema == nil || len(body) == 0 || !isJSON(contentType) {
+		return nil
+	}
+
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return fmt.Errorf("response body is not JSON: %w", err)
+	}
+	return errors.Join(d.validateValue(response.Schema, value, "response")...)
+}
+
+// validateBody checks a JSON body against the schema of the body parameter
+func (d *Document) validateBody(param spec.Parameter, contentType string, body []byte) []error {
+	if len(body) == 0 {
+		if param.Required {
+			return []error{errors.New("request body is required")}
+		}
+		return nil
+	}
+	// Other media types, such as CSV imports, are left to the handler
+	if contentType != "" && !isJSON(contentType) {
+		return nil
+	}
+
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return []error{fmt.Errorf("request body is not JSON: %w", err)}
+	}
+	return d.validateValue(param.Schema, value, "body")
+}
+
+// validateValue checks value, decoded from JSON, against schema
+func (d *Document) validateValue(schema *spec.Schema, value any, at string) []error {
+	if schema == nil || value == nil {
+		return nil
+	}
+
+	if schema.Ref.String() != "" {
+		resolved, err := spec.ResolveRef(d.swagger, &schema.Ref)
+		if err != nil {
+			return []error{fmt.Errorf("%s: %w", at, err)}
+		}
+		return d.validateValue(resolved, value, at)
+	}
+
+	var errs []error
+	for i := range schema.AllOf {
+		errs = append(errs, d.validateValue(&schema.AllOf[i], value, at)...)
+	}
+
+	if len(schema.Type) > 0 && !matchesType(schema.Type, value) {
+		return append(errs, fmt.Errorf("%s: %s is not of type %s", at, jsonType(value), strings.Join(schema.Type, " or ")))
+	}
+
+	if len(schema.Enum) > 0 && !inEnum(schema.Enum, value) {
+		errs = append(errs, fmt.Errorf("%s: %v is not one of %v", at, value, schema.Enum))
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		for _, name := range schema.Required {
+			if _, ok := v[name]; !ok {
+				errs = append(errs, fmt.Errorf("%s.%s is required", at, name))
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if property, ok := schema.Properties[name]; ok {
+				errs = append(errs, d.validateValue(&property, v[name], at+"."+name)...)
+			} else if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+				errs = append(errs, d.validateValue(schema.AdditionalProperties.Schema, v[name], at+"."+name)...)
+			}
+		}
+	case []any:
+		if schema.Items != nil && schema.Items.Schema != nil {
+			for i, item := range v {
+				errs = append(errs, d.validateValue(schema.Items.Schema, item, fmt.Sprintf("%s[%d]", at, i))...)
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateParameter checks a path, query or header value against the type and enum of param
+func validateParameter(param spec.Parameter, value string) error {
+	switch param.Type {
+	case "integer":
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return fmt.Errorf("must be an integer, not %q", value)
+		}
+	case "number":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("must be a number, not %q", value)
+		}
+	case "boolean":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("must be a boolean, not %q", value)
+		}
+	}
+
+	if len(param.Enum) > 0 && !inEnum(param.Enum, value) {
+		return fmt.Errorf("must be one of %v, not %q", param.Enum, value)
+	}
+	return nil
+}
+
+// matchesType reports whether value, decoded from JSON, is one of types
+func matchesType(types spec.StringOrArray, value any) bool {
+	for _, t := range types {
+		switch v := value.(type) {
+		case map[string]any:
+			if t == "object" {
+				return true
+			}
+		case []any:
+			if t == "array" {
+				return true
+			}
+		case string:
+			if t == "string" {
+				return true
+			}
+		case bool:
+			if t == "boolean" {
+				return true
+			}
+		case float64:
+			if t == "number" || (t == "integer" && v == float64(int64(v))) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jsonType names the JSON type of value
+func jsonType(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	}
+	return "null"
+}
+
+// inEnum reports whether value is in enum, comparing strings with their JSON
+// value so query parameters match numeric enums
+func inEnum(enum []any, value any) bool {
+	for _, allowed := range enum {
+		if allowed == value || fmt.Sprint(allowed) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// isJSON reports whether contentType is JSON
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
+
+// documentPath converts Gin's :param placeholders to the document's {param}
+func documentPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,140 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDocument = `{
+	"swagger": "2.0",
+	"paths": {
+		"/v1/entity/{entity_id}/children": {
+			"get": {
+				"parameters": [
+					{"name": "entity_id", "in": "path", "type": "string", "required": true},
+					{"name": "depth", "in": "query", "type": "integer"},
+					{"name": "order", "in": "query", "type": "string", "enum": ["asc", "desc"]},
+					{"name": "X-Cognito-ID", "in": "header", "type": "string", "required": true}
+				],
+				"responses": {
+					"200": {"schema": {"allOf": [
+						{"$ref": "#/definitions/Response"},
+						{"type": "object", "properties": {"data": {"type": "array", "items": {"$ref": "#/definitions/Entity"}}}}
+					]}}
+				}
+			}
+		},
+		"/v1/entity": {
+			"post": {
+				"parameters": [
+					{"name": "entity", "in": "body", "required": true, "schema": {"$ref": "#/definitions/Entity"}}
+				],
+				"responses": {"201": {"schema": {"$ref": "#/definitions/Response"}}}
+			}
+		}
+	},
+	"definitions": {
+		"Response": {
+			"type": "object",
+			"required": ["success"],
+			"properties": {"success": {"type": "boolean"}, "message": {"type": "string"}}
+		},
+		"Entity": {
+			"type": "object",
+			"required": ["name"],
+			"properties": {
+				"name": {"type": "string"},
+				"floor": {"type": "integer"},
+				"details": {"type": "object", "additionalProperties": {"type": "string"}}
+			}
+		}
+	}
+}`
+
+func loadTestDocument(t *testing.T) *Document {
+	t.Helper()
+	doc, err := Load([]byte(testDocument))
+	require.NoError(t, err)
+	return doc
+}
+
+func TestOperation(t *testing.T) {
+	doc := loadTestDocument(t)
+
+	assert.NotNil(t, doc.Operation(http.MethodGet, "/v1/entity/:entity_id/children"))
+	assert.NotNil(t, doc.Operation(http.MethodPost, "/v1/entity"))
+	assert.Nil(t, doc.Operation(http.MethodPut, "/v1/entity"))
+	assert.Nil(t, doc.Operation(http.MethodGet, "/v1/entity/:id/children"))
+	assert.Nil(t, doc.Operation(http.MethodGet, "/metrics"))
+}
+
+func TestValidateRequestParameters(t *testing.T) {
+	doc := loadTestDocument(t)
+	op := doc.Operation(http.MethodGet, "/v1/entity/:entity_id/children")
+	params := map[string]string{"entity_id": "42"}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/entity/42/children?depth=2&order=asc", nil)
+	req.Header.Set("X-Cognito-ID", "user-1")
+	assert.NoError(t, doc.ValidateRequest(op, req, params, nil))
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/entity/42/children?depth=two&order=up", nil)
+	err := doc.ValidateRequest(op, req, params, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `header parameter "X-Cognito-ID" is required`)
+	assert.Contains(t, err.Error(), `query parameter "depth" must be an integer`)
+	assert.Contains(t, err.Error(), `query parameter "order" must be one of`)
+}
+
+func TestValidateRequestBody(t *testing.T) {
+	doc := loadTestDocument(t)
+	op := doc.Operation(http.MethodPost, "/v1/entity")
+	validate := func(contentType, body string) error {
+		req := httptest.NewRequest(http.MethodPost, "/v1/entity", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		return doc.ValidateRequest(op, req, nil, []byte(body))
+	}
+
+	assert.NoError(t, validate("application/json", `{"name": "Hall", "floor": 2, "details": {"use": "storage"}}`))
+	// Null is accepted for any value
+	assert.NoError(t, validate("application/json", `{"name": "Hall", "floor": null}`))
+	// Other media types are left to the handler
+	assert.NoError(t, validate("text/csv", "name\nHall"))
+
+	assert.ErrorContains(t, validate("application/json", ""), "request body is required")
+	assert.ErrorContains(t, validate("application/json", "{"), "request body is not JSON")
+
+	err := validate("application/json; charset=utf-8", `{"floor": 2.5, "details": {"use": 1}}`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "body.name is required")
+	assert.Contains(t, err.Error(), "body.floor: number is not of type integer")
+	assert.Contains(t, err.Error(), "body.details.use: number is not of type string")
+}
+
+func TestValidateResponse(t *testing.T) {
+	doc := loadTestDocument(t)
+	op := doc.Operation(http.MethodGet, "/v1/entity/:entity_id/children")
+
+	assert.NoError(t, doc.ValidateResponse(op, http.StatusOK, "application/json", []byte(`{"success": true, "data": [{"name": "Hall"}]}`)))
+	// Bodies that are not JSON are not checked
+	assert.NoError(t, doc.ValidateResponse(op, http.StatusOK, "text/csv", []byte("name\nHall")))
+
+	assert.ErrorContains(t, doc.ValidateResponse(op, http.StatusNotFound, "application/json", nil), "status 404 is not documented")
+
+	err := doc.ValidateResponse(op, http.StatusOK, "application/json", []byte(`{"data": [{"floor": 1}]}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "response.success is required")
+	assert.Contains(t, err.Error(), "response.data[0].name is required")
+}
+
+func TestLoad(t *testing.T) {
+	_, err := Load([]byte("not json"))
+	assert.Error(t, err)
+
+	_, err = Load([]byte(`{"swagger": "2.0"}`))
+	assert.Error(t, err)
+}
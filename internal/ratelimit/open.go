@@ -0,0 +1,44 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/config"
+)
+
+// Stores selectable with RATE_LIMIT_STORE
+const (
+	StoreMemory = "memory"
+	StoreRedis  = "redis"
+	StoreNone   = "none"
+)
+
+// redisKeyPrefix namespaces the buckets of this service in a shared Redis
+const redisKeyPrefix = "zolaris:ratelimit:"
+
+// Open creates the store selected by the configuration. The redis store uses
+// client, opened by redisclient.Open. With none it returns nil, which disables
+// rate limiting.
+func Open(ctx context.Context, cfg *config.Config, client *redis.Client) (Store, error) {
+	switch cfg.RateLimit.Store {
+	case StoreMemory, "":
+		slog.InfoContext(ctx, "Rate limiting per instance")
+		return NewMemoryStore(), nil
+	case StoreRedis:
+		if client == nil {
+			return nil, errors.New("redis rate limit store requires a redis client")
+		}
+		slog.InfoContext(ctx, "Rate limiting across instances with redis")
+		return NewRedisStore(client, redisKeyPrefix), nil
+	case StoreNone:
+		slog.InfoContext(ctx, "Rate limiting is disabled")
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit store %q", cfg.RateLimit.Store)
+	}
+}
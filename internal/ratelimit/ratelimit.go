@@ -0,0 +1,106 @@
+// Package ratelimit throttles requests with token buckets. Each client has a
+// bucket per route group holding up to Limit.Requests tokens, refilled evenly
+// over Limit.Per; a request takes one token and is rejected when none is left.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit is the size of a bucket and the time it takes to refill from empty.
+// The zero Limit does not throttle.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// units are the periods accepted by ParseLimit
+var units = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+}
+
+// ParseLimit reads a limit such as "60/m": up to 60 requests at once, and one
+// more every second after that. The unit is s, m or h; "off" does not throttle.
+func ParseLimit(s string) (Limit, error) {
+	if s == "off" {
+		return Limit{}, nil
+	}
+
+	requests, unit, ok := strings.Cut(s, "/")
+	per, known := units[unit]
+	if !ok || !known {
+		return Limit{}, fmt.Errorf("limit must look like 60/m, with a unit of s, m or h, got %q", s)
+	}
+	n, err := strconv.Atoi(requests)
+	if err != nil || n < 1 {
+		return Limit{}, fmt.Errorf("limit must allow at least 1 request, got %q", s)
+	}
+	return Limit{Requests: n, Per: per}, nil
+}
+
+// Unlimited reports whether the limit does not throttle
+func (l Limit) Unlimited() bool {
+	return l.Requests == 0
+}
+
+// String returns the limit in the form read by ParseLimit
+func (l Limit) String() string {
+	if l.Unlimited() {
+		return "off"
+	}
+	for unit, per := range units {
+		if per == l.Per {
+			return fmt.Sprintf("%d/%s", l.Requests, unit)
+		}
+	}
+	return fmt.Sprintf("%d/%s", l.Requests, l.Per)
+}
+
+// rate returns the tokens added to a bucket per second
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+// Result is the state of a bucket after taking a token from it
+type Result struct {
+	Allowed bool
+	// Limit is the size of the bucket
+	Limit int
+	// Remaining is the number of whole tokens left
+	Remaining int
+	// RetryAfter is how long until a token is available, if the request was rejected
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+}
+
+// result describes a bucket holding tokens after a request was allowed or not
+func (l Limit) result(allowed bool, tokens float64) Result {
+	r := Result{
+		Allowed:   allowed,
+		Limit:     l.Requests,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     seconds((float64(l.Requests) - tokens) / l.rate()),
+	}
+	if !allowed {
+		r.RetryAfter = seconds((1 - tokens) / l.rate())
+	}
+	return r
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
+
+// Store keeps the buckets
+type Store interface {
+	// Take takes a token from the bucket under key, creating a full bucket if there is none
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
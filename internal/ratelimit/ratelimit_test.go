@@ -0,0 +1,120 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Limit
+		wantErr bool
+	}{
+		{in: "60/m", want: Limit{Requests: 60, Per: time.Minute}},
+		{in: "5/s", want: Limit{Requests: 5, Per: time.Second}},
+		{in: "30/h", want: Limit{Requests: 30, Per: time.Hour}},
+		{in: "off", want: Limit{}},
+		{in: "0/m", wantErr: true},
+		{in: "60", wantErr: true},
+		{in: "60/d", wantErr: true},
+		{in: "many/m", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseLimit(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.in, got.String())
+		})
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	limit := Limit{Requests: 3, Per: 3 * time.Second}
+
+	// The bucket starts full
+	for remaining := 2; remaining >= 0; remaining-- {
+		result, err := store.Take(ctx, "ip:1.2.3.4", limit)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, remaining, result.Remaining)
+	}
+
+	result, err := store.Take(ctx, "ip:1.2.3.4", limit)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, Result{Allowed: false, Limit: 3, Remaining: 0, RetryAfter: time.Second, Reset: 3 * time.Second}, result)
+
+	// Other clients have their own bucket
+	result, _ = store.Take(ctx, "ip:5.6.7.8", limit)
+	assert.True(t, result.Allowed)
+
+	// One token is added per second
+	now = now.Add(1500 * time.Millisecond)
+	result, _ = store.Take(ctx, "ip:1.2.3.4", limit)
+	assert.True(t, result.Allowed)
+	result, _ = store.Take(ctx, "ip:1.2.3.4", limit)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 500*time.Millisecond, result.RetryAfter)
+}
+
+func TestMemoryStoreSweepsFullBuckets(t *testing.T) {
+	now := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	limit := Limit{Requests: 10, Per: time.Minute}
+
+	store.Take(context.Background(), "a", limit)
+	store.Take(context.Background(), "b", limit)
+	require.Len(t, store.buckets, 2)
+
+	now = now.Add(2 * time.Minute)
+	store.Take(context.Background(), "c", limit)
+	assert.Len(t, store.buckets, 1)
+}
+
+func TestRedisStore(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	server.SetTime(time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC))
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	store := NewRedisStore(client, "test:")
+	t.Cleanup(func() { client.Close() })
+	limit := Limit{Requests: 2, Per: 2 * time.Second}
+
+	result, err := store.Take(ctx, "ip:1.2.3.4", limit)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Allowed: true, Limit: 2, Remaining: 1, Reset: time.Second}, result)
+	assert.True(t, server.Exists("test:ip:1.2.3.4"), "keys are namespaced")
+
+	result, _ = store.Take(ctx, "ip:1.2.3.4", limit)
+	assert.True(t, result.Allowed)
+	result, err = store.Take(ctx, "ip:1.2.3.4", limit)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.RetryAfter)
+
+	// Buckets are refilled by the clock of the Redis server
+	server.SetTime(time.Date(2025, 5, 1, 10, 0, 1, 0, time.UTC))
+	result, _ = store.Take(ctx, "ip:1.2.3.4", limit)
+	assert.True(t, result.Allowed)
+
+	// Buckets expire once they would be full again
+	server.FastForward(time.Minute)
+	assert.False(t, server.Exists("test:ip:1.2.3.4"))
+}
@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeScript takes a token from the bucket hash at KEYS[1], holding up to
+// ARGV[1] tokens refilled at ARGV[2] tokens per second. It uses the clock of
+// the Redis server, so instances with skewed clocks share buckets correctly.
+// It returns 1 if a token was taken, and the tokens left as a string, since
+// Lua numbers are truncated to integers in replies.
+var takeScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) + tonumber(time[2]) / 1000000
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'updated')
+local tokens = tonumber(state[1]) or capacity
+local updated = tonumber(state[2]) or now
+tokens = math.min(capacity, tokens + math.max(0, now - updated) * rate)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated', tostring(now))
+-- The bucket is full again by then, which is what a missing bucket means
+redis.call('PEXPIRE', KEYS[1], math.ceil((capacity - tokens) / rate * 1000) + 1000)
+return {allowed, tostring(tokens)}
+`)
+
+// RedisStore keeps the buckets in Redis, so every instance shares them
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore creates a store on the given client. Every key is namespaced with prefix.
+func NewRedisStore(client *redis.Client, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// Take takes a token from the bucket under key
+func (s *RedisStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	reply, err := takeScript.Run(ctx, s.client, []string{s.prefix + key}, limit.Requests, limit.rate()).Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to take a token: %w", err)
+	}
+	if len(reply) != 2 {
+		return Result{}, fmt.Errorf("unexpected reply %v", reply)
+	}
+
+	allowed, _ := reply[0].(int64)
+	tokensReply, _ := reply[1].(string)
+	tokens, err := strconv.ParseFloat(tokensReply, 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("unexpected token count %v: %w", reply[1], err)
+	}
+	return limit.result(allowed == 1, tokens), nil
+}
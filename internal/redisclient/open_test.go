@@ -0,0 +1,42 @@
+package redisclient
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/config"
+)
+
+func TestOpen(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+
+	cfg := &config.Config{}
+	cfg.Cache.Backend = "memory"
+	cfg.Cache.RedisURL = "redis://" + server.Addr() + "/0"
+	cfg.RateLimit.Store = "memory"
+	cfg.Idempotency.Store = "none"
+
+	client, err := Open(ctx, cfg)
+	require.NoError(t, err)
+	assert.Nil(t, client, "no store uses redis")
+
+	cfg.Idempotency.Store = "redis"
+	client, err = Open(ctx, cfg)
+	require.NoError(t, err)
+	require.NotNil(t, client)
+	t.Cleanup(func() { client.Close() })
+	assert.NoError(t, client.Ping(ctx).Err())
+
+	server.Close()
+	_, err = Open(ctx, cfg)
+	assert.ErrorContains(t, err, "failed to connect to redis")
+
+	cfg.Cache.RedisURL = "memcached://localhost"
+	_, err = Open(ctx, cfg)
+	assert.ErrorContains(t, err, "invalid redis URL")
+}
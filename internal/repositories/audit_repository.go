@@ -0,0 +1,193 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+)
+
+// AuditRepository writes and reads the append-only audit log of mutating actions
+type AuditRepository struct {
+	db    DBTX
+	reads ReadRouter
+}
+
+// NewAuditRepository creates a new audit repository instance
+func NewAuditRepository(dbPool *pgxpool.Pool) *AuditRepository {
+	return &AuditRepository{
+		db: dbPool,
+	}
+}
+
+// WithTx returns a copy of the repository that runs its queries on tx, so an
+// entry is only kept if the action it records is committed
+func (r *AuditRepository) WithTx(tx pgx.Tx) AuditRepositoryInterface {
+	return &AuditRepository{
+		db: tx,
+	}
+}
+
+// WithReadRouter routes the audit queries of the repository through router
+func (r *AuditRepository) WithReadRouter(router ReadRouter) AuditRepositoryInterface {
+	r.reads = router
+	return r
+}
+
+// auditColumns are the columns scanned by scanAuditEntry
+const auditColumns = `a.audit_id, a.occurred_at, a.actor_id, a.action, a.resource_type, a.resource_id, a.changes, a.ip, a.request_id`
+
+// auditListSpec whitelists the sort fields of audit lists; filters are applied from domain.AuditFilter
+var auditListSpec = listSpec{
+	sortFields: map[string]sortField{
+		"occurredAt": {column: "a.occurred_at", cast: "timestamptz"},
+	},
+	defaultSort: "occurredAt",
+	tiebreaker:  "a.audit_id::text",
+}
+
+// RecordAuditEntry appends entry to the audit log, setting its ID and time
+func (r *AuditRepository) RecordAuditEntry(ctx context.Context, entry *domain.AuditEntry) error {
+	query := `
+		INSERT INTO z_audit_log (actor_id, action, resource_type, resource_id, changes, ip, request_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING audit_id, occurred_at
+	`
+
+	changes := entry.Changes
+	if changes == nil {
+		changes = map[string]domain.AuditChange{}
+	}
+
+	err := r.db.QueryRow(ctx, query,
+		entry.ActorID,
+		entry.Action,
+		entry.ResourceType,
+		entry.ResourceID,
+		changes,
+		entry.IP,
+		entry.RequestID,
+	).Scan(&entry.ID, &entry.OccurredAt)
+	if err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	return nil
+}
+
+// ListAuditEntries returns one page of the audit entries matching filter
+func (r *AuditRepository) ListAuditEntries(ctx context.Context, filter domain.AuditFilter, opts domain.ListOptions) (*domain.Page[*domain.AuditEntry], error) {
+	db := readDB(r.db, r.reads)
+
+	q, err := newListQuery(auditListSpec, opts)
+	if err != nil {
+		return nil, err
+	}
+	applyAuditFilter(q, filter)
+
+	from := `FROM z_audit_log a`
+
+	var total int64
+	if err := db.QueryRow(ctx, q.countSQL(from), q.countArgs()...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count audit entries: %w", err)
+	}
+
+	query, args := q.pageSQL(auditColumns, from)
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]*domain.AuditEntry, 0)
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit rows: %w", err)
+	}
+
+	return newPage(q, entries, total, func(entry *domain.AuditEntry, sortName string) (string, string) {
+		return entry.OccurredAt.Format(time.RFC3339Nano), fmt.Sprint(entry.ID)
+	}), nil
+}
+
+// ExportAuditEntries passes every audit entry matching filter to fn, oldest
+// first, without holding them all in memory. It stops at the first error of fn.
+func (r *AuditRepository) ExportAuditEntries(ctx context.Context, filter domain.AuditFilter, fn func(entry *domain.AuditEntry) error) error {
+	q, err := newListQuery(auditListSpec, domain.ListOptions{})
+	if err != nil {
+		return err
+	}
+	applyAuditFilter(q, filter)
+
+	query := fmt.Sprintf("SELECT %s FROM z_audit_log a%s ORDER BY a.occurred_at, a.audit_id", auditColumns, whereSQL(q.conditions))
+	rows, err := readDB(r.db, r.reads).Query(ctx, query, q.args...)
+	if err != nil {
+		return fmt.Errorf("failed to query audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating audit rows: %w", err)
+	}
+	return nil
+}
+
+// applyAuditFilter adds the conditions of filter to q
+func applyAuditFilter(q *listQuery, filter domain.AuditFilter) {
+	if filter.ActorID != "" {
+		q.where("a.actor_id = " + q.arg(filter.ActorID) + "::uuid")
+	}
+	if filter.Action != "" {
+		q.where("a.action = " + q.arg(filter.Action))
+	}
+	if filter.ResourceType != "" {
+		q.where("a.resource_type = " + q.arg(filter.ResourceType))
+	}
+	if filter.ResourceID != "" {
+		q.where("a.resource_id = " + q.arg(filter.ResourceID))
+	}
+	if !filter.From.IsZero() {
+		q.where("a.occurred_at >= " + q.arg(filter.From))
+	}
+	if !filter.To.IsZero() {
+		q.where("a.occurred_at < " + q.arg(filter.To))
+	}
+}
+
+func scanAuditEntry(rows pgx.Rows) (*domain.AuditEntry, error) {
+	entry := new(domain.AuditEntry)
+	if err := rows.Scan(
+		&entry.ID,
+		&entry.OccurredAt,
+		&entry.ActorID,
+		&entry.Action,
+		&entry.ResourceType,
+		&entry.ResourceID,
+		&entry.Changes,
+		&entry.IP,
+		&entry.RequestID,
+	); err != nil {
+		return nil, fmt.Errorf("failed to scan audit row: %w", err)
+	}
+	return entry, nil
+}
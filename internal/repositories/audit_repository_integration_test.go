@@ -0,0 +1,92 @@
+//go:build integration
+
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+	"github.com/afreedicp/zolaris-backend-app/internal/testutil/pgtest"
+)
+
+func TestAuditRepository(t *testing.T) {
+	tx := pgtest.Tx(t)
+	repo := NewAuditRepository(nil).WithTx(tx)
+	ctx := context.Background()
+	user := seedUser(t, tx, nil)
+
+	ip := "203.0.113.7"
+	entries := []*domain.AuditEntry{
+		{ActorID: &user.ID, Action: domain.AuditActionCreate, ResourceType: domain.AuditResourceDevice, ResourceID: "AA:00:00:00:00:01", IP: &ip,
+			Changes: map[string]domain.AuditChange{"name": {After: json.RawMessage(`"Meter"`)}}},
+		{Action: domain.AuditActionCreate, ResourceType: domain.AuditResourceUser, ResourceID: user.ID},
+		{ActorID: &user.ID, Action: domain.AuditActionUpdate, ResourceType: domain.AuditResourceUser, ResourceID: user.ID},
+	}
+	for _, entry := range entries {
+		require.NoError(t, repo.RecordAuditEntry(ctx, entry))
+		assert.NotZero(t, entry.ID)
+		assert.False(t, entry.OccurredAt.IsZero())
+	}
+
+	ids := func(entries []*domain.AuditEntry) []int64 {
+		result := make([]int64, len(entries))
+		for i, entry := range entries {
+			result[i] = entry.ID
+		}
+		return result
+	}
+
+	cases := []struct {
+		name   string
+		filter domain.AuditFilter
+		want   []int64
+	}{
+		{"by actor", domain.AuditFilter{ActorID: user.ID}, []int64{entries[2].ID, entries[0].ID}},
+		{"by resource", domain.AuditFilter{ResourceType: domain.AuditResourceUser, ResourceID: user.ID}, []int64{entries[2].ID, entries[1].ID}},
+		{"by action", domain.AuditFilter{Action: domain.AuditActionUpdate, ActorID: user.ID}, []int64{entries[2].ID}},
+		{"before the entries", domain.AuditFilter{ActorID: user.ID, To: entries[0].OccurredAt}, []int64{}},
+		{"from the entries", domain.AuditFilter{ActorID: user.ID, From: entries[0].OccurredAt, To: time.Now().Add(time.Hour)}, []int64{entries[2].ID, entries[0].ID}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			page, err := repo.ListAuditEntries(ctx, tc.filter, domain.ListOptions{Sort: "-occurredAt"})
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, ids(page.Items))
+			assert.Equal(t, int64(len(tc.want)), page.TotalItems)
+		})
+	}
+
+	page, err := repo.ListAuditEntries(ctx, domain.AuditFilter{ActorID: user.ID}, domain.ListOptions{Sort: "-occurredAt"})
+	require.NoError(t, err)
+	first := page.Items[1]
+	assert.Equal(t, ip, *first.IP)
+	assert.Nil(t, first.RequestID)
+	assert.Equal(t, json.RawMessage(`"Meter"`), first.Changes["name"].After)
+
+	var exported []*domain.AuditEntry
+	err = repo.ExportAuditEntries(ctx, domain.AuditFilter{ResourceID: user.ID}, func(entry *domain.AuditEntry) error {
+		exported = append(exported, entry)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{entries[1].ID, entries[2].ID}, ids(exported))
+
+	t.Run("audit rows are append-only", func(t *testing.T) {
+		for _, statement := range []string{
+			`UPDATE z_audit_log SET action = 'delete' WHERE audit_id = $1`,
+			`DELETE FROM z_audit_log WHERE audit_id = $1`,
+		} {
+			sp, err := tx.Begin(ctx)
+			require.NoError(t, err)
+			_, err = sp.Exec(ctx, statement, entries[0].ID)
+			assert.Error(t, err)
+			require.NoError(t, sp.Rollback(ctx))
+		}
+	})
+}
@@ -15,7 +15,8 @@ import (
 
 // CategoryRepository handles all category-related database operations
 type CategoryRepository struct {
-	db *pgxpool.Pool
+	db    DBTX
+	reads ReadRouter
 }
 
 // NewCategoryRepository creates a new category repository instance
@@ -25,6 +26,28 @@ func NewCategoryRepository(dbPool *pgxpool.Pool) *CategoryRepository {
 	}
 }
 
+// WithTx returns a copy of the repository that runs its queries on tx
+func (r *CategoryRepository) WithTx(tx pgx.Tx) *CategoryRepository {
+	return &CategoryRepository{
+		db: tx,
+	}
+}
+
+// WithReadRouter routes the category listing queries of the repository through router
+func (r *CategoryRepository) WithReadRouter(router ReadRouter) *CategoryRepository {
+	r.reads = router
+	return r
+}
+
+// WithMaxLag returns a copy of the repository whose listing queries only use the
+// replica while it lags at most maxLag
+func (r *CategoryRepository) WithMaxLag(maxLag time.Duration) CategoryRepositoryInterface {
+	return &CategoryRepository{
+		db:    r.db,
+		reads: withMaxLag(r.reads, maxLag),
+	}
+}
+
 // AddCategory adds a new category to the database
 // This implementation matches the current signature while internally
 // creating a proper Category object
@@ -49,6 +72,9 @@ func (r *CategoryRepository) AddCategory(ctx context.Context, name, categoryType
 		now,
 	)
 	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.NewConflictError("category with this name already exists")
+		}
 		return fmt.Errorf("failed to add category: %w", err)
 	}
 
@@ -91,7 +117,7 @@ func (r *CategoryRepository) GetCategoriesByType(ctx context.Context, categoryTy
 		ORDER BY name
 	`
 
-	rows, err := r.db.Query(ctx, query, categoryType)
+	rows, err := readDB(r.db, r.reads).Query(ctx, query, categoryType)
 	if err != nil {
 		return nil, fmt.Errorf("database error: %w", err)
 	}
@@ -120,15 +146,38 @@ func (r *CategoryRepository) GetCategoriesByType(ctx context.Context, categoryTy
 	return categories, nil
 }
 
-// ListAllCategories retrieves all categories from the database
-func (r *CategoryRepository) ListAllCategories(ctx context.Context) ([]*domain.Category, error) {
-	query := `
-		SELECT category_id, name, type, created_at
-		FROM z_category
-		ORDER BY type, name
-	`
+// categoryListSpec whitelists the sort and filter fields of category lists
+var categoryListSpec = listSpec{
+	sortFields: map[string]sortField{
+		"name":      {column: "c.name", cast: "text"},
+		"type":      {column: "c.type::text", cast: "text"},
+		"createdAt": {column: "COALESCE(c.created_at, " + missingTime + ")", cast: "timestamptz"},
+	},
+	defaultSort: "name",
+	tiebreaker:  "c.category_id::text",
+	filterFields: map[string]string{
+		"type": "c.type",
+	},
+}
+
+// ListAllCategories retrieves one page of all categories from the database
+func (r *CategoryRepository) ListAllCategories(ctx context.Context, opts domain.ListOptions) (*domain.Page[*domain.Category], error) {
+	db := readDB(r.db, r.reads)
+
+	q, err := newListQuery(categoryListSpec, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	from := `FROM z_category c`
+
+	var total int64
+	if err := db.QueryRow(ctx, q.countSQL(from), q.countArgs()...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count categories: %w", err)
+	}
 
-	rows, err := r.db.Query(ctx, query)
+	query, args := q.pageSQL(`c.category_id, c.name, c.type, COALESCE(c.created_at, `+missingTime+`)`, from)
+	rows, err := db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("database error: %w", err)
 	}
@@ -154,5 +203,13 @@ func (r *CategoryRepository) ListAllCategories(ctx context.Context) ([]*domain.C
 		return nil, fmt.Errorf("error iterating category rows: %w", err)
 	}
 
-	return categories, nil
+	return newPage(q, categories, total, func(category *domain.Category, sortName string) (string, string) {
+		switch sortName {
+		case "type":
+			return category.Type, category.ID
+		case "createdAt":
+			return category.CreatedAt.Format(time.RFC3339Nano), category.ID
+		}
+		return category.Name, category.ID
+	}), nil
 }
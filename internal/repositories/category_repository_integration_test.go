@@ -0,0 +1,131 @@
+//go:build integration
+
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+	"github.com/afreedicp/zolaris-backend-app/internal/testutil/pgtest"
+)
+
+func TestCategoryRepository(t *testing.T) {
+	tx := pgtest.Tx(t)
+	repo := NewCategoryRepository(nil).WithTx(tx)
+	ctx := context.Background()
+
+	categories := []struct {
+		name         string
+		categoryType string
+	}{
+		{"home", "user"},
+		{"branch", "office"},
+		{"headquarters", "office"},
+		{"shelf", "location"},
+	}
+	for _, c := range categories {
+		require.NoError(t, repo.AddCategory(ctx, c.name, c.categoryType))
+	}
+
+	t.Run("get by name", func(t *testing.T) {
+		category, err := repo.GetCategoryByName(ctx, "headquarters")
+		require.NoError(t, err)
+		require.NotNil(t, category)
+		assert.Equal(t, "office", category.Type)
+		assert.NotEmpty(t, category.ID)
+
+		missing, err := repo.GetCategoryByName(ctx, "nowhere")
+		require.NoError(t, err)
+		assert.Nil(t, missing)
+	})
+
+	t.Run("get by type", func(t *testing.T) {
+		offices, err := repo.GetCategoriesByType(ctx, "office")
+		require.NoError(t, err)
+		require.Len(t, offices, 2)
+		assert.Equal(t, "branch", offices[0].Name)
+		assert.Equal(t, "headquarters", offices[1].Name)
+	})
+
+	// Every test runs in its own rolled back transaction, so these are the only categories
+	tests := []struct {
+		name      string
+		opts      domain.ListOptions
+		wantNames []string
+		wantTotal int64
+		wantNext  bool
+	}{
+		{"all", domain.ListOptions{}, []string{"branch", "headquarters", "home", "shelf"}, 4, false},
+		{"descending", domain.ListOptions{Sort: "-name"}, []string{"shelf", "home", "headquarters", "branch"}, 4, false},
+		{"filtered by type", domain.ListOptions{Filters: map[string]string{"type": "office"}}, []string{"branch", "headquarters"}, 2, false},
+		{"first page", domain.ListOptions{Page: 1, PageSize: 3}, []string{"branch", "headquarters", "home"}, 4, true},
+		{"second page", domain.ListOptions{Page: 2, PageSize: 3}, []string{"shelf"}, 4, false},
+	}
+	for _, tt := range tests {
+		t.Run("list "+tt.name, func(t *testing.T) {
+			page, err := repo.ListAllCategories(ctx, tt.opts)
+			require.NoError(t, err)
+
+			var names []string
+			for _, category := range page.Items {
+				names = append(names, category.Name)
+			}
+			assert.Equal(t, tt.wantNames, names)
+			assert.Equal(t, tt.wantTotal, page.TotalItems)
+			assert.Equal(t, tt.wantNext, page.NextCursor != "")
+		})
+	}
+
+	t.Run("list rejects unknown sort fields", func(t *testing.T) {
+		_, err := repo.ListAllCategories(ctx, domain.ListOptions{Sort: "secret"})
+		assert.ErrorIs(t, err, domain.ErrValidation)
+	})
+
+	t.Run("list by type pages with a cursor", func(t *testing.T) {
+		var types []string
+		opts := domain.ListOptions{Sort: "type", PageSize: 1}
+		for {
+			page, err := repo.ListAllCategories(ctx, opts)
+			require.NoError(t, err)
+			for _, category := range page.Items {
+				types = append(types, category.Type)
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			opts.Cursor = page.NextCursor
+		}
+		// Types are compared as text, so the order is alphabetical rather than that of the enum
+		assert.Equal(t, []string{"location", "office", "office", "user"}, types)
+	})
+
+	t.Run("list by creation time keeps rows without one", func(t *testing.T) {
+		_, err := tx.Exec(ctx, `UPDATE z_category SET created_at = NULL WHERE name = 'home'`)
+		require.NoError(t, err)
+
+		var names []string
+		opts := domain.ListOptions{Sort: "createdAt", PageSize: 1}
+		for {
+			page, err := repo.ListAllCategories(ctx, opts)
+			require.NoError(t, err)
+			for _, category := range page.Items {
+				names = append(names, category.Name)
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			opts.Cursor = page.NextCursor
+		}
+		require.Len(t, names, 4)
+		assert.Equal(t, "home", names[0])
+	})
+
+	// Runs last because the failed insert aborts the transaction
+	t.Run("add rejects an unknown type", func(t *testing.T) {
+		assert.Error(t, repo.AddCategory(ctx, "warehouse", "warehouse"))
+	})
+}
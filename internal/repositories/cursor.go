@@ -0,0 +1,32 @@
+package repositories
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded
+var ErrInvalidCursor = domain.NewValidationError("invalid cursor")
+
+// encodeCursor packs the keyset values of the last row of a page into an opaque token
+func encodeCursor(values ...string) string {
+	raw, _ := json.Marshal(values)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCursor unpacks a token created by encodeCursor, expecting exactly n values
+func decodeCursor(cursor string, n int) ([]string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var values []string
+	if err := json.Unmarshal(raw, &values); err != nil || len(values) != n {
+		return nil, ErrInvalidCursor
+	}
+
+	return values, nil
+}
@@ -3,13 +3,14 @@ package repositories
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/afreedicp/zolaris-backend-app/internal/domain"
@@ -25,9 +26,10 @@ type SensorDataDBModel struct {
 
 // DeviceRepository handles all device-related database operations
 type DeviceRepository struct {
-	pgPool       *pgxpool.Pool    // PostgreSQL connection pool for device data
+	pgPool       DBTX             // PostgreSQL connection pool (or transaction) for device data
 	dynamoClient *dynamodb.Client // DynamoDB client for sensor data
 	machineTable string           // DynamoDB table for sensor readings
+	reads        ReadRouter       // Optional router for read-only device listings
 }
 
 // NewDeviceRepository creates a new device repository instance
@@ -39,12 +41,26 @@ func NewDeviceRepository(pgPool *pgxpool.Pool, dynamoClient *dynamodb.Client) *D
 	}
 }
 
+// WithTx returns a copy of the repository that runs its PostgreSQL queries on tx
+func (r *DeviceRepository) WithTx(tx pgx.Tx) *DeviceRepository {
+	txRepo := *r
+	txRepo.pgPool = tx
+	txRepo.reads = nil
+	return &txRepo
+}
+
 // WithMachineTable sets the machine data table name for the repository
 func (r *DeviceRepository) WithMachineTable(machineTable string) *DeviceRepository {
 	r.machineTable = machineTable
 	return r
 }
 
+// WithReadRouter routes the device listing queries of the repository through router
+func (r *DeviceRepository) WithReadRouter(router ReadRouter) *DeviceRepository {
+	r.reads = router
+	return r
+}
+
 // AddDevice adds a new device to the PostgreSQL database
 func (r *DeviceRepository) AddDevice(ctx context.Context, deviceID, deviceName, userID string) error {
 	query := `
@@ -73,27 +89,99 @@ func (r *DeviceRepository) AddDevice(ctx context.Context, deviceID, deviceName,
 	return nil
 }
 
-// GetDevicesByUserID retrieves all devices for a specific user from PostgreSQL
-func (r *DeviceRepository) GetDevicesByUserID(ctx context.Context, userID string) ([]*domain.Device, error) {
+// deviceListSpec whitelists the sort and filter fields of device lists
+var deviceListSpec = listSpec{
+	sortFields: map[string]sortField{
+		"name":      {column: "d.device_name", cast: "text"},
+		"createdAt": {column: "COALESCE(d.created_at, " + missingTime + ")", cast: "timestamptz"},
+	},
+	defaultSort: "name",
+	tiebreaker:  "d.mac_address",
+	filterFields: map[string]string{
+		"category": "d.category",
+		"entityId": "d.entity_id::text",
+	},
+}
+
+// GetDevicesByUserID retrieves one page of the devices of a specific user from PostgreSQL
+func (r *DeviceRepository) GetDevicesByUserID(ctx context.Context, userID string, opts domain.ListOptions) (*domain.Page[*domain.Device], error) {
+	db := readDB(r.pgPool, r.reads)
+
+	q, err := newListQuery(deviceListSpec, opts)
+	if err != nil {
+		return nil, err
+	}
+	q.where("d.user_id = " + q.arg(userID))
+
+	from := `FROM z_device d`
+
+	var total int64
+	if err := db.QueryRow(ctx, q.countSQL(from), q.countArgs()...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count devices: %w", err)
+	}
+
+	query, args := q.pageSQL(`d.mac_address, d.user_id, d.entity_id, d.device_name, d.category, d.description, COALESCE(d.created_at, `+missingTime+`), d.updated_at`, from)
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	var devices []*domain.Device
+	for rows.Next() {
+		device := &domain.Device{}
+		err := rows.Scan(
+			&device.MacAddress,
+			&device.UserID,
+			&device.EntityID,
+			&device.Name,
+			&device.Category,
+			&device.Description,
+			&device.CreatedAt,
+			&device.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning device row: %w", err)
+		}
+
+		devices = append(devices, device)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating device rows: %w", err)
+	}
+
+	return newPage(q, devices, total, func(device *domain.Device, sortName string) (string, string) {
+		if sortName == "createdAt" {
+			return device.CreatedAt.Format(time.RFC3339Nano), device.MacAddress
+		}
+		return device.Name, device.MacAddress
+	}), nil
+}
+
+// GetDevicesInSubtree retrieves all devices assigned to the entity at rootPath or any of its descendants
+func (r *DeviceRepository) GetDevicesInSubtree(ctx context.Context, rootPath string) ([]*domain.Device, error) {
 	query := `
-		SELECT mac_address, user_id, device_name, category, description, created_at, updated_at
-		FROM z_device
-		WHERE user_id = $1
-		ORDER BY device_name
+		SELECT d.mac_address, d.user_id, d.entity_id, d.device_name, d.category, d.description, d.created_at, d.updated_at
+		FROM z_device d
+		JOIN z_entity e ON d.entity_id = e.entity_id
+		WHERE e.path <@ $1::ltree
+		ORDER BY e.path, d.device_name
 	`
 
-	rows, err := r.pgPool.Query(ctx, query, userID)
+	rows, err := readDB(r.pgPool, r.reads).Query(ctx, query, rootPath)
 	if err != nil {
 		return nil, fmt.Errorf("database error: %w", err)
 	}
 	defer rows.Close()
 
-	var devices []*domain.Device
+	devices := make([]*domain.Device, 0)
 	for rows.Next() {
 		device := &domain.Device{}
 		err := rows.Scan(
 			&device.MacAddress,
 			&device.UserID,
+			&device.EntityID,
 			&device.Name,
 			&device.Category,
 			&device.Description,
@@ -114,10 +202,38 @@ func (r *DeviceRepository) GetDevicesByUserID(ctx context.Context, userID string
 	return devices, nil
 }
 
+// GetDeviceOwners returns the owning user ID of each of the given devices that is already registered
+func (r *DeviceRepository) GetDeviceOwners(ctx context.Context, macAddresses []string) (map[string]string, error) {
+	owners := make(map[string]string)
+	if len(macAddresses) == 0 {
+		return owners, nil
+	}
+
+	query := `SELECT mac_address, user_id FROM z_device WHERE mac_address = ANY($1)`
+
+	rows, err := r.pgPool.Query(ctx, query, macAddresses)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var macAddress, userID string
+		if err := rows.Scan(&macAddress, &userID); err != nil {
+			return nil, fmt.Errorf("error scanning device row: %w", err)
+		}
+		owners[macAddress] = userID
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating device rows: %w", err)
+	}
+
+	return owners, nil
+}
+
 // GetSensorData retrieves sensor data from DynamoDB for a specific device within a time range
 func (r *DeviceRepository) GetSensorData(ctx context.Context, macID string, startTime, endTime int64) ([]*domain.SensorReading, error) {
-	log.Printf("Table name: %s", r.machineTable)
-
 	input := &dynamodb.QueryInput{
 		TableName:              aws.String(r.machineTable),
 		KeyConditionExpression: aws.String("mac_id = :macId AND #ts BETWEEN :startTime AND :endTime"),
@@ -131,11 +247,11 @@ func (r *DeviceRepository) GetSensorData(ctx context.Context, macID string, star
 		},
 	}
 
-	log.Printf("Querying sensor data for device %s from %d to %d", macID, startTime, endTime)
+	slog.DebugContext(ctx, "Querying sensor data", "table", r.machineTable, "device", macID, "start", startTime, "end", endTime)
 
 	result, err := r.dynamoClient.Query(ctx, input)
 	if err != nil {
-		return nil, err
+		return nil, domain.NewUnavailableError(err, "failed to query sensor data")
 	}
 
 	var dbSensorData []SensorDataDBModel
@@ -0,0 +1,123 @@
+//go:build integration
+
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+	"github.com/afreedicp/zolaris-backend-app/internal/testutil/pgtest"
+)
+
+func TestDeviceRepository(t *testing.T) {
+	tx := pgtest.Tx(t)
+	repo := NewDeviceRepository(nil, nil).WithTx(tx)
+	ctx := context.Background()
+	tree := seedTree(t, tx)
+	other := seedUser(t, tx, nil)
+
+	devices := []struct {
+		mac      string
+		name     string
+		userID   string
+		entityID string
+	}{
+		{"AA:00:00:00:00:01", "Boiler", tree.user.ID, tree.desk},
+		{"AA:00:00:00:00:02", "Chiller", tree.user.ID, tree.beta},
+		{"AA:00:00:00:00:03", "Airco", tree.user.ID, ""},
+		{"AA:00:00:00:00:04", "Meter", other.ID, ""},
+	}
+	for _, d := range devices {
+		require.NoError(t, repo.AddDevice(ctx, d.mac, d.name, d.userID))
+		if d.entityID != "" {
+			_, err := tx.Exec(ctx, `UPDATE z_device SET entity_id = $1 WHERE mac_address = $2`, d.entityID, d.mac)
+			require.NoError(t, err)
+		}
+	}
+
+	t.Run("adding a known device renames it", func(t *testing.T) {
+		require.NoError(t, repo.AddDevice(ctx, "AA:00:00:00:00:03", "Air conditioner", tree.user.ID))
+
+		page, err := repo.GetDevicesByUserID(ctx, tree.user.ID, domain.ListOptions{})
+		require.NoError(t, err)
+
+		var names []string
+		for _, device := range page.Items {
+			names = append(names, device.Name)
+		}
+		assert.Equal(t, []string{"Air conditioner", "Boiler", "Chiller"}, names)
+	})
+
+	tests := []struct {
+		name      string
+		opts      domain.ListOptions
+		wantMACs  []string
+		wantTotal int64
+		wantNext  bool
+	}{
+		{"descending by name", domain.ListOptions{Sort: "-name"}, []string{"AA:00:00:00:00:02", "AA:00:00:00:00:01", "AA:00:00:00:00:03"}, 3, false},
+		{"filtered by entity", domain.ListOptions{Filters: map[string]string{"entityId": tree.desk}}, []string{"AA:00:00:00:00:01"}, 1, false},
+		{"first page", domain.ListOptions{Page: 1, PageSize: 2}, []string{"AA:00:00:00:00:03", "AA:00:00:00:00:01"}, 3, true},
+		{"second page", domain.ListOptions{Page: 2, PageSize: 2}, []string{"AA:00:00:00:00:02"}, 3, false},
+	}
+	for _, tt := range tests {
+		t.Run("list "+tt.name, func(t *testing.T) {
+			page, err := repo.GetDevicesByUserID(ctx, tree.user.ID, tt.opts)
+			require.NoError(t, err)
+
+			var macs []string
+			for _, device := range page.Items {
+				macs = append(macs, device.MacAddress)
+				assert.Equal(t, tree.user.ID, device.UserID)
+			}
+			assert.Equal(t, tt.wantMACs, macs)
+			assert.Equal(t, tt.wantTotal, page.TotalItems)
+			assert.Equal(t, tt.wantNext, page.NextCursor != "")
+		})
+	}
+
+	t.Run("list by cursor", func(t *testing.T) {
+		first, err := repo.GetDevicesByUserID(ctx, tree.user.ID, domain.ListOptions{PageSize: 2})
+		require.NoError(t, err)
+		require.NotEmpty(t, first.NextCursor)
+
+		second, err := repo.GetDevicesByUserID(ctx, tree.user.ID, domain.ListOptions{PageSize: 2, Cursor: first.NextCursor})
+		require.NoError(t, err)
+		require.Len(t, second.Items, 1)
+		assert.Equal(t, "AA:00:00:00:00:02", second.Items[0].MacAddress)
+		assert.Empty(t, second.NextCursor)
+	})
+
+	t.Run("subtree", func(t *testing.T) {
+		root, err := repo.GetDevicesInSubtree(ctx, tree.root)
+		require.NoError(t, err)
+
+		var macs []string
+		for _, device := range root {
+			macs = append(macs, device.MacAddress)
+		}
+		assert.ElementsMatch(t, []string{"AA:00:00:00:00:01", "AA:00:00:00:00:02"}, macs)
+
+		alpha, err := repo.GetDevicesInSubtree(ctx, tree.root+"."+tree.alpha)
+		require.NoError(t, err)
+		require.Len(t, alpha, 1)
+		assert.Equal(t, tree.desk, *alpha[0].EntityID)
+	})
+
+	t.Run("owners", func(t *testing.T) {
+		owners, err := repo.GetDeviceOwners(ctx, []string{"AA:00:00:00:00:01", "AA:00:00:00:00:04", "AA:00:00:00:00:99"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"AA:00:00:00:00:01": tree.user.ID,
+			"AA:00:00:00:00:04": other.ID,
+		}, owners)
+
+		owners, err = repo.GetDeviceOwners(ctx, nil)
+		require.NoError(t, err)
+		assert.Empty(t, owners)
+	})
+}
@@ -0,0 +1,133 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+)
+
+// EntityHistoryRepository reads the append-only history of entity changes.
+// History rows are written by a trigger on z_entity, so there are no write methods.
+type EntityHistoryRepository struct {
+	db    DBTX
+	reads ReadRouter
+}
+
+// NewEntityHistoryRepository creates a new entity history repository instance
+func NewEntityHistoryRepository(dbPool *pgxpool.Pool) *EntityHistoryRepository {
+	return &EntityHistoryRepository{
+		db: dbPool,
+	}
+}
+
+// WithTx returns a copy of the repository that runs its queries on tx
+func (r *EntityHistoryRepository) WithTx(tx pgx.Tx) *EntityHistoryRepository {
+	return &EntityHistoryRepository{
+		db: tx,
+	}
+}
+
+// WithReadRouter routes the history queries of the repository through router
+func (r *EntityHistoryRepository) WithReadRouter(router ReadRouter) *EntityHistoryRepository {
+	r.reads = router
+	return r
+}
+
+// ListEntityHistory returns the recorded changes of an entity, newest first
+func (r *EntityHistoryRepository) ListEntityHistory(ctx context.Context, entityId string, limit int) ([]*domain.EntityHistoryEntry, error) {
+	query := `
+		SELECT history_id, entity_id, action, actor_id, before, after, changed_at
+		FROM z_entity_history
+		WHERE entity_id = $1
+		ORDER BY changed_at DESC, history_id DESC
+		LIMIT $2
+	`
+
+	rows, err := readDB(r.db, r.reads).Query(ctx, query, entityId, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query entity history: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]*domain.EntityHistoryEntry, 0)
+	for rows.Next() {
+		entry := new(domain.EntityHistoryEntry)
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.EntityID,
+			&entry.Action,
+			&entry.ActorID,
+			&entry.Before,
+			&entry.After,
+			&entry.ChangedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan history row: %w", err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating history rows: %w", err)
+	}
+
+	return entries, nil
+}
+
+// GetSubtreeSnapshot reconstructs the subtree rooted at rootEntityId as it was at the given time.
+// The result is empty if the root did not exist then.
+//
+// Only the history of entities with a row recorded under the root's path is read, as
+// an entity is in the subtree when its latest row is there.
+func (r *EntityHistoryRepository) GetSubtreeSnapshot(ctx context.Context, rootEntityId string, at time.Time) ([]domain.EntitySnapshot, error) {
+	query := `
+		WITH root AS (
+			SELECT action, path
+			FROM z_entity_history
+			WHERE entity_id = $1 AND changed_at <= $2
+			ORDER BY changed_at DESC, history_id DESC
+			LIMIT 1
+		),
+		candidates AS (
+			SELECT DISTINCT h.entity_id
+			FROM z_entity_history h, root
+			WHERE root.action <> 'delete' AND h.path <@ root.path AND h.changed_at <= $2
+		),
+		latest AS (
+			SELECT DISTINCT ON (h.entity_id) h.entity_id, h.action, h.after, h.path
+			FROM z_entity_history h
+			WHERE h.entity_id IN (SELECT entity_id FROM candidates) AND h.changed_at <= $2
+			ORDER BY h.entity_id, h.changed_at DESC, h.history_id DESC
+		)
+		SELECT l.entity_id::text, l.after->>'name', l.after->>'parent_id', l.after->>'path'
+		FROM latest l, root
+		WHERE l.action <> 'delete' AND l.path <@ root.path
+		ORDER BY l.after->>'path'
+	`
+
+	rows, err := readDB(r.db, r.reads).Query(ctx, query, rootEntityId, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query subtree snapshot: %w", err)
+	}
+	defer rows.Close()
+
+	snapshot := make([]domain.EntitySnapshot, 0)
+	for rows.Next() {
+		var entity domain.EntitySnapshot
+		if err := rows.Scan(&entity.EntityID, &entity.Name, &entity.ParentID, &entity.Path); err != nil {
+			return nil, fmt.Errorf("failed to scan snapshot row: %w", err)
+		}
+		snapshot = append(snapshot, entity)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating snapshot rows: %w", err)
+	}
+
+	return snapshot, nil
+}
@@ -0,0 +1,100 @@
+//go:build integration
+
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+	"github.com/afreedicp/zolaris-backend-app/internal/testutil/pgtest"
+)
+
+func TestEntityHistoryTrigger(t *testing.T) {
+	tx := pgtest.Tx(t)
+	entities := NewEntityRepository(nil)
+	repo := entities.WithTx(tx)
+	history := NewEntityHistoryRepository(nil).WithTx(tx)
+	ctx := context.Background()
+	tree := seedTree(t, tx)
+
+	// Entities created through the repository record the acting user
+	entityID, err := repo.CreateSubEntity(ctx, tree.officeCategory, "Gamma", tree.user.ID, nil, tree.alpha)
+	require.NoError(t, err)
+
+	_, err = tx.Exec(ctx, `UPDATE z_entity SET name = 'Gamma 2' WHERE entity_id = $1`, entityID)
+	require.NoError(t, err)
+
+	// Touching only updated_at is not a change
+	_, err = tx.Exec(ctx, `UPDATE z_entity SET updated_at = now() + interval '1 second' WHERE entity_id = $1`, entityID)
+	require.NoError(t, err)
+
+	_, err = tx.Exec(ctx, `UPDATE z_entity SET parent_id = $1 WHERE entity_id = $2`, tree.beta, entityID)
+	require.NoError(t, err)
+
+	entries, err := history.ListEntityHistory(ctx, entityID, 10)
+	require.NoError(t, err)
+
+	var actions []string
+	for _, entry := range entries {
+		actions = append(actions, entry.Action)
+	}
+	assert.Equal(t, []string{domain.EntityActionMove, domain.EntityActionUpdate, domain.EntityActionCreate}, actions)
+
+	create := entries[2]
+	require.NotNil(t, create.ActorID)
+	assert.Equal(t, tree.user.ID, *create.ActorID)
+	assert.Nil(t, create.Before)
+
+	var after map[string]any
+	require.NoError(t, json.Unmarshal(entries[1].After, &after))
+	assert.Equal(t, "Gamma 2", after["name"])
+
+	limited, err := history.ListEntityHistory(ctx, entityID, 1)
+	require.NoError(t, err)
+	require.Len(t, limited, 1)
+	assert.Equal(t, domain.EntityActionMove, limited[0].Action)
+
+	t.Run("history rows are append-only", func(t *testing.T) {
+		sp, err := tx.Begin(ctx)
+		require.NoError(t, err)
+		defer sp.Rollback(ctx)
+
+		_, err = sp.Exec(ctx, `DELETE FROM z_entity_history WHERE entity_id = $1`, entityID)
+		assert.Error(t, err)
+	})
+}
+
+func TestEntityHistorySubtreeSnapshot(t *testing.T) {
+	tx := pgtest.Tx(t)
+	history := NewEntityHistoryRepository(nil).WithTx(tx)
+	ctx := context.Background()
+	tree := seedTree(t, tx)
+
+	// now() is fixed for the whole transaction, so every seeded row shares one timestamp
+	var seededAt time.Time
+	require.NoError(t, tx.QueryRow(ctx, `SELECT now()`).Scan(&seededAt))
+
+	t.Run("subtree at the time it was created", func(t *testing.T) {
+		snapshot, err := history.GetSubtreeSnapshot(ctx, tree.alpha, seededAt)
+		require.NoError(t, err)
+		require.Len(t, snapshot, 2)
+
+		assert.Equal(t, tree.alpha, snapshot[0].EntityID)
+		assert.Equal(t, "Alpha", snapshot[0].Name)
+		assert.Equal(t, tree.desk, snapshot[1].EntityID)
+		assert.Equal(t, tree.alpha, *snapshot[1].ParentID)
+		assert.Equal(t, tree.root+"."+tree.alpha+"."+tree.desk, snapshot[1].Path)
+	})
+
+	t.Run("before the root existed", func(t *testing.T) {
+		snapshot, err := history.GetSubtreeSnapshot(ctx, tree.alpha, seededAt.Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Empty(t, snapshot)
+	})
+}
@@ -3,8 +3,12 @@ package repositories
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
+
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
@@ -20,15 +24,46 @@ const (
 )
 
 type EntityRepository struct {
-	db *pgxpool.Pool
+	db    DBTX
+	reads ReadRouter
 }
 
-func NewEntityRepository(dbPool *pgxpool.Pool) EntityRepository {
-	return EntityRepository{
+func NewEntityRepository(dbPool *pgxpool.Pool) *EntityRepository {
+	return &EntityRepository{
 		db: dbPool,
 	}
 }
 
+// WithTx returns a copy of the repository that runs its queries on tx
+func (r *EntityRepository) WithTx(tx pgx.Tx) EntityRepositoryInterface {
+	return &EntityRepository{
+		db: tx,
+	}
+}
+
+// WithReadRouter routes the hierarchy, listing and search queries of the repository through router
+func (r *EntityRepository) WithReadRouter(router ReadRouter) EntityRepositoryInterface {
+	r.reads = router
+	return r
+}
+
+// Primary returns a copy of the repository that runs every query on the primary,
+// for reads that must see a write made just before
+func (r *EntityRepository) Primary() EntityRepositoryInterface {
+	return &EntityRepository{
+		db: r.db,
+	}
+}
+
+// WithMaxLag returns a copy of the repository whose hierarchy, listing and search
+// queries only use the replica while it lags at most maxLag
+func (r *EntityRepository) WithMaxLag(maxLag time.Duration) EntityRepositoryInterface {
+	return &EntityRepository{
+		db:    r.db,
+		reads: withMaxLag(r.reads, maxLag),
+	}
+}
+
 func (r *EntityRepository) CheckEntityPresence(ctx context.Context, userId string) (bool, error) {
 	var exists bool
 	query := `select exists(select 1 from z_entity where user_id = $1)`
@@ -46,7 +81,7 @@ func (r *EntityRepository) GetCategoryType(ctx context.Context, categoryId strin
 	err := r.db.QueryRow(ctx, query, categoryId).Scan(&categoryType) // Fixed: scanning into categoryType instead of categoryId
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return "", fmt.Errorf("category with ID %s not found", categoryId)
+			return "", domain.NewNotFoundError("category with ID %s not found", categoryId)
 		}
 		return "", fmt.Errorf("failed to get category type: %w", err)
 	}
@@ -59,7 +94,7 @@ func (r *EntityRepository) GetCategoryIDByEntityID(ctx context.Context, entityID
 	err := r.db.QueryRow(ctx, query, entityID).Scan(&categoryID)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return "", fmt.Errorf("entity with ID %s not found", entityID)
+			return "", domain.NewNotFoundError("entity with ID %s not found", entityID)
 		}
 		return "", fmt.Errorf("failed to get category ID: %w", err)
 	}
@@ -75,7 +110,17 @@ func (r *EntityRepository) CreateRootEntity(ctx context.Context, categoryId stri
 	var entityId string
 
 	if categoryType == "" {
-		return "", fmt.Errorf("category with ID %s not found", categoryId)
+		return "", domain.NewNotFoundError("category with ID %s not found", categoryId)
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := setHistoryActor(ctx, tx, userId); err != nil {
+		return "", err
 	}
 
 	if categoryType == UserCategoryType {
@@ -85,7 +130,7 @@ func (r *EntityRepository) CreateRootEntity(ctx context.Context, categoryId stri
 
 		query := `insert into z_entity (category_id, name, user_id) values ($1, $2, $3) returning entity_id`
 
-		err = r.db.QueryRow(ctx, query, categoryId, entityName, userId).Scan(&entityId)
+		err = tx.QueryRow(ctx, query, categoryId, entityName, userId).Scan(&entityId)
 	} else {
 		detailsJSON, jsonErr := json.Marshal(details)
 		if jsonErr != nil {
@@ -93,13 +138,17 @@ func (r *EntityRepository) CreateRootEntity(ctx context.Context, categoryId stri
 		}
 
 		query := `insert into z_entity (category_id, name, details) values ($1, $2, $3) returning entity_id`
-		err = r.db.QueryRow(ctx, query, categoryId, entityName, detailsJSON).Scan(&entityId)
+		err = tx.QueryRow(ctx, query, categoryId, entityName, detailsJSON).Scan(&entityId)
 	}
 
 	if err != nil {
 		return "", fmt.Errorf("failed to create root entity: %w", err)
 	}
 
+	if err := tx.Commit(ctx); err != nil {
+		return "", fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return entityId, nil
 }
 
@@ -110,6 +159,10 @@ func (r *EntityRepository) CreateSubEntity(ctx context.Context, categoryId strin
 	}
 	defer tx.Rollback(ctx)
 
+	if err := setHistoryActor(ctx, tx, userId); err != nil {
+		return "", err
+	}
+
 	if parentEntityId == "" {
 		getParentEntityIDQuery := `select entity_id from z_entity where user_id = $1 limit 1`
 		err = tx.QueryRow(ctx, getParentEntityIDQuery, userId).Scan(&parentEntityId)
@@ -118,7 +171,8 @@ func (r *EntityRepository) CreateSubEntity(ctx context.Context, categoryId strin
 		}
 	}
 
-	categoryType, err := r.GetCategoryType(ctx, categoryId)
+	txRepo := r.WithTx(tx)
+	categoryType, err := txRepo.GetCategoryType(ctx, categoryId)
 	if err != nil {
 		return "", err
 	}
@@ -137,7 +191,7 @@ func (r *EntityRepository) CreateSubEntity(ctx context.Context, categoryId strin
 		}
 
 		if !userHasEntity {
-			return "", fmt.Errorf("user with ID %s does not have any existing entities", userId)
+			return "", domain.NewValidationError("user with ID %s does not have any existing entities", userId)
 		}
 
 		if categoryType == UserCategoryType {
@@ -169,23 +223,25 @@ func (r *EntityRepository) CreateSubEntity(ctx context.Context, categoryId strin
 // GetChildEntities retrieves all direct child entities of a given entity.
 // If recursive is true, returns all descendants (children, grandchildren, etc.)
 func (r *EntityRepository) GetChildEntities(ctx context.Context, entityId string, recursive bool) ([]*domain.Entity, error) {
+	db := readDB(r.db, r.reads)
+
 	// First check if the parent entity exists
 	var exists bool
 	checkEntityQuery := `SELECT EXISTS(SELECT 1 FROM z_entity WHERE entity_id = $1)`
 
-	if err := r.db.QueryRow(ctx, checkEntityQuery, entityId).Scan(&exists); err != nil {
+	if err := db.QueryRow(ctx, checkEntityQuery, entityId).Scan(&exists); err != nil {
 		return nil, fmt.Errorf("failed to check entity existence: %w", err)
 	}
 
 	if !exists {
-		return nil, fmt.Errorf("entity with ID %s not found", entityId)
+		return nil, domain.NewNotFoundError("entity with ID %s not found", entityId)
 	}
 
 	// Get the path of the parent entity
 	var parentPath string
 	pathQuery := `SELECT path::text FROM z_entity WHERE entity_id = $1`
 
-	if err := r.db.QueryRow(ctx, pathQuery, entityId).Scan(&parentPath); err != nil {
+	if err := db.QueryRow(ctx, pathQuery, entityId).Scan(&parentPath); err != nil {
 		return nil, fmt.Errorf("failed to get entity path: %w", err)
 	}
 
@@ -225,9 +281,9 @@ func (r *EntityRepository) GetChildEntities(ctx context.Context, entityId string
 	var err error
 
 	if recursive {
-		rows, err = r.db.Query(ctx, query, parentPath, entityId)
+		rows, err = db.Query(ctx, query, parentPath, entityId)
 	} else {
-		rows, err = r.db.Query(ctx, query, entityId)
+		rows, err = db.Query(ctx, query, entityId)
 	}
 
 	if err != nil {
@@ -265,279 +321,478 @@ func (r *EntityRepository) GetChildEntities(ctx context.Context, entityId string
 	return entities, nil
 }
 
-// GetEntityHierarchy retrieves an entity and all its descendant entities as a hierarchy
-// This method provides a structured view of the entity tree with proper parent-child relationships
-func (r *EntityRepository) GetEntityHierarchy(ctx context.Context, rootEntityId string) (map[string]any, error) {
-	// First check if the root entity exists
-	var exists bool
-	checkEntityQuery := `SELECT EXISTS(SELECT 1 FROM z_entity WHERE entity_id = $1)`
+// HierarchyOptions controls how much of an entity subtree GetEntityHierarchy loads
+type HierarchyOptions struct {
+	// MaxDepth is the number of levels below the root to include
+	MaxDepth int
+	// Limit is the maximum number of direct children of the root to return
+	Limit int
+	// Cursor resumes listing the root's direct children after a previous page
+	Cursor string
+}
 
-	if err := r.db.QueryRow(ctx, checkEntityQuery, rootEntityId).Scan(&exists); err != nil {
-		return nil, fmt.Errorf("failed to check entity existence: %w", err)
+// entityNodeColumns is the select list shared by queries returning domain.EntityNode rows.
+// child_count lets clients expand nodes lazily when their children were not loaded.
+const entityNodeColumns = `
+	e.entity_id, e.user_id, e.name, e.details, e.category_id,
+	c.name, c.type::text, e.parent_id, e.path::text, e.depth,
+	e.created_at, e.updated_at,
+	(SELECT count(*) FROM z_entity ch WHERE ch.parent_id = e.entity_id)
+`
+
+// GetEntityHierarchy retrieves an entity and its descendants as a hierarchy.
+// Only opts.MaxDepth levels below the root are loaded, and the root's direct children
+// are paged by name using opts.Limit and opts.Cursor.
+func (r *EntityRepository) GetEntityHierarchy(ctx context.Context, rootEntityId string, opts HierarchyOptions) (*domain.EntityHierarchy, error) {
+	// Load the root entity
+	rootQuery := `SELECT ` + entityNodeColumns + `
+		FROM z_entity e
+		JOIN z_category c ON e.category_id = c.category_id
+		WHERE e.entity_id = $1
+	`
+
+	root, err := scanEntityNode(readDB(r.db, r.reads).QueryRow(ctx, rootQuery, rootEntityId))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewNotFoundError("entity with ID %s not found", rootEntityId)
+		}
+		return nil, fmt.Errorf("failed to get root entity: %w", err)
 	}
 
-	if !exists {
-		return nil, fmt.Errorf("entity with ID %s not found", rootEntityId)
+	hierarchy := &domain.EntityHierarchy{Root: root}
+	if opts.MaxDepth < 1 || root.ChildCount == 0 {
+		return hierarchy, nil
 	}
 
-	// Get all entities in the hierarchy using recursive CTE
-	query := `
-		WITH RECURSIVE entity_hierarchy AS (
-			-- Base case: the root entity
-			SELECT 
-				e.entity_id, 
-				e.user_id, 
-				e.name, 
-				e.details, 
-				e.category_id,
-				c.name AS category_name,
-				c.type AS category_type,
-				e.parent_id, 
-				e.path::text,
-				e.depth, 
-				e.created_at, 
-				e.updated_at
-			FROM 
-				z_entity e
-				JOIN z_category c ON e.category_id = c.category_id
-			WHERE 
-				e.entity_id = $1
-				
-			UNION ALL
-			
-			-- Recursive case: find all children
-			SELECT 
-				child.entity_id, 
-				child.user_id, 
-				child.name, 
-				child.details, 
-				child.category_id,
-				c.name AS category_name,
-				c.type AS category_type,
-				child.parent_id, 
-				child.path::text,
-				child.depth, 
-				child.created_at, 
-				child.updated_at
-			FROM 
-				z_entity child
-				JOIN entity_hierarchy parent ON child.parent_id = parent.entity_id
-				JOIN z_category c ON child.category_id = c.category_id
-		)
-		SELECT 
-			entity_id, 
-			user_id, 
-			name, 
-			details, 
-			category_id,
-			category_name,
-			category_type,
-			parent_id, 
-			path,
-			depth, 
-			created_at, 
-			updated_at
-		FROM 
-			entity_hierarchy
-		ORDER BY 
-			depth, name
+	// Page through the direct children of the root, fetching one extra row to detect a next page
+	args := []any{rootEntityId, opts.Limit + 1}
+	childrenQuery := `SELECT ` + entityNodeColumns + `
+		FROM z_entity e
+		JOIN z_category c ON e.category_id = c.category_id
+		WHERE e.parent_id = $1
 	`
+	if opts.Cursor != "" {
+		values, err := decodeCursor(opts.Cursor, 2)
+		if err != nil {
+			return nil, err
+		}
+		childrenQuery += ` AND (e.name, e.entity_id) > ($3, $4::uuid)`
+		args = append(args, values[0], values[1])
+	}
+	childrenQuery += ` ORDER BY e.name, e.entity_id LIMIT $2`
 
-	rows, err := r.db.Query(ctx, query, rootEntityId)
+	children, err := r.queryEntityNodes(ctx, childrenQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query child entities: %w", err)
+	}
+
+	if len(children) > opts.Limit {
+		children = children[:opts.Limit]
+		last := children[len(children)-1]
+		hierarchy.NextCursor = encodeCursor(last.Name, last.ID)
+	}
+	root.Children = children
+
+	if opts.MaxDepth < 2 || len(children) == 0 {
+		return hierarchy, nil
+	}
+
+	// Load the deeper levels below the paged children, bounded by the requested depth
+	childPaths := make([]string, len(children))
+	for i, child := range children {
+		childPaths[i] = child.Path
+	}
+
+	descendantsQuery := `SELECT ` + entityNodeColumns + `
+		FROM z_entity e
+		JOIN z_category c ON e.category_id = c.category_id
+		WHERE e.path <@ $1::text[]::ltree[]
+			AND e.depth > $2
+			AND e.depth <= $3
+		ORDER BY e.depth, e.name, e.entity_id
+	`
+
+	descendants, err := r.queryEntityNodes(ctx, descendantsQuery, childPaths, root.Depth+1, root.Depth+opts.MaxDepth)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query entity hierarchy: %w", err)
 	}
-	defer rows.Close()
 
-	// Store all entities to build the hierarchy
-	allEntities := make(map[string]map[string]any)
-	var rootEntity map[string]any
+	// Rows are ordered by depth, so every parent is indexed before its children arrive
+	nodes := make(map[string]*domain.EntityNode, len(children)+len(descendants))
+	for _, child := range children {
+		nodes[child.ID] = child
+	}
+	for _, node := range descendants {
+		nodes[node.ID] = node
+		if node.ParentID == nil {
+			continue
+		}
+		if parent, ok := nodes[*node.ParentID]; ok {
+			parent.Children = append(parent.Children, node)
+		}
+	}
 
-	// Build a flat map of all entities
-	for rows.Next() {
-		var (
-			entityId     string
-			userId       *string
-			name         string
-			details      []byte
-			categoryId   string
-			categoryName string
-			categoryType string
-			parentId     *string
-			path         string
-			depth        int
-			createdAt    time.Time
-			updatedAt    time.Time
+	return hierarchy, nil
+}
+
+// GetEntityAncestors returns the ancestors of an entity ordered from the root down,
+// optionally ending with the entity itself
+func (r *EntityRepository) GetEntityAncestors(ctx context.Context, entityId string, includeSelf bool) ([]*domain.EntityNode, error) {
+	var path string
+	if err := readDB(r.db, r.reads).QueryRow(ctx, `SELECT path::text FROM z_entity WHERE entity_id = $1`, entityId).Scan(&path); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewNotFoundError("entity with ID %s not found", entityId)
+		}
+		return nil, fmt.Errorf("failed to get entity path: %w", err)
+	}
+
+	query := `SELECT ` + entityNodeColumns + `
+		FROM z_entity e
+		JOIN z_category c ON e.category_id = c.category_id
+		WHERE e.path @> $1::ltree
+			AND ($2 OR e.entity_id != $3)
+		ORDER BY e.depth
+	`
+
+	ancestors, err := r.queryEntityNodes(ctx, query, path, includeSelf, entityId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query entity ancestors: %w", err)
+	}
+
+	return ancestors, nil
+}
+
+// EntitySearchFilter narrows an entity search; empty fields are ignored
+type EntitySearchFilter struct {
+	// UserID restricts results to the subtrees rooted at the entities owned by this user
+	UserID       string
+	NamePrefix   string
+	CategoryID   string
+	CategoryType string
+	// Details matches entities whose details contain all of these key/value pairs
+	Details map[string]any
+	Limit   int
+}
+
+// SearchEntities finds entities within the user's accessible subtrees, shallowest first
+func (r *EntityRepository) SearchEntities(ctx context.Context, filter EntitySearchFilter) ([]*domain.EntityNode, error) {
+	args := []any{filter.UserID}
+	query := `SELECT ` + entityNodeColumns + `
+		FROM z_entity e
+		JOIN z_category c ON e.category_id = c.category_id
+		WHERE EXISTS (
+			SELECT 1 FROM z_entity owned
+			WHERE owned.user_id = $1 AND e.path <@ owned.path
 		)
+	`
 
-		if err := rows.Scan(
-			&entityId,
-			&userId,
-			&name,
-			&details,
-			&categoryId,
-			&categoryName,
-			&categoryType,
-			&parentId,
-			&path,
-			&depth,
-			&createdAt,
-			&updatedAt,
-		); err != nil {
-			return nil, fmt.Errorf("failed to scan entity row: %w", err)
+	if filter.NamePrefix != "" {
+		args = append(args, escapeLike(filter.NamePrefix)+"%")
+		query += fmt.Sprintf(" AND lower(e.name) LIKE lower($%d)", len(args))
+	}
+
+	if filter.CategoryID != "" {
+		args = append(args, filter.CategoryID)
+		query += fmt.Sprintf(" AND e.category_id = $%d", len(args))
+	}
+
+	if filter.CategoryType != "" {
+		args = append(args, filter.CategoryType)
+		query += fmt.Sprintf(" AND c.type::text = $%d", len(args))
+	}
+
+	if len(filter.Details) > 0 {
+		detailsJSON, err := json.Marshal(filter.Details)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal details filter: %w", err)
 		}
+		args = append(args, detailsJSON)
+		query += fmt.Sprintf(" AND e.details @> $%d::jsonb", len(args))
+	}
+
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" ORDER BY e.depth, e.name, e.entity_id LIMIT $%d", len(args))
+
+	results, err := r.queryEntityNodes(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search entities: %w", err)
+	}
 
-		// Create entity object
-		entity := make(map[string]any)
-		entity["id"] = entityId
-		entity["name"] = name
-		entity["category_id"] = categoryId
-		entity["category_name"] = categoryName
-		entity["category_type"] = categoryType
-		entity["path"] = path
-		entity["depth"] = depth
-		entity["created_at"] = createdAt
-		entity["updated_at"] = updatedAt
-		entity["children"] = []map[string]any{} // Will be populated later
-
-		// Add optional fields
-		if userId != nil {
-			entity["user_id"] = *userId
+	return results, nil
+}
+
+// setHistoryActor records the acting user for the entity history trigger until the transaction ends
+func setHistoryActor(ctx context.Context, tx pgx.Tx, userId string) error {
+	if userId == "" {
+		return nil
+	}
+
+	if _, err := tx.Exec(ctx, `SELECT set_config('zolaris.actor_id', $1, true)`, userId); err != nil {
+		return fmt.Errorf("failed to set history actor: %w", err)
+	}
+
+	return nil
+}
+
+// escapeLike escapes the LIKE wildcards in a user supplied pattern fragment
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// IsEntityAccessible reports whether the entity lies within a subtree rooted at an entity owned by the user
+func (r *EntityRepository) IsEntityAccessible(ctx context.Context, userId string, entityId string) (bool, error) {
+	var accessible bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM z_entity e
+			JOIN z_entity owned ON e.path <@ owned.path
+			WHERE e.entity_id = $1 AND owned.user_id = $2
+		)
+	`
+
+	if err := r.db.QueryRow(ctx, query, entityId, userId).Scan(&accessible); err != nil {
+		return false, fmt.Errorf("failed to check entity access: %w", err)
+	}
+
+	return accessible, nil
+}
+
+// GetCategoryTypes returns the type of each of the given categories that exists
+func (r *EntityRepository) GetCategoryTypes(ctx context.Context, categoryIds []string) (map[string]CategoryType, error) {
+	types := make(map[string]CategoryType)
+	if len(categoryIds) == 0 {
+		return types, nil
+	}
+
+	query := `SELECT category_id::text, type::text FROM z_category WHERE category_id::text = ANY($1)`
+
+	rows, err := r.db.Query(ctx, query, categoryIds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query category types: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var categoryId, categoryType string
+		if err := rows.Scan(&categoryId, &categoryType); err != nil {
+			return nil, fmt.Errorf("failed to scan category row: %w", err)
 		}
+		types[categoryId] = CategoryType(categoryType)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating category rows: %w", err)
+	}
+
+	return types, nil
+}
 
-		if parentId != nil {
-			entity["parent_id"] = *parentId
+// GetSubtree returns an entity and all of its descendants, ordered so that parents precede children
+func (r *EntityRepository) GetSubtree(ctx context.Context, rootEntityId string) ([]*domain.EntityNode, error) {
+	query := `SELECT ` + entityNodeColumns + `
+		FROM z_entity e
+		JOIN z_category c ON e.category_id = c.category_id
+		WHERE e.path <@ (SELECT path FROM z_entity WHERE entity_id = $1)
+		ORDER BY e.depth, e.name, e.entity_id
+	`
+
+	nodes, err := r.queryEntityNodes(ctx, query, rootEntityId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query entity subtree: %w", err)
+	}
+
+	if len(nodes) == 0 {
+		return nil, domain.NewNotFoundError("entity with ID %s not found", rootEntityId)
+	}
+
+	return nodes, nil
+}
+
+// ImportEntityRows creates the entities and assigns the devices of a validated import under parentEntityId.
+// Rows must be ordered so that every entity row precedes the rows referencing it.
+// Everything is written in a single transaction, which is rolled back instead of committed when dryRun is set.
+// It returns the created entity IDs keyed by row reference and the number of devices written.
+func (r *EntityRepository) ImportEntityRows(ctx context.Context, userId string, parentEntityId string, rows []domain.EntityImportRow, dryRun bool) (map[string]string, int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := setHistoryActor(ctx, tx, userId); err != nil {
+		return nil, 0, err
+	}
+
+	entityIds := make(map[string]string)
+	devices := 0
+
+	entityQuery := `INSERT INTO z_entity (category_id, parent_id, name, details) VALUES ($1, $2, $3, $4) RETURNING entity_id`
+	deviceQuery := `
+		INSERT INTO z_device (mac_address, user_id, entity_id, device_name, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		ON CONFLICT (mac_address) DO UPDATE SET
+			entity_id = EXCLUDED.entity_id,
+			device_name = EXCLUDED.device_name,
+			updated_at = NOW()
+		WHERE z_device.user_id = EXCLUDED.user_id
+	`
+
+	for _, row := range rows {
+		parentId := parentEntityId
+		if row.ParentRef != "" {
+			parentId = entityIds[row.ParentRef]
 		}
 
-		// Parse details JSON if present
-		if len(details) > 0 && string(details) != "null" {
-			var detailsMap map[string]any
-			if err := json.Unmarshal(details, &detailsMap); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal details JSON: %w", err)
+		switch row.Kind {
+		case domain.ImportKindEntity:
+			details := row.Details
+			if details == nil {
+				details = map[string]any{}
+			}
+			detailsJSON, err := json.Marshal(details)
+			if err != nil {
+				return nil, 0, fmt.Errorf("row %d: failed to marshal details: %w", row.Row, err)
 			}
-			entity["details"] = detailsMap
-		} else {
-			entity["details"] = map[string]any{}
-		}
 
-		// Store entity in the map
-		allEntities[entityId] = entity
+			var entityId string
+			if err := tx.QueryRow(ctx, entityQuery, row.CategoryID, parentId, row.Name, detailsJSON).Scan(&entityId); err != nil {
+				return nil, 0, fmt.Errorf("row %d: failed to create entity: %w", row.Row, err)
+			}
+			entityIds[row.Ref] = entityId
 
-		// If this is the root entity, save a reference
-		if entityId == rootEntityId {
-			rootEntity = entity
+		case domain.ImportKindDevice:
+			result, err := tx.Exec(ctx, deviceQuery, row.DeviceID, userId, parentId, row.Name)
+			if err != nil {
+				return nil, 0, fmt.Errorf("row %d: failed to import device: %w", row.Row, err)
+			}
+			if result.RowsAffected() == 0 {
+				return nil, 0, fmt.Errorf("row %d: device %s is registered to another user", row.Row, row.DeviceID)
+			}
+			devices++
 		}
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating entity rows: %w", err)
+	if dryRun {
+		return entityIds, devices, nil
 	}
 
-	// If no root entity was found, something went wrong
-	if rootEntity == nil {
-		return nil, fmt.Errorf("root entity not found in query results")
+	if err := tx.Commit(ctx); err != nil {
+		return nil, 0, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	// Build the hierarchy - for each entity, add it to its parent's children array
-	for _, entity := range allEntities {
-		parentId, hasParent := entity["parent_id"]
-		if hasParent && parentId != nil {
-			// Check if this entity is not the root entity itself
-			if entity["id"].(string) != rootEntityId {
-				if parent, exists := allEntities[parentId.(string)]; exists {
-					children := parent["children"].([]map[string]any)
-					parent["children"] = append(children, entity)
-				}
-			}
+	return entityIds, devices, nil
+}
+
+// queryEntityNodes runs a query selecting entityNodeColumns and scans every row
+func (r *EntityRepository) queryEntityNodes(ctx context.Context, query string, args ...any) ([]*domain.EntityNode, error) {
+	rows, err := readDB(r.db, r.reads).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	nodes := make([]*domain.EntityNode, 0)
+	for rows.Next() {
+		node, err := scanEntityNode(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan entity row: %w", err)
 		}
+		nodes = append(nodes, node)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating entity rows: %w", err)
 	}
 
-	return rootEntity, nil
+	return nodes, nil
 }
 
-// ListEntityChildren lists all children of a given entity with optional filtering
-// level: 0 for direct children only, -1 for all descendants, or specific depth (1, 2, 3, etc.)
-// categoryType: filter by category type (optional)
-func (r *EntityRepository) ListEntityChildren(ctx context.Context, entityId string, level int, categoryType string) ([]*domain.Entity, error) {
+// scanEntityNode scans a row selected with entityNodeColumns
+func scanEntityNode(row pgx.Row) (*domain.EntityNode, error) {
+	node := new(domain.EntityNode)
+	if err := row.Scan(
+		&node.ID,
+		&node.UserID,
+		&node.Name,
+		&node.Details,
+		&node.CategoryID,
+		&node.CategoryName,
+		&node.CategoryType,
+		&node.ParentID,
+		&node.Path,
+		&node.Depth,
+		&node.CreatedAt,
+		&node.UpdatedAt,
+		&node.ChildCount,
+	); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// entityListSpec whitelists the sort and filter fields of entity lists
+var entityListSpec = listSpec{
+	sortFields: map[string]sortField{
+		"name":      {column: "e.name", cast: "text"},
+		"depth":     {column: "e.depth", cast: "int"},
+		"createdAt": {column: "COALESCE(e.created_at, " + missingTime + ")", cast: "timestamptz"},
+	},
+	defaultSort: "name",
+	tiebreaker:  "e.entity_id::text",
+	filterFields: map[string]string{
+		"categoryType": "c.type",
+		"categoryId":   "e.category_id::text",
+	},
+}
+
+// ListEntityChildren lists one page of the descendants of an entity.
+// level 0 returns direct children only, a positive level returns descendants up
+// to that many levels below the entity and -1 returns all descendants.
+func (r *EntityRepository) ListEntityChildren(ctx context.Context, entityId string, level int, opts domain.ListOptions) (*domain.Page[*domain.Entity], error) {
+	db := readDB(r.db, r.reads)
+
 	// First check if the entity exists
 	var exists bool
 	checkEntityQuery := `SELECT EXISTS(SELECT 1 FROM z_entity WHERE entity_id = $1)`
 
-	if err := r.db.QueryRow(ctx, checkEntityQuery, entityId).Scan(&exists); err != nil {
+	if err := db.QueryRow(ctx, checkEntityQuery, entityId).Scan(&exists); err != nil {
 		return nil, fmt.Errorf("failed to check entity existence: %w", err)
 	}
 
 	if !exists {
-		return nil, fmt.Errorf("entity with ID %s not found", entityId)
+		return nil, domain.NewNotFoundError("entity with ID %s not found", entityId)
 	}
 
-	// Build the query based on parameters
-	var args []any
-	args = append(args, entityId)
-
-	// Base query
-	query := `
-		WITH RECURSIVE entity_children AS (
-			-- Base case: direct children of the entity
-			SELECT 
-				e.entity_id, e.user_id, e.name, e.details, e.category_id, 
-				e.parent_id, e.path::text, e.depth, e.created_at, e.updated_at,
-				c.type as category_type, 
-				1 as level
-			FROM 
-				z_entity e
-				JOIN z_category c ON e.category_id = c.category_id
-			WHERE 
-				e.parent_id = $1
-	`
-
-	// Add category filter if provided
-	if categoryType != "" {
-		query += ` AND c.type = $2`
-		args = append(args, categoryType)
+	q, err := newListQuery(entityListSpec, opts)
+	if err != nil {
+		return nil, err
 	}
-
-	// Continue recursive query
-	query += `
-			UNION ALL
-			
-			-- Recursive case: children of children
-			SELECT 
-				child.entity_id, child.user_id, child.name, child.details, child.category_id, 
-				child.parent_id, child.path::text, child.depth, child.created_at, child.updated_at,
-				c.type as category_type,
-				parent.level + 1
-			FROM 
-				z_entity child
-				JOIN entity_children parent ON child.parent_id = parent.entity_id
-				JOIN z_category c ON child.category_id = c.category_id
-	`
-
-	// Apply level filter for recursive case if specified
-	if level > 0 {
-		query += fmt.Sprintf(" WHERE parent.level < %d", level)
+	q.where("e.path <@ p.path")
+	q.where("e.entity_id <> p.entity_id")
+	switch {
+	case level == 0:
+		q.where("e.depth = p.depth + 1")
+	case level > 0:
+		q.where("e.depth <= p.depth + " + q.arg(level))
 	}
 
-	// Close the CTE and add final selection
-	query += `
-		)
-		SELECT 
-			entity_id, user_id, name, details, category_id, 
-			parent_id, path, depth, created_at, updated_at
-		FROM 
-			entity_children
-	`
+	from := `
+		FROM z_entity e
+		JOIN z_category c ON c.category_id = e.category_id
+		JOIN z_entity p ON p.entity_id = ` + q.arg(entityId)
 
-	// Add category filter for the final selection if provided
-	if categoryType != "" {
-		query += ` WHERE category_type = $2`
+	var total int64
+	if err := db.QueryRow(ctx, q.countSQL(from), q.countArgs()...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count entity children: %w", err)
 	}
 
-	// Add ordering
-	query += ` ORDER BY depth, name`
+	query, args := q.pageSQL(`
+		e.entity_id, e.user_id, e.name, e.details, e.category_id,
+		e.parent_id, e.path::text, e.depth, COALESCE(e.created_at, `+missingTime+`), e.updated_at`, from)
 
 	// Execute query
-	rows, err := r.db.Query(ctx, query, args...)
+	rows, err := db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query entity children: %w", err)
 	}
@@ -572,7 +827,15 @@ func (r *EntityRepository) ListEntityChildren(ctx context.Context, entityId stri
 		return nil, fmt.Errorf("error iterating entity rows: %w", err)
 	}
 
-	return entities, nil
+	return newPage(q, entities, total, func(entity *domain.Entity, sortName string) (string, string) {
+		switch sortName {
+		case "depth":
+			return strconv.Itoa(entity.Depth), entity.ID
+		case "createdAt":
+			return entity.CreatedAt.Format(time.RFC3339Nano), entity.ID
+		}
+		return entity.Name, entity.ID
+	}), nil
 }
 
 func (r *EntityRepository) GetEntityID(ctx context.Context, userId string) (string, error) {
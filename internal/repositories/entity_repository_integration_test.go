@@ -0,0 +1,470 @@
+//go:build integration
+
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+	"github.com/afreedicp/zolaris-backend-app/internal/testutil/pgtest"
+)
+
+func TestEntityPathTrigger(t *testing.T) {
+	tx := pgtest.Tx(t)
+	ctx := context.Background()
+	tree := seedTree(t, tx)
+
+	tests := []struct {
+		name      string
+		entityID  string
+		wantPath  string
+		wantDepth int
+	}{
+		{"root", tree.root, tree.root, 1},
+		{"child", tree.alpha, tree.root + "." + tree.alpha, 2},
+		{"grandchild", tree.desk, tree.root + "." + tree.alpha + "." + tree.desk, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var path string
+			var depth int
+			err := tx.QueryRow(ctx, `SELECT path::text, depth FROM z_entity WHERE entity_id = $1`, tt.entityID).Scan(&path, &depth)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPath, path)
+			assert.Equal(t, tt.wantDepth, depth)
+		})
+	}
+
+	t.Run("moving an entity recomputes its path", func(t *testing.T) {
+		_, err := tx.Exec(ctx, `UPDATE z_entity SET parent_id = $1 WHERE entity_id = $2`, tree.beta, tree.desk)
+		require.NoError(t, err)
+
+		var path string
+		var depth int
+		err = tx.QueryRow(ctx, `SELECT path::text, depth FROM z_entity WHERE entity_id = $1`, tree.desk).Scan(&path, &depth)
+		require.NoError(t, err)
+		assert.Equal(t, tree.root+"."+tree.beta+"."+tree.desk, path)
+		assert.Equal(t, 3, depth)
+	})
+}
+
+func TestEntityRepositoryCreateRootEntity(t *testing.T) {
+	tx := pgtest.Tx(t)
+	base := NewEntityRepository(nil)
+	repo := base.WithTx(tx)
+	ctx := context.Background()
+
+	user := seedUser(t, tx, nil)
+	userCategory := seedCategory(t, tx, "User", UserCategoryType)
+	officeCategory := seedCategory(t, tx, "Office", OfficeCategoryType)
+
+	tests := []struct {
+		name       string
+		categoryID string
+		userID     string
+		details    map[string]any
+		wantErr    bool
+		wantErrIs  error
+		wantUser   bool
+	}{
+		{name: "user entity", categoryID: userCategory, userID: user.ID, wantUser: true},
+		{name: "office entity", categoryID: officeCategory, details: map[string]any{"floor": float64(3)}},
+		{name: "user entity without user", categoryID: userCategory, wantErr: true},
+		{name: "unknown category", categoryID: uuid.New().String(), userID: user.ID, wantErr: true, wantErrIs: domain.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entityID, err := repo.CreateRootEntity(ctx, tt.categoryID, tt.name, tt.userID, tt.details)
+			if tt.wantErr {
+				require.Error(t, err)
+				if tt.wantErrIs != nil {
+					assert.ErrorIs(t, err, tt.wantErrIs)
+				}
+				return
+			}
+			require.NoError(t, err)
+
+			entities, err := repo.GetSubtree(ctx, entityID)
+			require.NoError(t, err)
+			require.Len(t, entities, 1)
+
+			root := entities[0]
+			assert.Equal(t, tt.name, root.Name)
+			assert.Nil(t, root.ParentID)
+			assert.Equal(t, 1, root.Depth)
+			assert.Equal(t, entityID, root.Path)
+			if tt.wantUser {
+				require.NotNil(t, root.UserID)
+				assert.Equal(t, tt.userID, *root.UserID)
+			} else {
+				var details map[string]any
+				require.NoError(t, json.Unmarshal(root.Details, &details))
+				assert.Equal(t, tt.details, details)
+			}
+		})
+	}
+
+	present, err := repo.CheckEntityPresence(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, present)
+
+	entityID, err := repo.GetEntityID(ctx, user.ID)
+	require.NoError(t, err)
+
+	categoryID, err := repo.GetCategoryIDByEntityID(ctx, entityID)
+	require.NoError(t, err)
+	assert.Equal(t, userCategory, categoryID)
+}
+
+func TestEntityRepositoryCreateSubEntity(t *testing.T) {
+	tx := pgtest.Tx(t)
+	base := NewEntityRepository(nil)
+	repo := base.WithTx(tx)
+	ctx := context.Background()
+	tree := seedTree(t, tx)
+	stranger := seedUser(t, tx, nil)
+
+	tests := []struct {
+		name       string
+		categoryID string
+		userID     string
+		parentID   string
+		wantErr    error
+		wantParent string
+	}{
+		{name: "defaults to the user's entity", categoryID: tree.officeCategory, userID: tree.user.ID, wantParent: tree.root},
+		{name: "explicit parent", categoryID: tree.locationCategory, userID: tree.user.ID, parentID: tree.beta, wantParent: tree.beta},
+		{name: "user without entities", categoryID: tree.officeCategory, userID: stranger.ID, parentID: tree.beta, wantErr: domain.ErrValidation},
+		{name: "unknown category", categoryID: uuid.New().String(), userID: tree.user.ID, parentID: tree.beta, wantErr: domain.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entityID, err := repo.CreateSubEntity(ctx, tt.categoryID, tt.name, tt.userID, nil, tt.parentID)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			ancestors, err := repo.GetEntityAncestors(ctx, entityID, false)
+			require.NoError(t, err)
+			require.NotEmpty(t, ancestors)
+			assert.Equal(t, tt.wantParent, ancestors[len(ancestors)-1].ID)
+		})
+	}
+}
+
+func TestEntityRepositoryGetChildEntities(t *testing.T) {
+	tx := pgtest.Tx(t)
+	base := NewEntityRepository(nil)
+	repo := base.WithTx(tx)
+	ctx := context.Background()
+	tree := seedTree(t, tx)
+
+	tests := []struct {
+		name      string
+		entityID  string
+		recursive bool
+		want      []string
+		wantErr   error
+	}{
+		{"direct children", tree.root, false, []string{tree.alpha, tree.beta}, nil},
+		{"all descendants", tree.root, true, []string{tree.alpha, tree.desk, tree.beta}, nil},
+		{"leaf", tree.desk, true, []string{}, nil},
+		{"unknown entity", uuid.New().String(), false, nil, domain.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			children, err := repo.GetChildEntities(ctx, tt.entityID, tt.recursive)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			if tt.recursive {
+				assert.ElementsMatch(t, tt.want, entityIDs(children))
+			} else {
+				assert.Equal(t, tt.want, entityIDs(children))
+			}
+		})
+	}
+}
+
+func TestEntityRepositoryGetEntityHierarchy(t *testing.T) {
+	tx := pgtest.Tx(t)
+	base := NewEntityRepository(nil)
+	repo := base.WithTx(tx)
+	ctx := context.Background()
+	tree := seedTree(t, tx)
+
+	t.Run("depth limits the loaded levels", func(t *testing.T) {
+		hierarchy, err := repo.GetEntityHierarchy(ctx, tree.root, HierarchyOptions{MaxDepth: 1, Limit: 10})
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, hierarchy.Root.ChildCount)
+		require.Equal(t, []string{tree.alpha, tree.beta}, nodeIDs(hierarchy.Root.Children))
+		assert.Empty(t, hierarchy.Root.Children[0].Children)
+		assert.Equal(t, 1, hierarchy.Root.Children[0].ChildCount)
+		assert.Empty(t, hierarchy.NextCursor)
+	})
+
+	t.Run("depth 0 loads the root alone", func(t *testing.T) {
+		hierarchy, err := repo.GetEntityHierarchy(ctx, tree.root, HierarchyOptions{MaxDepth: 0, Limit: 10})
+		require.NoError(t, err)
+
+		assert.Equal(t, tree.root, hierarchy.Root.ID)
+		assert.Equal(t, 2, hierarchy.Root.ChildCount)
+		assert.Empty(t, hierarchy.Root.Children)
+	})
+
+	t.Run("deeper levels are nested under their parents", func(t *testing.T) {
+		hierarchy, err := repo.GetEntityHierarchy(ctx, tree.root, HierarchyOptions{MaxDepth: 2, Limit: 10})
+		require.NoError(t, err)
+
+		alpha := hierarchy.Root.Children[0]
+		require.Equal(t, []string{tree.desk}, nodeIDs(alpha.Children))
+		assert.Equal(t, "location", alpha.Children[0].CategoryType)
+	})
+
+	t.Run("children are paged by cursor", func(t *testing.T) {
+		first, err := repo.GetEntityHierarchy(ctx, tree.root, HierarchyOptions{MaxDepth: 1, Limit: 1})
+		require.NoError(t, err)
+		require.Equal(t, []string{tree.alpha}, nodeIDs(first.Root.Children))
+		require.NotEmpty(t, first.NextCursor)
+
+		second, err := repo.GetEntityHierarchy(ctx, tree.root, HierarchyOptions{MaxDepth: 1, Limit: 1, Cursor: first.NextCursor})
+		require.NoError(t, err)
+		assert.Equal(t, []string{tree.beta}, nodeIDs(second.Root.Children))
+		assert.Empty(t, second.NextCursor)
+	})
+
+	t.Run("unknown entity", func(t *testing.T) {
+		_, err := repo.GetEntityHierarchy(ctx, uuid.New().String(), HierarchyOptions{MaxDepth: 1, Limit: 10})
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
+
+func TestEntityRepositoryGetEntityAncestors(t *testing.T) {
+	tx := pgtest.Tx(t)
+	base := NewEntityRepository(nil)
+	repo := base.WithTx(tx)
+	ctx := context.Background()
+	tree := seedTree(t, tx)
+
+	tests := []struct {
+		name        string
+		entityID    string
+		includeSelf bool
+		want        []string
+		wantErr     error
+	}{
+		{"excluding self", tree.desk, false, []string{tree.root, tree.alpha}, nil},
+		{"including self", tree.desk, true, []string{tree.root, tree.alpha, tree.desk}, nil},
+		{"root has none", tree.root, false, []string{}, nil},
+		{"unknown entity", uuid.New().String(), false, nil, domain.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ancestors, err := repo.GetEntityAncestors(ctx, tt.entityID, tt.includeSelf)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, nodeIDs(ancestors))
+		})
+	}
+}
+
+func TestEntityRepositorySearchEntities(t *testing.T) {
+	tx := pgtest.Tx(t)
+	base := NewEntityRepository(nil)
+	repo := base.WithTx(tx)
+	ctx := context.Background()
+	tree := seedTree(t, tx)
+
+	// A second tree with a matching name that belongs to someone else
+	other := seedTree(t, tx)
+
+	_, err := tx.Exec(ctx, `UPDATE z_entity SET details = '{"floor": 2}' WHERE entity_id = $1`, tree.beta)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		filter EntitySearchFilter
+		want   []string
+	}{
+		{"name prefix is case insensitive", EntitySearchFilter{NamePrefix: "al"}, []string{tree.alpha}},
+		{"category type", EntitySearchFilter{CategoryType: "office"}, []string{tree.alpha, tree.beta}},
+		{"category ID", EntitySearchFilter{CategoryID: tree.locationCategory}, []string{tree.desk}},
+		{"details", EntitySearchFilter{Details: map[string]any{"floor": 2}}, []string{tree.beta}},
+		{"wildcards are literal", EntitySearchFilter{NamePrefix: "%"}, []string{}},
+		{"shallowest first", EntitySearchFilter{Limit: 2}, []string{tree.root, tree.alpha}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := tt.filter
+			filter.UserID = tree.user.ID
+			if filter.Limit == 0 {
+				filter.Limit = 10
+			}
+
+			results, err := repo.SearchEntities(ctx, filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, nodeIDs(results))
+			assert.NotContains(t, nodeIDs(results), other.alpha)
+		})
+	}
+}
+
+func TestEntityRepositoryAccessAndCategories(t *testing.T) {
+	tx := pgtest.Tx(t)
+	base := NewEntityRepository(nil)
+	repo := base.WithTx(tx)
+	ctx := context.Background()
+	tree := seedTree(t, tx)
+	other := seedTree(t, tx)
+
+	tests := []struct {
+		name     string
+		userID   string
+		entityID string
+		want     bool
+	}{
+		{"own root", tree.user.ID, tree.root, true},
+		{"own descendant", tree.user.ID, tree.desk, true},
+		{"other user's entity", tree.user.ID, other.alpha, false},
+		{"unknown entity", tree.user.ID, uuid.New().String(), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accessible, err := repo.IsEntityAccessible(ctx, tt.userID, tt.entityID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, accessible)
+		})
+	}
+
+	t.Run("category types", func(t *testing.T) {
+		types, err := repo.GetCategoryTypes(ctx, []string{tree.userCategory, tree.officeCategory, uuid.New().String()})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]CategoryType{
+			tree.userCategory:   UserCategoryType,
+			tree.officeCategory: OfficeCategoryType,
+		}, types)
+
+		categoryType, err := repo.GetCategoryType(ctx, tree.locationCategory)
+		require.NoError(t, err)
+		assert.Equal(t, LocationCategoryType, categoryType)
+
+		_, err = repo.GetCategoryType(ctx, uuid.New().String())
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+
+	t.Run("subtree", func(t *testing.T) {
+		nodes, err := repo.GetSubtree(ctx, tree.alpha)
+		require.NoError(t, err)
+		assert.Equal(t, []string{tree.alpha, tree.desk}, nodeIDs(nodes))
+
+		_, err = repo.GetSubtree(ctx, uuid.New().String())
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
+
+func TestEntityRepositoryImportEntityRows(t *testing.T) {
+	tx := pgtest.Tx(t)
+	base := NewEntityRepository(nil)
+	repo := base.WithTx(tx)
+	ctx := context.Background()
+	tree := seedTree(t, tx)
+	other := seedTree(t, tx)
+
+	rows := []domain.EntityImportRow{
+		{Row: 1, Kind: domain.ImportKindEntity, Ref: "floor", Name: "Floor 1", CategoryID: tree.officeCategory},
+		{Row: 2, Kind: domain.ImportKindEntity, Ref: "room", ParentRef: "floor", Name: "Room 101", CategoryID: tree.locationCategory, Details: map[string]any{"seats": 4}},
+		{Row: 3, Kind: domain.ImportKindDevice, ParentRef: "room", Name: "Sensor", DeviceID: "AA:BB:CC:00:00:01"},
+	}
+
+	t.Run("dry run persists nothing", func(t *testing.T) {
+		ids, devices, err := repo.ImportEntityRows(ctx, tree.user.ID, tree.beta, rows, true)
+		require.NoError(t, err)
+		assert.Len(t, ids, 2)
+		assert.Equal(t, 1, devices)
+
+		children, err := repo.GetChildEntities(ctx, tree.beta, true)
+		require.NoError(t, err)
+		assert.Empty(t, children)
+	})
+
+	t.Run("import creates the tree", func(t *testing.T) {
+		ids, devices, err := repo.ImportEntityRows(ctx, tree.user.ID, tree.beta, rows, false)
+		require.NoError(t, err)
+		assert.Equal(t, 1, devices)
+
+		nodes, err := repo.GetSubtree(ctx, tree.beta)
+		require.NoError(t, err)
+		assert.Equal(t, []string{tree.beta, ids["floor"], ids["room"]}, nodeIDs(nodes))
+		assert.Equal(t, 4, nodes[2].Depth)
+
+		var deviceEntity string
+		err = tx.QueryRow(ctx, `SELECT entity_id::text FROM z_device WHERE mac_address = $1`, "AA:BB:CC:00:00:01").Scan(&deviceEntity)
+		require.NoError(t, err)
+		assert.Equal(t, ids["room"], deviceEntity)
+	})
+
+	t.Run("devices of another user are rejected", func(t *testing.T) {
+		device := []domain.EntityImportRow{
+			{Row: 1, Kind: domain.ImportKindDevice, Name: "Stolen", DeviceID: "AA:BB:CC:00:00:01"},
+		}
+		_, _, err := repo.ImportEntityRows(ctx, other.user.ID, other.beta, device, false)
+		assert.Error(t, err)
+
+		var owner string
+		err = tx.QueryRow(ctx, `SELECT user_id::text FROM z_device WHERE mac_address = $1`, "AA:BB:CC:00:00:01").Scan(&owner)
+		require.NoError(t, err)
+		assert.Equal(t, tree.user.ID, owner)
+	})
+}
+
+func TestEntityRepositoryListEntityChildren(t *testing.T) {
+	tx := pgtest.Tx(t)
+	base := NewEntityRepository(nil)
+	repo := base.WithTx(tx)
+	ctx := context.Background()
+	tree := seedTree(t, tx)
+
+	tests := []struct {
+		name      string
+		level     int
+		opts      domain.ListOptions
+		want      []string
+		wantTotal int64
+		wantNext  bool
+	}{
+		{"direct children", 0, domain.ListOptions{}, []string{tree.alpha, tree.beta}, 2, false},
+		{"one level", 1, domain.ListOptions{}, []string{tree.alpha, tree.beta}, 2, false},
+		{"two levels", 2, domain.ListOptions{}, []string{tree.alpha, tree.beta, tree.desk}, 3, false},
+		{"all descendants", -1, domain.ListOptions{Sort: "-depth", PageSize: 1}, []string{tree.desk}, 3, true},
+		{"filtered by category type", -1, domain.ListOptions{Filters: map[string]string{"categoryType": "location"}}, []string{tree.desk}, 1, false},
+		{"second page", -1, domain.ListOptions{Page: 2, PageSize: 2}, []string{tree.desk}, 3, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := repo.ListEntityChildren(ctx, tree.root, tt.level, tt.opts)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, entityIDs(page.Items))
+			assert.Equal(t, tt.wantTotal, page.TotalItems)
+			assert.Equal(t, tt.wantNext, page.NextCursor != "")
+		})
+	}
+
+	t.Run("unknown entity", func(t *testing.T) {
+		_, err := repo.ListEntityChildren(ctx, uuid.New().String(), 0, domain.ListOptions{})
+		assert.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
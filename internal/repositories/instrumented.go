@@ -0,0 +1,391 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+	"github.com/afreedicp/zolaris-backend-app/internal/tracing"
+)
+
+// CallObserver records the duration and outcome of every repository call.
+// It is implemented by metrics.Metrics.
+type CallObserver interface {
+	ObserveRepositoryCall(repository, method string, duration time.Duration, err error)
+}
+
+// observe times call, reports it to o and traces it in a span that is the
+// parent of the queries made with the context passed to call
+func observe[T any](ctx context.Context, o CallObserver, repository, method string, call func(ctx context.Context) (T, error)) (T, error) {
+	ctx, span := tracing.Start(ctx, repository+"."+method, trace.WithAttributes(
+		attribute.String("repository", repository),
+		attribute.String("repository.method", method),
+	))
+	start := time.Now()
+	result, err := call(ctx)
+	o.ObserveRepositoryCall(repository, method, time.Since(start), err)
+	// A missing row is an answer, not a failure
+	if errors.Is(err, domain.ErrNotFound) {
+		span.End()
+	} else {
+		tracing.End(span, err)
+	}
+	return result, err
+}
+
+// observeErr is observe for a call that only returns an error
+func observeErr(ctx context.Context, o CallObserver, repository, method string, call func(ctx context.Context) error) error {
+	_, err := observe(ctx, o, repository, method, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, call(ctx)
+	})
+	return err
+}
+
+// InstrumentUserRepository reports the calls of repo to o and traces them
+func InstrumentUserRepository(repo UserRepositoryInterface, o CallObserver) UserRepositoryInterface {
+	return &instrumentedUserRepository{next: repo, observer: o}
+}
+
+type instrumentedUserRepository struct {
+	next     UserRepositoryInterface
+	observer CallObserver
+}
+
+func (r *instrumentedUserRepository) GetUserIdByCognitoId(ctx context.Context, cId string) (string, error) {
+	return observe(ctx, r.observer, "user", "GetUserIdByCognitoId", func(ctx context.Context) (string, error) {
+		return r.next.GetUserIdByCognitoId(ctx, cId)
+	})
+}
+
+func (r *instrumentedUserRepository) GetUserByID(ctx context.Context, userID string) (*domain.User, error) {
+	return observe(ctx, r.observer, "user", "GetUserByID", func(ctx context.Context) (*domain.User, error) {
+		return r.next.GetUserByID(ctx, userID)
+	})
+}
+
+func (r *instrumentedUserRepository) CreateUser(ctx context.Context, user *domain.User) error {
+	return observeErr(ctx, r.observer, "user", "CreateUser", func(ctx context.Context) error {
+		return r.next.CreateUser(ctx, user)
+	})
+}
+
+func (r *instrumentedUserRepository) UpdateUser(ctx context.Context, user *domain.User) error {
+	return observeErr(ctx, r.observer, "user", "UpdateUser", func(ctx context.Context) error {
+		return r.next.UpdateUser(ctx, user)
+	})
+}
+
+func (r *instrumentedUserRepository) CheckHasParentID(ctx context.Context, userID string) (bool, error) {
+	return observe(ctx, r.observer, "user", "CheckHasParentID", func(ctx context.Context) (bool, error) {
+		return r.next.CheckHasParentID(ctx, userID)
+	})
+}
+
+func (r *instrumentedUserRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return observe(ctx, r.observer, "user", "GetUserByEmail", func(ctx context.Context) (*domain.User, error) {
+		return r.next.GetUserByEmail(ctx, email)
+	})
+}
+
+func (r *instrumentedUserRepository) GetChildUsers(ctx context.Context, parentID string) ([]*domain.User, error) {
+	return observe(ctx, r.observer, "user", "GetChildUsers", func(ctx context.Context) ([]*domain.User, error) {
+		return r.next.GetChildUsers(ctx, parentID)
+	})
+}
+
+func (r *instrumentedUserRepository) ListReferredUsers(ctx context.Context, userID string, opts domain.ListOptions) (*domain.Page[*domain.User], error) {
+	return observe(ctx, r.observer, "user", "ListReferredUsers", func(ctx context.Context) (*domain.Page[*domain.User], error) {
+		return r.next.ListReferredUsers(ctx, userID, opts)
+	})
+}
+
+func (r *instrumentedUserRepository) UpdateUserParentID(ctx context.Context, userID string, parentID *string) error {
+	return observeErr(ctx, r.observer, "user", "UpdateUserParentID", func(ctx context.Context) error {
+		return r.next.UpdateUserParentID(ctx, userID, parentID)
+	})
+}
+
+func (r *instrumentedUserRepository) WithTx(tx pgx.Tx) UserRepositoryInterface {
+	return InstrumentUserRepository(r.next.WithTx(tx), r.observer)
+}
+
+func (r *instrumentedUserRepository) WithReadRouter(router ReadRouter) UserRepositoryInterface {
+	return InstrumentUserRepository(r.next.WithReadRouter(router), r.observer)
+}
+
+// InstrumentEntityRepository reports the calls of repo to o and traces them
+func InstrumentEntityRepository(repo EntityRepositoryInterface, o CallObserver) EntityRepositoryInterface {
+	return &instrumentedEntityRepository{next: repo, observer: o}
+}
+
+type instrumentedEntityRepository struct {
+	next     EntityRepositoryInterface
+	observer CallObserver
+}
+
+func (r *instrumentedEntityRepository) CheckEntityPresence(ctx context.Context, userId string) (bool, error) {
+	return observe(ctx, r.observer, "entity", "CheckEntityPresence", func(ctx context.Context) (bool, error) {
+		return r.next.CheckEntityPresence(ctx, userId)
+	})
+}
+
+func (r *instrumentedEntityRepository) GetCategoryType(ctx context.Context, categoryId string) (CategoryType, error) {
+	return observe(ctx, r.observer, "entity", "GetCategoryType", func(ctx context.Context) (CategoryType, error) {
+		return r.next.GetCategoryType(ctx, categoryId)
+	})
+}
+
+func (r *instrumentedEntityRepository) GetCategoryTypes(ctx context.Context, categoryIds []string) (map[string]CategoryType, error) {
+	return observe(ctx, r.observer, "entity", "GetCategoryTypes", func(ctx context.Context) (map[string]CategoryType, error) {
+		return r.next.GetCategoryTypes(ctx, categoryIds)
+	})
+}
+
+func (r *instrumentedEntityRepository) GetCategoryIDByEntityID(ctx context.Context, entityID string) (string, error) {
+	return observe(ctx, r.observer, "entity", "GetCategoryIDByEntityID", func(ctx context.Context) (string, error) {
+		return r.next.GetCategoryIDByEntityID(ctx, entityID)
+	})
+}
+
+func (r *instrumentedEntityRepository) CreateRootEntity(ctx context.Context, categoryId string, entityName string, userId string, details map[string]any) (string, error) {
+	return observe(ctx, r.observer, "entity", "CreateRootEntity", func(ctx context.Context) (string, error) {
+		return r.next.CreateRootEntity(ctx, categoryId, entityName, userId, details)
+	})
+}
+
+func (r *instrumentedEntityRepository) CreateSubEntity(ctx context.Context, categoryId string, entityName string, userId string, details map[string]any, parentEntityId string) (string, error) {
+	return observe(ctx, r.observer, "entity", "CreateSubEntity", func(ctx context.Context) (string, error) {
+		return r.next.CreateSubEntity(ctx, categoryId, entityName, userId, details, parentEntityId)
+	})
+}
+
+func (r *instrumentedEntityRepository) GetChildEntities(ctx context.Context, entityId string, recursive bool) ([]*domain.Entity, error) {
+	return observe(ctx, r.observer, "entity", "GetChildEntities", func(ctx context.Context) ([]*domain.Entity, error) {
+		return r.next.GetChildEntities(ctx, entityId, recursive)
+	})
+}
+
+func (r *instrumentedEntityRepository) GetEntityHierarchy(ctx context.Context, rootEntityId string, opts HierarchyOptions) (*domain.EntityHierarchy, error) {
+	return observe(ctx, r.observer, "entity", "GetEntityHierarchy", func(ctx context.Context) (*domain.EntityHierarchy, error) {
+		return r.next.GetEntityHierarchy(ctx, rootEntityId, opts)
+	})
+}
+
+func (r *instrumentedEntityRepository) GetEntityAncestors(ctx context.Context, entityId string, includeSelf bool) ([]*domain.EntityNode, error) {
+	return observe(ctx, r.observer, "entity", "GetEntityAncestors", func(ctx context.Context) ([]*domain.EntityNode, error) {
+		return r.next.GetEntityAncestors(ctx, entityId, includeSelf)
+	})
+}
+
+func (r *instrumentedEntityRepository) SearchEntities(ctx context.Context, filter EntitySearchFilter) ([]*domain.EntityNode, error) {
+	return observe(ctx, r.observer, "entity", "SearchEntities", func(ctx context.Context) ([]*domain.EntityNode, error) {
+		return r.next.SearchEntities(ctx, filter)
+	})
+}
+
+func (r *instrumentedEntityRepository) IsEntityAccessible(ctx context.Context, userId string, entityId string) (bool, error) {
+	return observe(ctx, r.observer, "entity", "IsEntityAccessible", func(ctx context.Context) (bool, error) {
+		return r.next.IsEntityAccessible(ctx, userId, entityId)
+	})
+}
+
+func (r *instrumentedEntityRepository) GetSubtree(ctx context.Context, rootEntityId string) ([]*domain.EntityNode, error) {
+	return observe(ctx, r.observer, "entity", "GetSubtree", func(ctx context.Context) ([]*domain.EntityNode, error) {
+		return r.next.GetSubtree(ctx, rootEntityId)
+	})
+}
+
+func (r *instrumentedEntityRepository) ImportEntityRows(ctx context.Context, userId string, parentEntityId string, rows []domain.EntityImportRow, dryRun bool) (map[string]string, int, error) {
+	var devices int
+	entityIds, err := observe(ctx, r.observer, "entity", "ImportEntityRows", func(ctx context.Context) (map[string]string, error) {
+		entityIds, n, err := r.next.ImportEntityRows(ctx, userId, parentEntityId, rows, dryRun)
+		devices = n
+		return entityIds, err
+	})
+	return entityIds, devices, err
+}
+
+func (r *instrumentedEntityRepository) ListEntityChildren(ctx context.Context, entityId string, level int, opts domain.ListOptions) (*domain.Page[*domain.Entity], error) {
+	return observe(ctx, r.observer, "entity", "ListEntityChildren", func(ctx context.Context) (*domain.Page[*domain.Entity], error) {
+		return r.next.ListEntityChildren(ctx, entityId, level, opts)
+	})
+}
+
+func (r *instrumentedEntityRepository) GetEntityID(ctx context.Context, userId string) (string, error) {
+	return observe(ctx, r.observer, "entity", "GetEntityID", func(ctx context.Context) (string, error) {
+		return r.next.GetEntityID(ctx, userId)
+	})
+}
+
+func (r *instrumentedEntityRepository) WithTx(tx pgx.Tx) EntityRepositoryInterface {
+	return InstrumentEntityRepository(r.next.WithTx(tx), r.observer)
+}
+
+func (r *instrumentedEntityRepository) WithReadRouter(router ReadRouter) EntityRepositoryInterface {
+	return InstrumentEntityRepository(r.next.WithReadRouter(router), r.observer)
+}
+
+func (r *instrumentedEntityRepository) Primary() EntityRepositoryInterface {
+	return InstrumentEntityRepository(r.next.Primary(), r.observer)
+}
+
+func (r *instrumentedEntityRepository) WithMaxLag(maxLag time.Duration) EntityRepositoryInterface {
+	return InstrumentEntityRepository(r.next.WithMaxLag(maxLag), r.observer)
+}
+
+// InstrumentDeviceRepository reports the calls of repo to o and traces them
+func InstrumentDeviceRepository(repo DeviceRepositoryInterface, o CallObserver) DeviceRepositoryInterface {
+	return &instrumentedDeviceRepository{next: repo, observer: o}
+}
+
+type instrumentedDeviceRepository struct {
+	next     DeviceRepositoryInterface
+	observer CallObserver
+}
+
+func (r *instrumentedDeviceRepository) AddDevice(ctx context.Context, deviceID, deviceName, userID string) error {
+	return observeErr(ctx, r.observer, "device", "AddDevice", func(ctx context.Context) error {
+		return r.next.AddDevice(ctx, deviceID, deviceName, userID)
+	})
+}
+
+func (r *instrumentedDeviceRepository) GetDevicesByUserID(ctx context.Context, userID string, opts domain.ListOptions) (*domain.Page[*domain.Device], error) {
+	return observe(ctx, r.observer, "device", "GetDevicesByUserID", func(ctx context.Context) (*domain.Page[*domain.Device], error) {
+		return r.next.GetDevicesByUserID(ctx, userID, opts)
+	})
+}
+
+func (r *instrumentedDeviceRepository) GetDevicesInSubtree(ctx context.Context, rootPath string) ([]*domain.Device, error) {
+	return observe(ctx, r.observer, "device", "GetDevicesInSubtree", func(ctx context.Context) ([]*domain.Device, error) {
+		return r.next.GetDevicesInSubtree(ctx, rootPath)
+	})
+}
+
+func (r *instrumentedDeviceRepository) GetDeviceOwners(ctx context.Context, macAddresses []string) (map[string]string, error) {
+	return observe(ctx, r.observer, "device", "GetDeviceOwners", func(ctx context.Context) (map[string]string, error) {
+		return r.next.GetDeviceOwners(ctx, macAddresses)
+	})
+}
+
+func (r *instrumentedDeviceRepository) GetSensorData(ctx context.Context, macID string, startTime, endTime int64) ([]*domain.SensorReading, error) {
+	return observe(ctx, r.observer, "device", "GetSensorData", func(ctx context.Context) ([]*domain.SensorReading, error) {
+		return r.next.GetSensorData(ctx, macID, startTime, endTime)
+	})
+}
+
+// InstrumentCategoryRepository reports the calls of repo to o and traces them
+func InstrumentCategoryRepository(repo CategoryRepositoryInterface, o CallObserver) CategoryRepositoryInterface {
+	return &instrumentedCategoryRepository{next: repo, observer: o}
+}
+
+type instrumentedCategoryRepository struct {
+	next     CategoryRepositoryInterface
+	observer CallObserver
+}
+
+func (r *instrumentedCategoryRepository) AddCategory(ctx context.Context, name, categoryType string) error {
+	return observeErr(ctx, r.observer, "category", "AddCategory", func(ctx context.Context) error {
+		return r.next.AddCategory(ctx, name, categoryType)
+	})
+}
+
+func (r *instrumentedCategoryRepository) GetCategoryByName(ctx context.Context, name string) (*domain.Category, error) {
+	return observe(ctx, r.observer, "category", "GetCategoryByName", func(ctx context.Context) (*domain.Category, error) {
+		return r.next.GetCategoryByName(ctx, name)
+	})
+}
+
+func (r *instrumentedCategoryRepository) GetCategoriesByType(ctx context.Context, categoryType string) ([]*domain.Category, error) {
+	return observe(ctx, r.observer, "category", "GetCategoriesByType", func(ctx context.Context) ([]*domain.Category, error) {
+		return r.next.GetCategoriesByType(ctx, categoryType)
+	})
+}
+
+func (r *instrumentedCategoryRepository) ListAllCategories(ctx context.Context, opts domain.ListOptions) (*domain.Page[*domain.Category], error) {
+	return observe(ctx, r.observer, "category", "ListAllCategories", func(ctx context.Context) (*domain.Page[*domain.Category], error) {
+		return r.next.ListAllCategories(ctx, opts)
+	})
+}
+
+func (r *instrumentedCategoryRepository) WithMaxLag(maxLag time.Duration) CategoryRepositoryInterface {
+	return InstrumentCategoryRepository(r.next.WithMaxLag(maxLag), r.observer)
+}
+
+// InstrumentPolicyRepository reports the calls of repo to o and traces them
+func InstrumentPolicyRepository(repo PolicyRepositoryInterface, o CallObserver) PolicyRepositoryInterface {
+	return &instrumentedPolicyRepository{next: repo, observer: o}
+}
+
+type instrumentedPolicyRepository struct {
+	next     PolicyRepositoryInterface
+	observer CallObserver
+}
+
+func (r *instrumentedPolicyRepository) AttachPolicy(ctx context.Context, policyName, identityID string) error {
+	return observeErr(ctx, r.observer, "policy", "AttachPolicy", func(ctx context.Context) error {
+		return r.next.AttachPolicy(ctx, policyName, identityID)
+	})
+}
+
+// InstrumentEntityHistoryRepository reports the calls of repo to o and traces them
+func InstrumentEntityHistoryRepository(repo EntityHistoryRepositoryInterface, o CallObserver) EntityHistoryRepositoryInterface {
+	return &instrumentedEntityHistoryRepository{next: repo, observer: o}
+}
+
+type instrumentedEntityHistoryRepository struct {
+	next     EntityHistoryRepositoryInterface
+	observer CallObserver
+}
+
+func (r *instrumentedEntityHistoryRepository) ListEntityHistory(ctx context.Context, entityId string, limit int) ([]*domain.EntityHistoryEntry, error) {
+	return observe(ctx, r.observer, "entity_history", "ListEntityHistory", func(ctx context.Context) ([]*domain.EntityHistoryEntry, error) {
+		return r.next.ListEntityHistory(ctx, entityId, limit)
+	})
+}
+
+func (r *instrumentedEntityHistoryRepository) GetSubtreeSnapshot(ctx context.Context, rootEntityId string, at time.Time) ([]domain.EntitySnapshot, error) {
+	return observe(ctx, r.observer, "entity_history", "GetSubtreeSnapshot", func(ctx context.Context) ([]domain.EntitySnapshot, error) {
+		return r.next.GetSubtreeSnapshot(ctx, rootEntityId, at)
+	})
+}
+
+// InstrumentAuditRepository reports the calls of repo to o and traces them
+func InstrumentAuditRepository(repo AuditRepositoryInterface, o CallObserver) AuditRepositoryInterface {
+	return &instrumentedAuditRepository{next: repo, observer: o}
+}
+
+type instrumentedAuditRepository struct {
+	next     AuditRepositoryInterface
+	observer CallObserver
+}
+
+func (r *instrumentedAuditRepository) RecordAuditEntry(ctx context.Context, entry *domain.AuditEntry) error {
+	return observeErr(ctx, r.observer, "audit", "RecordAuditEntry", func(ctx context.Context) error {
+		return r.next.RecordAuditEntry(ctx, entry)
+	})
+}
+
+func (r *instrumentedAuditRepository) ListAuditEntries(ctx context.Context, filter domain.AuditFilter, opts domain.ListOptions) (*domain.Page[*domain.AuditEntry], error) {
+	return observe(ctx, r.observer, "audit", "ListAuditEntries", func(ctx context.Context) (*domain.Page[*domain.AuditEntry], error) {
+		return r.next.ListAuditEntries(ctx, filter, opts)
+	})
+}
+
+func (r *instrumentedAuditRepository) ExportAuditEntries(ctx context.Context, filter domain.AuditFilter, fn func(entry *domain.AuditEntry) error) error {
+	return observeErr(ctx, r.observer, "audit", "ExportAuditEntries", func(ctx context.Context) error {
+		return r.next.ExportAuditEntries(ctx, filter, fn)
+	})
+}
+
+func (r *instrumentedAuditRepository) WithTx(tx pgx.Tx) AuditRepositoryInterface {
+	return InstrumentAuditRepository(r.next.WithTx(tx), r.observer)
+}
+
+func (r *instrumentedAuditRepository) WithReadRouter(router ReadRouter) AuditRepositoryInterface {
+	return InstrumentAuditRepository(r.next.WithReadRouter(router), r.observer)
+}
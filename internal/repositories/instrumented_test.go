@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+	"github.com/afreedicp/zolaris-backend-app/internal/testutil/spantest"
+)
+
+type observedCall struct {
+	repository, method string
+	err                error
+}
+
+type recordingObserver struct {
+	calls []observedCall
+}
+
+func (o *recordingObserver) ObserveRepositoryCall(repository, method string, duration time.Duration, err error) {
+	o.calls = append(o.calls, observedCall{repository, method, err})
+}
+
+type fakeEntityRepository struct {
+	EntityRepositoryInterface
+	tx pgx.Tx
+}
+
+func (r *fakeEntityRepository) GetEntityID(ctx context.Context, userId string) (string, error) {
+	if userId == "" {
+		return "", domain.NewNotFoundError("no entity")
+	}
+	return "entity-of-" + userId, nil
+}
+
+func (r *fakeEntityRepository) ImportEntityRows(ctx context.Context, userId string, parentEntityId string, rows []domain.EntityImportRow, dryRun bool) (map[string]string, int, error) {
+	return map[string]string{"a": "1"}, 2, errors.New("boom")
+}
+
+func (r *fakeEntityRepository) WithTx(tx pgx.Tx) EntityRepositoryInterface {
+	return &fakeEntityRepository{tx: tx}
+}
+
+func TestInstrumentEntityRepository(t *testing.T) {
+	observer := &recordingObserver{}
+	repo := InstrumentEntityRepository(&fakeEntityRepository{}, observer)
+
+	id, err := repo.GetEntityID(context.Background(), "u1")
+	require.NoError(t, err)
+	assert.Equal(t, "entity-of-u1", id)
+
+	_, err = repo.GetEntityID(context.Background(), "")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	entityIds, devices, err := repo.ImportEntityRows(context.Background(), "u1", "p1", nil, true)
+	assert.EqualError(t, err, "boom")
+	assert.Equal(t, map[string]string{"a": "1"}, entityIds)
+	assert.Equal(t, 2, devices)
+
+	require.Len(t, observer.calls, 3)
+	assert.Equal(t, observedCall{"entity", "GetEntityID", nil}, observer.calls[0])
+	assert.Equal(t, "GetEntityID", observer.calls[1].method)
+	assert.ErrorIs(t, observer.calls[1].err, domain.ErrNotFound)
+	assert.Equal(t, "ImportEntityRows", observer.calls[2].method)
+}
+
+func TestInstrumentedRepositoryStaysInstrumentedInTransactions(t *testing.T) {
+	observer := &recordingObserver{}
+	tx := &fakeTx{}
+	repo := InstrumentEntityRepository(&fakeEntityRepository{}, observer).WithTx(tx)
+
+	_, err := repo.GetEntityID(context.Background(), "u1")
+	require.NoError(t, err)
+
+	require.Len(t, observer.calls, 1)
+	inner := repo.(*instrumentedEntityRepository).next.(*fakeEntityRepository)
+	assert.Same(t, tx, inner.tx)
+}
+
+type spanCheckingEntityRepository struct {
+	fakeEntityRepository
+	spanID trace.SpanID
+}
+
+func (r *spanCheckingEntityRepository) GetEntityID(ctx context.Context, userId string) (string, error) {
+	r.spanID = trace.SpanContextFromContext(ctx).SpanID()
+	return r.fakeEntityRepository.GetEntityID(ctx, userId)
+}
+
+func TestInstrumentedRepositoryTracesCalls(t *testing.T) {
+	recorder := spantest.Record(t)
+	inner := &spanCheckingEntityRepository{}
+	repo := InstrumentEntityRepository(inner, &recordingObserver{})
+
+	_, err := repo.GetEntityID(context.Background(), "u1")
+	require.NoError(t, err)
+	_, err = repo.GetEntityID(context.Background(), "")
+	require.Error(t, err)
+	_, _, err = repo.ImportEntityRows(context.Background(), "u1", "p1", nil, true)
+	require.Error(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	assert.Equal(t, "entity.GetEntityID", spans[0].Name())
+	// Queries made by the repository are children of its span
+	assert.Equal(t, spans[1].SpanContext().SpanID(), inner.spanID)
+	// A missing row is not a failed call
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+	assert.Equal(t, "entity.ImportEntityRows", spans[2].Name())
+	assert.Equal(t, codes.Error, spans[2].Status().Code)
+}
@@ -2,6 +2,9 @@ package repositories
 
 import (
 	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
 
 	"github.com/afreedicp/zolaris-backend-app/internal/domain"
 )
@@ -15,34 +18,69 @@ type UserRepositoryInterface interface {
 	CheckHasParentID(ctx context.Context, userID string) (bool, error)
 	GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
 	GetChildUsers(ctx context.Context, parentID string) ([]*domain.User, error)
-	ListReferredUsers(ctx context.Context, userID string) ([]*domain.User, error)
+	ListReferredUsers(ctx context.Context, userID string, opts domain.ListOptions) (*domain.Page[*domain.User], error)
 	UpdateUserParentID(ctx context.Context, userID string, parentID *string) error
+	WithTx(tx pgx.Tx) UserRepositoryInterface
+	WithReadRouter(router ReadRouter) UserRepositoryInterface
 }
 
 // DeviceRepositoryInterface defines the operations for device data
 type DeviceRepositoryInterface interface {
-	AddDevice(ctx context.Context, device *domain.Device) error
-	GetDevicesByUserID(ctx context.Context, userID string) ([]*domain.Device, error)
+	AddDevice(ctx context.Context, deviceID, deviceName, userID string) error
+	GetDevicesByUserID(ctx context.Context, userID string, opts domain.ListOptions) (*domain.Page[*domain.Device], error)
+	GetDevicesInSubtree(ctx context.Context, rootPath string) ([]*domain.Device, error)
+	GetDeviceOwners(ctx context.Context, macAddresses []string) (map[string]string, error)
 	GetSensorData(ctx context.Context, macID string, startTime, endTime int64) ([]*domain.SensorReading, error)
 }
 
 // CategoryRepositoryInterface defines the operations for category data
 type CategoryRepositoryInterface interface {
-	AddCategory(ctx context.Context, category *domain.Category) error
+	AddCategory(ctx context.Context, name, categoryType string) error
+	GetCategoryByName(ctx context.Context, name string) (*domain.Category, error)
 	GetCategoriesByType(ctx context.Context, categoryType string) ([]*domain.Category, error)
-	ListAllCategories(ctx context.Context) ([]*domain.Category, error)
+	ListAllCategories(ctx context.Context, opts domain.ListOptions) (*domain.Page[*domain.Category], error)
+	WithMaxLag(maxLag time.Duration) CategoryRepositoryInterface
 }
 
 // PolicyRepositoryInterface defines the operations for policy data
 type PolicyRepositoryInterface interface {
-	AttachPolicy(ctx context.Context, identityId, policyName string) error
+	AttachPolicy(ctx context.Context, policyName, identityID string) error
 }
 
 // EntityRepositoryInterface defines the operations for entity data
 type EntityRepositoryInterface interface {
 	CheckEntityPresence(ctx context.Context, userId string) (bool, error)
 	GetCategoryType(ctx context.Context, categoryId string) (CategoryType, error)
+	GetCategoryTypes(ctx context.Context, categoryIds []string) (map[string]CategoryType, error)
+	GetCategoryIDByEntityID(ctx context.Context, entityID string) (string, error)
 	CreateRootEntity(ctx context.Context, categoryId string, entityName string, userId string, details map[string]any) (string, error)
-	CreateSubEntity(ctx context.Context, categoryId string, entityName string, parentEntityId string, userId string, details map[string]any) (string, error)
+	CreateSubEntity(ctx context.Context, categoryId string, entityName string, userId string, details map[string]any, parentEntityId string) (string, error)
 	GetChildEntities(ctx context.Context, entityId string, recursive bool) ([]*domain.Entity, error)
+	GetEntityHierarchy(ctx context.Context, rootEntityId string, opts HierarchyOptions) (*domain.EntityHierarchy, error)
+	GetEntityAncestors(ctx context.Context, entityId string, includeSelf bool) ([]*domain.EntityNode, error)
+	SearchEntities(ctx context.Context, filter EntitySearchFilter) ([]*domain.EntityNode, error)
+	IsEntityAccessible(ctx context.Context, userId string, entityId string) (bool, error)
+	GetSubtree(ctx context.Context, rootEntityId string) ([]*domain.EntityNode, error)
+	ImportEntityRows(ctx context.Context, userId string, parentEntityId string, rows []domain.EntityImportRow, dryRun bool) (map[string]string, int, error)
+	ListEntityChildren(ctx context.Context, entityId string, level int, opts domain.ListOptions) (*domain.Page[*domain.Entity], error)
+	GetEntityID(ctx context.Context, userId string) (string, error)
+	WithTx(tx pgx.Tx) EntityRepositoryInterface
+	WithReadRouter(router ReadRouter) EntityRepositoryInterface
+	Primary() EntityRepositoryInterface
+	WithMaxLag(maxLag time.Duration) EntityRepositoryInterface
+}
+
+// EntityHistoryRepositoryInterface defines the operations for entity history data
+type EntityHistoryRepositoryInterface interface {
+	ListEntityHistory(ctx context.Context, entityId string, limit int) ([]*domain.EntityHistoryEntry, error)
+	GetSubtreeSnapshot(ctx context.Context, rootEntityId string, at time.Time) ([]domain.EntitySnapshot, error)
+}
+
+// AuditRepositoryInterface defines the operations for the audit log
+type AuditRepositoryInterface interface {
+	RecordAuditEntry(ctx context.Context, entry *domain.AuditEntry) error
+	ListAuditEntries(ctx context.Context, filter domain.AuditFilter, opts domain.ListOptions) (*domain.Page[*domain.AuditEntry], error)
+	ExportAuditEntries(ctx context.Context, filter domain.AuditFilter, fn func(entry *domain.AuditEntry) error) error
+	WithTx(tx pgx.Tx) AuditRepositoryInterface
+	WithReadRouter(router ReadRouter) AuditRepositoryInterface
 }
@@ -0,0 +1,189 @@
+package repositories
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+)
+
+// Page size bounds shared by every list endpoint
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// sortField is a column a list may be ordered by
+type sortField struct {
+	column string // SQL expression to order by; must never be NULL, so nullable columns are coalesced
+	cast   string // SQL type a cursor value is cast back to
+}
+
+// missingTime stands in for a NULL timestamp in sort columns, and in the selected
+// columns they are read back from, so cursors match. It is the zero time.Time.
+const missingTime = `'0001-01-01 00:00:00+00'::timestamptz`
+
+// listSpec whitelists the sort and filter fields of one list endpoint
+type listSpec struct {
+	sortFields   map[string]sortField
+	defaultSort  string
+	tiebreaker   string            // unique text expression that makes the order total
+	filterFields map[string]string // field name -> SQL expression compared with =
+}
+
+// listQuery builds the WHERE, ORDER BY and LIMIT clauses of a paged list query.
+// Conditions added with where apply to both the count and the page query; the
+// keyset condition of a cursor only applies to the page query.
+type listQuery struct {
+	spec       listSpec
+	sortName   string
+	sort       sortField
+	desc       bool
+	page       int
+	pageSize   int
+	after      []string // sort value and tiebreaker of the last row of the previous page
+	conditions []string
+	args       []any
+}
+
+// newListQuery validates opts against spec and applies its filters
+func newListQuery(spec listSpec, opts domain.ListOptions) (*listQuery, error) {
+	q := &listQuery{spec: spec, page: opts.Page, pageSize: opts.PageSize}
+	if q.page < 1 {
+		q.page = 1
+	}
+	if q.pageSize < 1 {
+		q.pageSize = defaultPageSize
+	}
+	if q.pageSize > maxPageSize {
+		q.pageSize = maxPageSize
+	}
+
+	q.sortName = opts.Sort
+	if strings.HasPrefix(q.sortName, "-") {
+		q.sortName, q.desc = q.sortName[1:], true
+	}
+	if q.sortName == "" {
+		q.sortName = spec.defaultSort
+	}
+	field, ok := spec.sortFields[q.sortName]
+	if !ok {
+		return nil, domain.NewValidationError("unsupported sort field %q", q.sortName)
+	}
+	q.sort = field
+
+	if opts.Cursor != "" {
+		values, err := decodeCursor(opts.Cursor, 4)
+		if err != nil || values[0] != q.sortName || values[1] != q.direction() {
+			return nil, ErrInvalidCursor
+		}
+		q.after = values[2:]
+		q.page = 0
+	}
+
+	// Apply filters in a stable order so identical requests build identical SQL
+	names := make([]string, 0, len(opts.Filters))
+	for name := range opts.Filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		column, ok := spec.filterFields[name]
+		if !ok {
+			return nil, domain.NewValidationError("unsupported filter field %q", name)
+		}
+		q.where(column + " = " + q.arg(opts.Filters[name]))
+	}
+
+	return q, nil
+}
+
+// arg adds a query argument and returns its placeholder
+func (q *listQuery) arg(value any) string {
+	q.args = append(q.args, value)
+	return fmt.Sprintf("$%d", len(q.args))
+}
+
+// where adds a condition that every listed row must satisfy
+func (q *listQuery) where(condition string) {
+	q.conditions = append(q.conditions, condition)
+}
+
+func (q *listQuery) direction() string {
+	if q.desc {
+		return "desc"
+	}
+	return "asc"
+}
+
+// countSQL returns the query counting all matching rows, for use with countArgs
+func (q *listQuery) countSQL(from string) string {
+	return "SELECT count(*) " + from + whereSQL(q.conditions)
+}
+
+// countArgs returns the arguments of countSQL
+func (q *listQuery) countArgs() []any {
+	return q.args
+}
+
+// pageSQL returns the query selecting one page plus one extra row, used to detect further pages
+func (q *listQuery) pageSQL(columns, from string) (string, []any) {
+	conditions := q.conditions
+	args := append([]any(nil), q.args...)
+
+	comparison := ">"
+	if q.desc {
+		comparison = "<"
+	}
+
+	if q.after != nil {
+		args = append(args, q.after[0], q.after[1])
+		conditions = append(conditions[:len(conditions):len(conditions)], fmt.Sprintf(
+			"(%s, %s) %s ($%d::%s, $%d)",
+			q.sort.column, q.spec.tiebreaker, comparison, len(args)-1, q.sort.cast, len(args),
+		))
+	}
+
+	query := fmt.Sprintf(
+		"SELECT %s %s%s ORDER BY %s %s, %s %s LIMIT %d",
+		columns, from, whereSQL(conditions),
+		q.sort.column, q.direction(), q.spec.tiebreaker, q.direction(),
+		q.pageSize+1,
+	)
+	if q.after == nil && q.page > 1 {
+		query += fmt.Sprintf(" OFFSET %d", (q.page-1)*q.pageSize)
+	}
+
+	return query, args
+}
+
+func whereSQL(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
+// newPage trims the extra row fetched by pageSQL and derives the next cursor from
+// the last item. key returns an item's value for the given sort field and its tiebreaker.
+func newPage[T any](q *listQuery, items []T, total int64, key func(item T, sortName string) (string, string)) *domain.Page[T] {
+	page := &domain.Page[T]{
+		Items:      items,
+		TotalItems: total,
+		Page:       q.page,
+		PageSize:   q.pageSize,
+	}
+
+	if len(items) > q.pageSize {
+		page.Items = items[:q.pageSize]
+		value, id := key(page.Items[q.pageSize-1], q.sortName)
+		page.NextCursor = encodeCursor(q.sortName, q.direction(), value, id)
+	}
+
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+
+	return page
+}
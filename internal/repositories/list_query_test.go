@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+)
+
+var testListSpec = listSpec{
+	sortFields: map[string]sortField{
+		"name":      {column: "t.name", cast: "text"},
+		"createdAt": {column: "t.created_at", cast: "timestamptz"},
+	},
+	defaultSort:  "name",
+	tiebreaker:   "t.id",
+	filterFields: map[string]string{"kind": "t.kind"},
+}
+
+func TestListQueryOffsetPage(t *testing.T) {
+	q, err := newListQuery(testListSpec, domain.ListOptions{Page: 3, PageSize: 10, Filters: map[string]string{"kind": "a"}})
+	require.NoError(t, err)
+	q.where("t.owner = " + q.arg("u1"))
+
+	assert.Equal(t, "SELECT count(*) FROM t WHERE t.kind = $1 AND t.owner = $2", q.countSQL("FROM t"))
+	assert.Equal(t, []any{"a", "u1"}, q.countArgs())
+
+	query, args := q.pageSQL("t.id", "FROM t")
+	assert.Equal(t, "SELECT t.id FROM t WHERE t.kind = $1 AND t.owner = $2 ORDER BY t.name asc, t.id asc LIMIT 11 OFFSET 20", query)
+	assert.Equal(t, []any{"a", "u1"}, args)
+}
+
+func TestListQueryCursorPage(t *testing.T) {
+	cursor := encodeCursor("createdAt", "desc", "2025-01-02T03:04:05Z", "id-9")
+	q, err := newListQuery(testListSpec, domain.ListOptions{Page: 5, Cursor: cursor, Sort: "-createdAt"})
+	require.NoError(t, err)
+
+	query, args := q.pageSQL("t.id", "FROM t")
+	assert.Equal(t, "SELECT t.id FROM t WHERE (t.created_at, t.id) < ($1::timestamptz, $2) ORDER BY t.created_at desc, t.id desc LIMIT 21", query)
+	assert.Equal(t, []any{"2025-01-02T03:04:05Z", "id-9"}, args)
+	assert.Empty(t, q.countArgs(), "the keyset condition must not leak into the count query")
+	assert.Equal(t, 0, q.page)
+}
+
+func TestListQueryRejectsInvalidOptions(t *testing.T) {
+	_, err := newListQuery(testListSpec, domain.ListOptions{Sort: "password"})
+	assert.True(t, errors.Is(err, domain.ErrValidation))
+
+	_, err = newListQuery(testListSpec, domain.ListOptions{Filters: map[string]string{"owner": "x"}})
+	assert.True(t, errors.Is(err, domain.ErrValidation))
+
+	// A cursor is only valid for the sort order it was issued for
+	cursor := encodeCursor("name", "asc", "b", "id-2")
+	_, err = newListQuery(testListSpec, domain.ListOptions{Cursor: cursor, Sort: "-name"})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestListQueryClampsPageSize(t *testing.T) {
+	q, err := newListQuery(testListSpec, domain.ListOptions{PageSize: 1000})
+	require.NoError(t, err)
+	assert.Equal(t, maxPageSize, q.pageSize)
+
+	q, err = newListQuery(testListSpec, domain.ListOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, defaultPageSize, q.pageSize)
+	assert.Equal(t, 1, q.page)
+}
+
+func TestNewPage(t *testing.T) {
+	q, err := newListQuery(testListSpec, domain.ListOptions{PageSize: 2})
+	require.NoError(t, err)
+
+	key := func(item string, sortName string) (string, string) { return item, "id-" + item }
+
+	page := newPage(q, []string{"a", "b", "c"}, 7, key)
+	assert.Equal(t, []string{"a", "b"}, page.Items)
+	assert.Equal(t, int64(7), page.TotalItems)
+	assert.Equal(t, encodeCursor("name", "asc", "b", "id-b"), page.NextCursor)
+
+	last := newPage(q, []string{"a"}, 1, key)
+	assert.Empty(t, last.NextCursor)
+
+	empty := newPage[string](q, nil, 0, key)
+	assert.NotNil(t, empty.Items)
+}
@@ -0,0 +1,120 @@
+//go:build integration
+
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+	"github.com/afreedicp/zolaris-backend-app/internal/testutil/pgtest"
+)
+
+func TestMain(m *testing.M) {
+	pgtest.Main(m)
+}
+
+// seedUser inserts a user with a unique email and Cognito ID
+func seedUser(t *testing.T, tx pgx.Tx, referralMail *string) *domain.User {
+	t.Helper()
+
+	id := uuid.New().String()
+	cognitoID := "cognito-" + id
+	role := "user"
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	user := domain.NewUser(fmt.Sprintf("%s@example.com", id), "Test", "User", "555-0100")
+	user.ID = id
+	user.CognitoID = &cognitoID
+	user.ReferralMail = referralMail
+	user.Role = &role
+	user.CreatedAt = now
+	user.UpdatedAt = now
+
+	require.NoError(t, (&UserRepository{db: tx}).CreateUser(context.Background(), user))
+	return user
+}
+
+// seedCategory inserts a category and returns its ID
+func seedCategory(t *testing.T, tx pgx.Tx, name string, categoryType CategoryType) string {
+	t.Helper()
+
+	var categoryID string
+	err := tx.QueryRow(context.Background(),
+		`INSERT INTO z_category (name, type) VALUES ($1, $2) RETURNING category_id`,
+		name, string(categoryType),
+	).Scan(&categoryID)
+	require.NoError(t, err)
+
+	return categoryID
+}
+
+// seedEntity inserts an entity below parentID, or a root entity if parentID is empty.
+// userID is only stored when it is not empty.
+func seedEntity(t *testing.T, tx pgx.Tx, categoryID, parentID, name, userID string) string {
+	t.Helper()
+
+	var entityID string
+	err := tx.QueryRow(context.Background(),
+		`INSERT INTO z_entity (category_id, parent_id, name, user_id) VALUES ($1, NULLIF($2, '')::uuid, $3, NULLIF($4, '')::uuid) RETURNING entity_id`,
+		categoryID, parentID, name, userID,
+	).Scan(&entityID)
+	require.NoError(t, err)
+
+	return entityID
+}
+
+// testTree is a small hierarchy owned by a single user:
+//
+//	root (user)
+//	├── Alpha (office)
+//	│   └── Desk (location)
+//	└── Beta (office)
+type testTree struct {
+	user             *domain.User
+	userCategory     string
+	officeCategory   string
+	locationCategory string
+	root             string
+	alpha            string
+	beta             string
+	desk             string
+}
+
+func seedTree(t *testing.T, tx pgx.Tx) *testTree {
+	t.Helper()
+
+	tree := &testTree{user: seedUser(t, tx, nil)}
+	tree.userCategory = seedCategory(t, tx, "User", UserCategoryType)
+	tree.officeCategory = seedCategory(t, tx, "Office", OfficeCategoryType)
+	tree.locationCategory = seedCategory(t, tx, "Location", LocationCategoryType)
+
+	tree.root = seedEntity(t, tx, tree.userCategory, "", "Root", tree.user.ID)
+	tree.alpha = seedEntity(t, tx, tree.officeCategory, tree.root, "Alpha", "")
+	tree.beta = seedEntity(t, tx, tree.officeCategory, tree.root, "Beta", "")
+	tree.desk = seedEntity(t, tx, tree.locationCategory, tree.alpha, "Desk", "")
+
+	return tree
+}
+
+func entityIDs(entities []*domain.Entity) []string {
+	ids := make([]string, len(entities))
+	for i, entity := range entities {
+		ids[i] = entity.ID
+	}
+	return ids
+}
+
+func nodeIDs(nodes []*domain.EntityNode) []string {
+	ids := make([]string, len(nodes))
+	for i, node := range nodes {
+		ids[i] = node.ID
+	}
+	return ids
+}
@@ -0,0 +1,34 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// PostgreSQL error codes the repositories react to
+const (
+	pgUniqueViolation      = "23505"
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+)
+
+// pgErrorCode returns the SQLSTATE of a PostgreSQL error, or "" for other errors
+func pgErrorCode(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code
+	}
+	return ""
+}
+
+// isUniqueViolation reports whether err is a unique constraint violation
+func isUniqueViolation(err error) bool {
+	return pgErrorCode(err) == pgUniqueViolation
+}
+
+// isRetryableTxError reports whether err is a serialization failure or deadlock
+func isRetryableTxError(err error) bool {
+	code := pgErrorCode(err)
+	return code == pgSerializationFailure || code == pgDeadlockDetected
+}
@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ReadRouter picks the pool for read-only queries that tolerate replication lag,
+// such as hierarchy and listing queries. It is implemented by db.ReplicaRouter.
+type ReadRouter interface {
+	ReadPool() *pgxpool.Pool
+	// ReadPoolWithin picks the replica only if it lags at most maxLag
+	ReadPoolWithin(maxLag time.Duration) *pgxpool.Pool
+}
+
+// maxLagRouter narrows a router to a replica lagging at most maxLag
+type maxLagRouter struct {
+	router ReadRouter
+	maxLag time.Duration
+}
+
+func (r maxLagRouter) ReadPool() *pgxpool.Pool {
+	return r.router.ReadPoolWithin(r.maxLag)
+}
+
+func (r maxLagRouter) ReadPoolWithin(maxLag time.Duration) *pgxpool.Pool {
+	return r.router.ReadPoolWithin(min(maxLag, r.maxLag))
+}
+
+// withMaxLag returns router narrowed to a replica lagging at most maxLag, or nil if router is nil
+func withMaxLag(router ReadRouter, maxLag time.Duration) ReadRouter {
+	if router == nil {
+		return nil
+	}
+	return maxLagRouter{router: router, maxLag: maxLag}
+}
+
+// readDB returns the pool picked by router, or db when no router is set.
+// Repositories bound to a transaction drop their router, so reads inside a
+// transaction always see its writes.
+func readDB(db DBTX, router ReadRouter) DBTX {
+	if router == nil {
+		return db
+	}
+	return router.ReadPool()
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// StatsRepository counts the registered devices and entities for monitoring
+type StatsRepository struct {
+	db    DBTX
+	reads ReadRouter
+}
+
+// NewStatsRepository creates a new stats repository instance
+func NewStatsRepository(dbPool *pgxpool.Pool) *StatsRepository {
+	return &StatsRepository{
+		db: dbPool,
+	}
+}
+
+// WithReadRouter routes the counting queries of the repository through router
+func (r *StatsRepository) WithReadRouter(router ReadRouter) *StatsRepository {
+	r.reads = router
+	return r
+}
+
+// CountDevices returns the number of registered devices
+func (r *StatsRepository) CountDevices(ctx context.Context) (int64, error) {
+	var count int64
+	if err := readDB(r.db, r.reads).QueryRow(ctx, `SELECT count(*) FROM z_device`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count devices: %w", err)
+	}
+	return count, nil
+}
+
+// CountEntitiesByCategoryType returns the number of entities of each category type
+func (r *StatsRepository) CountEntitiesByCategoryType(ctx context.Context) (map[string]int64, error) {
+	query := `
+		SELECT c.type, count(*)
+		FROM z_entity e
+		JOIN z_category c ON c.category_id = e.category_id
+		GROUP BY c.type
+	`
+
+	rows, err := readDB(r.db, r.reads).Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count entities: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int64)
+	for rows.Next() {
+		var categoryType string
+		var count int64
+		if err := rows.Scan(&categoryType, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan entity count: %w", err)
+		}
+		counts[categoryType] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count entities: %w", err)
+	}
+
+	return counts, nil
+}
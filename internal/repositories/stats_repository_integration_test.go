@@ -0,0 +1,39 @@
+//go:build integration
+
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/testutil/pgtest"
+)
+
+func TestStatsRepository(t *testing.T) {
+	tx := pgtest.Tx(t)
+	repo := &StatsRepository{db: tx}
+	ctx := context.Background()
+
+	devicesBefore, err := repo.CountDevices(ctx)
+	require.NoError(t, err)
+	entitiesBefore, err := repo.CountEntitiesByCategoryType(ctx)
+	require.NoError(t, err)
+
+	tree := seedTree(t, tx)
+	devices := NewDeviceRepository(nil, nil).WithTx(tx)
+	require.NoError(t, devices.AddDevice(ctx, "BB:00:00:00:00:01", "Boiler", tree.user.ID))
+	require.NoError(t, devices.AddDevice(ctx, "BB:00:00:00:00:02", "Chiller", tree.user.ID))
+
+	devicesAfter, err := repo.CountDevices(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, devicesBefore+2, devicesAfter)
+
+	entitiesAfter, err := repo.CountEntitiesByCategoryType(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, entitiesBefore["user"]+1, entitiesAfter["user"])
+	assert.Equal(t, entitiesBefore["office"]+2, entitiesAfter["office"])
+	assert.Equal(t, entitiesBefore["location"]+1, entitiesAfter["location"])
+}
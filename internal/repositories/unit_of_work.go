@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DBTX is the query surface shared by *pgxpool.Pool and pgx.Tx, so a repository
+// can run against the pool or inside a caller's transaction. Begin on a pgx.Tx
+// opens a savepoint, so repository methods that manage their own transaction
+// nest safely inside a unit of work.
+type DBTX interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Defaults for retrying transactions that fail with a serialization error
+const (
+	defaultTxMaxAttempts = 3
+	defaultTxRetryDelay  = 20 * time.Millisecond
+)
+
+type txBeginner interface {
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
+// UnitOfWork runs several repository calls in one transaction. Repositories are
+// bound to the transaction with their WithTx methods inside the callback.
+type UnitOfWork struct {
+	db          txBeginner
+	maxAttempts int
+	retryDelay  time.Duration
+}
+
+// NewUnitOfWork creates a unit of work over the given connection pool
+func NewUnitOfWork(dbPool *pgxpool.Pool) *UnitOfWork {
+	return &UnitOfWork{
+		db:          dbPool,
+		maxAttempts: defaultTxMaxAttempts,
+		retryDelay:  defaultTxRetryDelay,
+	}
+}
+
+// Do runs fn in a read-committed transaction, committing if it returns nil
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, tx pgx.Tx) error) error {
+	return u.DoWithOptions(ctx, pgx.TxOptions{}, fn)
+}
+
+// DoWithOptions runs fn in a transaction with the given options. If the transaction
+// fails with a serialization failure or deadlock, fn is run again in a fresh
+// transaction, so it must not have side effects outside the database.
+func (u *UnitOfWork) DoWithOptions(ctx context.Context, opts pgx.TxOptions, fn func(ctx context.Context, tx pgx.Tx) error) error {
+	var err error
+	for attempt := 1; attempt <= u.maxAttempts; attempt++ {
+		err = u.run(ctx, opts, fn)
+		if err == nil || !isRetryableTxError(err) {
+			return err
+		}
+
+		if attempt < u.maxAttempts {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(attempt) * u.retryDelay):
+			}
+		}
+	}
+	return fmt.Errorf("transaction failed after %d attempts: %w", u.maxAttempts, err)
+}
+
+func (u *UnitOfWork) run(ctx context.Context, opts pgx.TxOptions, fn func(ctx context.Context, tx pgx.Tx) error) error {
+	tx, err := u.db.BeginTx(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(ctx, tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
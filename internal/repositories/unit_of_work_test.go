@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTx struct {
+	pgx.Tx
+	committed  bool
+	rolledBack bool
+}
+
+func (t *fakeTx) Commit(ctx context.Context) error {
+	t.committed = true
+	return nil
+}
+
+func (t *fakeTx) Rollback(ctx context.Context) error {
+	if !t.committed {
+		t.rolledBack = true
+	}
+	return nil
+}
+
+type fakeBeginner struct {
+	txs []*fakeTx
+}
+
+func (b *fakeBeginner) BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error) {
+	tx := &fakeTx{}
+	b.txs = append(b.txs, tx)
+	return tx, nil
+}
+
+func newTestUnitOfWork() (*UnitOfWork, *fakeBeginner) {
+	db := &fakeBeginner{}
+	return &UnitOfWork{db: db, maxAttempts: 3}, db
+}
+
+func TestUnitOfWorkCommits(t *testing.T) {
+	uow, db := newTestUnitOfWork()
+
+	err := uow.Do(context.Background(), func(ctx context.Context, tx pgx.Tx) error { return nil })
+
+	assert.NoError(t, err)
+	assert.Len(t, db.txs, 1)
+	assert.True(t, db.txs[0].committed)
+}
+
+func TestUnitOfWorkRollsBackOnError(t *testing.T) {
+	uow, db := newTestUnitOfWork()
+	failure := errors.New("boom")
+
+	err := uow.Do(context.Background(), func(ctx context.Context, tx pgx.Tx) error { return failure })
+
+	assert.ErrorIs(t, err, failure)
+	assert.Len(t, db.txs, 1)
+	assert.True(t, db.txs[0].rolledBack)
+}
+
+func TestUnitOfWorkRetriesSerializationFailures(t *testing.T) {
+	uow, db := newTestUnitOfWork()
+
+	calls := 0
+	err := uow.Do(context.Background(), func(ctx context.Context, tx pgx.Tx) error {
+		calls++
+		if calls < 3 {
+			return &pgconn.PgError{Code: pgSerializationFailure}
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.True(t, db.txs[0].rolledBack)
+	assert.True(t, db.txs[2].committed)
+}
+
+func TestUnitOfWorkGivesUpAfterMaxAttempts(t *testing.T) {
+	uow, db := newTestUnitOfWork()
+
+	err := uow.Do(context.Background(), func(ctx context.Context, tx pgx.Tx) error {
+		return &pgconn.PgError{Code: pgDeadlockDetected}
+	})
+
+	assert.Error(t, err)
+	assert.Len(t, db.txs, 3)
+}
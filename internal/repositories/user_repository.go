@@ -6,7 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"time"
-"log"
+"log/slog"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
@@ -15,7 +15,8 @@ import (
 
 // UserRepository handles all user-related database operations with PostgreSQL
 type UserRepository struct {
-	db *pgxpool.Pool
+	db    DBTX
+	reads ReadRouter
 }
 
 // NewUserRepository creates a new user repository instance
@@ -25,6 +26,19 @@ func NewUserRepository(dbPool *pgxpool.Pool) UserRepositoryInterface {
 	}
 }
 
+// WithTx returns a copy of the repository that runs its queries on tx
+func (r *UserRepository) WithTx(tx pgx.Tx) UserRepositoryInterface {
+	return &UserRepository{
+		db: tx,
+	}
+}
+
+// WithReadRouter routes the user listing queries of the repository through router
+func (r *UserRepository) WithReadRouter(router ReadRouter) UserRepositoryInterface {
+	r.reads = router
+	return r
+}
+
 func (r *UserRepository) GetUserIdByCognitoId(ctx context.Context, cId string) (string, error) {
 	var userId string
 
@@ -166,7 +180,7 @@ func (r *UserRepository) UpdateUser(ctx context.Context, user *domain.User) erro
 
 	rowsAffected := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("user not found with ID: %s", user.ID)
+		return domain.NewNotFoundError("user not found with ID: %s", user.ID)
 	}
 
 	return nil
@@ -185,7 +199,7 @@ func (r *UserRepository) CheckHasParentID(ctx context.Context, userID string) (b
 	err := r.db.QueryRow(ctx, query, userID).Scan(&hasParent)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return false, fmt.Errorf("user not found: %w", err)
+			return false, domain.NewNotFoundError("user not found with ID: %s", userID)
 		}
 		return false, fmt.Errorf("database error: %w", err)
 	}
@@ -200,7 +214,7 @@ func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*dom
                cognito_id, referral_mail, role, -- <--- ADDED THESE FIELDS HERE
                address, parent_id, created_at, updated_at
         FROM z_users
-        WHERE user_id = $1
+        WHERE email = $1
     `
 
 	row := r.db.QueryRow(ctx, query, email)
@@ -215,10 +229,10 @@ func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*dom
 		&user.FirstName,
 		&user.LastName,
 		&user.Phone,
+		&user.CognitoID,
+		&user.ReferralMail,
+		&user.Role,
 		&addressJSON,
-		&user.CognitoID,    // <--- ADDED SCAN DESTINATION
-        &user.ReferralMail, // <--- ADDED SCAN DESTINATION
-        &user.Role,         // <--- ADDED SCAN DESTINATION
 		&parentID,
 		&user.CreatedAt,
 		&user.UpdatedAt,
@@ -248,7 +262,8 @@ func (r *UserRepository) GetChildUsers(ctx context.Context, parentID string) ([]
                cognito_id, referral_mail, role, -- <--- ADDED THESE FIELDS HERE
                address, parent_id, created_at, updated_at
         FROM z_users
-        WHERE user_id = $1
+        WHERE parent_id = $1
+        ORDER BY email
     `
 
 	rows, err := r.db.Query(ctx, query, parentID)
@@ -269,10 +284,10 @@ func (r *UserRepository) GetChildUsers(ctx context.Context, parentID string) ([]
 			&user.FirstName,
 			&user.LastName,
 			&user.Phone,
+			&user.CognitoID,
+			&user.ReferralMail,
+			&user.Role,
 			&addressJSON,
-			&user.CognitoID,    // <--- ADDED SCAN DESTINATION
-        &user.ReferralMail, // <--- ADDED SCAN DESTINATION
-        &user.Role,         // <--- ADDED SCAN DESTINATION
 			&parentID,
 			&user.CreatedAt,
 			&user.UpdatedAt,
@@ -299,33 +314,44 @@ func (r *UserRepository) GetChildUsers(ctx context.Context, parentID string) ([]
 	return users, nil
 }
 
-func (r *UserRepository) ListReferredUsers(ctx context.Context, userID string) ([]*domain.User, error) {
-	// Step 1: Get the email of the referring user
-	var referrerEmail string
-	err := r.db.QueryRow(ctx, `
-		SELECT email
-		FROM z_users
-		WHERE user_id = $1
-	`, userID).Scan(&referrerEmail)
+// userListSpec whitelists the sort and filter fields of user lists
+var userListSpec = listSpec{
+	sortFields: map[string]sortField{
+		"email":     {column: "u.email", cast: "text"},
+		"firstName": {column: "COALESCE(u.first_name, '')", cast: "text"},
+		"createdAt": {column: "COALESCE(u.created_at, " + missingTime + ")", cast: "timestamptz"},
+	},
+	defaultSort: "email",
+	tiebreaker:  "u.user_id::text",
+	filterFields: map[string]string{
+		"role": "u.role",
+	},
+}
+
+// ListReferredUsers retrieves one page of the users who signed up with the given user's email as referral
+func (r *UserRepository) ListReferredUsers(ctx context.Context, userID string, opts domain.ListOptions) (*domain.Page[*domain.User], error) {
+	db := readDB(r.db, r.reads)
 
+	q, err := newListQuery(userListSpec, opts)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			// Referrer not found — treat as no referrals
-			return []*domain.User{}, nil
-		}
-		return nil, fmt.Errorf("failed to get email for user %s: %w", userID, err)
+		return nil, err
 	}
+	// An unknown referrer yields NULL, which matches no rows
+	q.where("u.referral_mail = (SELECT email FROM z_users WHERE user_id = " + q.arg(userID) + ")")
 
-	// Step 2: Get all users who were referred using that email
-	query := `
-		SELECT user_id, email, first_name, last_name, phone,
-			   cognito_id, referral_mail, role,
-			   address, parent_id, created_at, updated_at
-		FROM z_users
-		WHERE referral_mail = $1;
-	`
+	from := `FROM z_users u`
+
+	var total int64
+	if err := db.QueryRow(ctx, q.countSQL(from), q.countArgs()...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count referred users: %w", err)
+	}
 
-	rows, err := r.db.Query(ctx, query, referrerEmail)
+	query, args := q.pageSQL(`
+		u.user_id, u.email, u.first_name, u.last_name, u.phone,
+		u.cognito_id, u.referral_mail, u.role,
+		u.address, u.parent_id, COALESCE(u.created_at, `+missingTime+`), u.updated_at`, from)
+
+	rows, err := db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query error: %w", err)
 	}
@@ -335,11 +361,7 @@ func (r *UserRepository) ListReferredUsers(ctx context.Context, userID string) (
 	var users []*domain.User
 	for rows.Next() {
 		user := &domain.User{}
-		// Removed local *string variables like firstName, lastName etc.
-		// Scanning directly into &user.Field, assuming domain.User fields are *string if nullable,
-		// or string if not nullable (and DB ensures NOT NULL).
 		var addressJSON []byte // For the JSONB 'address' column
-		var parentID *string   // For nullable parent_id
 
 		err := rows.Scan(
 			&user.ID,
@@ -351,7 +373,7 @@ func (r *UserRepository) ListReferredUsers(ctx context.Context, userID string) (
 			&user.ReferralMail,
 			&user.Role,
 			&addressJSON,
-			&parentID,
+			&user.ParentID,
 			&user.CreatedAt,
 			&user.UpdatedAt,
 		)
@@ -359,21 +381,12 @@ func (r *UserRepository) ListReferredUsers(ctx context.Context, userID string) (
 			return nil, fmt.Errorf("error scanning user row: %w", err)
 		}
 
-		user.ParentID = parentID // Assign the scanned nullable parentID
-
-		// --- CORRECTED ADDRESS PARSING LOGIC ---
-		// This block is now structurally correct.
 		if len(addressJSON) > 0 && string(addressJSON) != "null" {
-			user.Address = &domain.Address{} // Initialize Address struct only if there's data to unmarshal
-			if err := json.Unmarshal(addressJSON, &user.Address); err != nil {
-				// If unmarshaling fails, return an error for this row.
+			user.Address = &domain.Address{}
+			if err := json.Unmarshal(addressJSON, user.Address); err != nil {
 				return nil, fmt.Errorf("failed to parse address JSON in ListReferredUsers: %w", err)
 			}
-		} else {
-			// If addressJSON is empty or "null", explicitly set user.Address to nil
-			user.Address = nil
 		}
-		// --- END CORRECTED ADDRESS PARSING LOGIC ---
 
 		users = append(users, user)
 	}
@@ -382,7 +395,18 @@ func (r *UserRepository) ListReferredUsers(ctx context.Context, userID string) (
 		return nil, fmt.Errorf("row iteration error: %w", err)
 	}
 
-	return users, nil
+	return newPage(q, users, total, func(user *domain.User, sortName string) (string, string) {
+		switch sortName {
+		case "firstName":
+			if user.FirstName == nil {
+				return "", user.ID
+			}
+			return *user.FirstName, user.ID
+		case "createdAt":
+			return user.CreatedAt.Format(time.RFC3339Nano), user.ID
+		}
+		return user.Email, user.ID
+	}), nil
 }
 
 
@@ -390,10 +414,9 @@ func (r *UserRepository) ListReferredUsers(ctx context.Context, userID string) (
 // It accepts a *string for parentID to correctly handle NULL values in the database.
 func (r *UserRepository) UpdateUserParentID(ctx context.Context, userID string, parentID *string) error {
 	// The query to update the parent_id and updated_at timestamp for a given user.
-	 log.Println("ERROR: r.db is nil in UpdateUserParentID!")
 	// Assuming 'id' is the primary key column for the user in z_users.
 	  if r.db == nil {
-        log.Println("ERROR: r.db is nil in UpdateUserParentID!")
+        slog.ErrorContext(ctx, "r.db is nil in UpdateUserParentID")
         return fmt.Errorf("database connection is not initialized")
     }
 	query := `UPDATE z_users SET parent_id = $1, updated_at = NOW() WHERE user_id = $2`
@@ -406,7 +429,7 @@ func (r *UserRepository) UpdateUserParentID(ctx context.Context, userID string,
 	// Check how many rows were affected to verify the update occurred.
 	rowsAffected := result.RowsAffected()
 	if rowsAffected == 0 {
-		log.Printf("Warning: No user found with ID %s to update parent_id", userID)
+		slog.WarnContext(ctx, "No user found to update parent_id", "target_user_id", userID)
 		// Depending on your business logic, you might want to return an error here
 		// if it's critical that the user exists for the update to succeed.
 	}
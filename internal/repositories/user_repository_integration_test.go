@@ -0,0 +1,168 @@
+//go:build integration
+
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+	"github.com/afreedicp/zolaris-backend-app/internal/testutil/pgtest"
+)
+
+func TestUserRepositoryCreateAndGet(t *testing.T) {
+	tx := pgtest.Tx(t)
+	repo := NewUserRepository(nil).WithTx(tx)
+	ctx := context.Background()
+
+	user := seedUser(t, tx, nil)
+	user.Address = &domain.Address{Street1: "1 Main St", City: "Kochi", Region: "KL", Country: "IN", Zip: "682001"}
+	require.NoError(t, repo.UpdateUser(ctx, user))
+
+	tests := []struct {
+		name string
+		get  func() (*domain.User, error)
+	}{
+		{"by ID", func() (*domain.User, error) { return repo.GetUserByID(ctx, user.ID) }},
+		{"by email", func() (*domain.User, error) { return repo.GetUserByEmail(ctx, user.Email) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.get()
+			require.NoError(t, err)
+			require.NotNil(t, got)
+
+			assert.Equal(t, user.ID, got.ID)
+			assert.Equal(t, user.Email, got.Email)
+			assert.Equal(t, *user.CognitoID, *got.CognitoID)
+			assert.Equal(t, "user", *got.Role)
+			assert.Equal(t, user.Address, got.Address)
+			assert.Nil(t, got.ParentID)
+		})
+	}
+
+	cognitoUserID, err := repo.GetUserIdByCognitoId(ctx, *user.CognitoID)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, cognitoUserID)
+}
+
+func TestUserRepositoryMissingUser(t *testing.T) {
+	tx := pgtest.Tx(t)
+	repo := NewUserRepository(nil).WithTx(tx)
+	ctx := context.Background()
+
+	missingID := uuid.New().String()
+
+	userID, err := repo.GetUserIdByCognitoId(ctx, "cognito-unknown")
+	require.NoError(t, err)
+	assert.Empty(t, userID)
+
+	user, err := repo.GetUserByID(ctx, missingID)
+	require.NoError(t, err)
+	assert.Nil(t, user)
+
+	user, err = repo.GetUserByEmail(ctx, "nobody@example.com")
+	require.NoError(t, err)
+	assert.Nil(t, user)
+
+	err = repo.UpdateUser(ctx, &domain.User{ID: missingID})
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	_, err = repo.CheckHasParentID(ctx, missingID)
+	assert.Error(t, err)
+}
+
+func TestUserRepositoryParentLinks(t *testing.T) {
+	tx := pgtest.Tx(t)
+	repo := NewUserRepository(nil).WithTx(tx)
+	ctx := context.Background()
+
+	parent := seedUser(t, tx, nil)
+	first := seedUser(t, tx, nil)
+	second := seedUser(t, tx, nil)
+
+	hasParent, err := repo.CheckHasParentID(ctx, first.ID)
+	require.NoError(t, err)
+	assert.False(t, hasParent)
+
+	require.NoError(t, repo.UpdateUserParentID(ctx, first.ID, &parent.ID))
+	require.NoError(t, repo.UpdateUserParentID(ctx, second.ID, &parent.ID))
+
+	hasParent, err = repo.CheckHasParentID(ctx, first.ID)
+	require.NoError(t, err)
+	assert.True(t, hasParent)
+
+	children, err := repo.GetChildUsers(ctx, parent.ID)
+	require.NoError(t, err)
+	require.Len(t, children, 2)
+	for _, child := range children {
+		assert.Equal(t, parent.ID, *child.ParentID)
+	}
+
+	// A nil parent clears the link again
+	require.NoError(t, repo.UpdateUserParentID(ctx, second.ID, nil))
+	children, err = repo.GetChildUsers(ctx, parent.ID)
+	require.NoError(t, err)
+	require.Len(t, children, 1)
+	assert.Equal(t, first.ID, children[0].ID)
+}
+
+func TestUserRepositoryListReferredUsers(t *testing.T) {
+	tx := pgtest.Tx(t)
+	repo := NewUserRepository(nil).WithTx(tx)
+	ctx := context.Background()
+
+	referrer := seedUser(t, tx, nil)
+	var referred []string
+	for i := 0; i < 3; i++ {
+		referred = append(referred, seedUser(t, tx, &referrer.Email).ID)
+	}
+	seedUser(t, tx, nil)
+
+	tests := []struct {
+		name      string
+		userID    string
+		opts      domain.ListOptions
+		wantTotal int64
+		wantLen   int
+		wantNext  bool
+	}{
+		{"all on one page", referrer.ID, domain.ListOptions{}, 3, 3, false},
+		{"first page", referrer.ID, domain.ListOptions{Page: 1, PageSize: 2}, 3, 2, true},
+		{"last page", referrer.ID, domain.ListOptions{Page: 2, PageSize: 2}, 3, 1, false},
+		{"role filter", referrer.ID, domain.ListOptions{Filters: map[string]string{"role": "admin"}}, 0, 0, false},
+		{"unknown referrer", uuid.New().String(), domain.ListOptions{}, 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := repo.ListReferredUsers(ctx, tt.userID, tt.opts)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantTotal, page.TotalItems)
+			assert.Len(t, page.Items, tt.wantLen)
+			assert.Equal(t, tt.wantNext, page.NextCursor != "")
+			for _, user := range page.Items {
+				assert.Contains(t, referred, user.ID)
+				assert.Equal(t, referrer.Email, *user.ReferralMail)
+			}
+		})
+	}
+
+	t.Run("cursor continues where the previous page ended", func(t *testing.T) {
+		first, err := repo.ListReferredUsers(ctx, referrer.ID, domain.ListOptions{PageSize: 2, Sort: "-email"})
+		require.NoError(t, err)
+		require.NotEmpty(t, first.NextCursor)
+
+		second, err := repo.ListReferredUsers(ctx, referrer.ID, domain.ListOptions{PageSize: 2, Sort: "-email", Cursor: first.NextCursor})
+		require.NoError(t, err)
+		require.Len(t, second.Items, 1)
+		assert.Empty(t, second.NextCursor)
+
+		seen := []string{first.Items[0].ID, first.Items[1].ID, second.Items[0].ID}
+		assert.ElementsMatch(t, referred, seen)
+	})
+}
@@ -0,0 +1,67 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+)
+
+// SecretsManagerAPI is the part of the Secrets Manager client used by the provider
+type SecretsManagerAPI interface {
+	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// AWSSecretsManager reads secrets from AWS Secrets Manager
+type AWSSecretsManager struct {
+	client SecretsManagerAPI
+}
+
+// NewAWSSecretsManager creates a provider on the given Secrets Manager client
+func NewAWSSecretsManager(client SecretsManagerAPI) *AWSSecretsManager {
+	return &AWSSecretsManager{client: client}
+}
+
+// GetSecret returns the current version of a secret. A name of the form
+// "secret-id#key" selects one field of a JSON secret, such as the password of
+// the secrets RDS manages: "rds!db-1234#password".
+func (a *AWSSecretsManager) GetSecret(ctx context.Context, name string) (string, error) {
+	secretID, key, hasKey := strings.Cut(name, "#")
+
+	out, err := a.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(secretID),
+	})
+	if err != nil {
+		var notFound *types.ResourceNotFoundException
+		if errors.As(err, &notFound) {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+
+	value := aws.ToString(out.SecretString)
+	if out.SecretString == nil {
+		value = string(out.SecretBinary)
+	}
+	if !hasKey {
+		return value, nil
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return "", fmt.Errorf("secret %s is not a JSON object: %w", secretID, err)
+	}
+	field, ok := fields[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+	if s, ok := field.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprint(field), nil
+}
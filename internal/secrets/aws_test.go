@@ -0,0 +1,48 @@
+package secrets
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSecretsManager serves secret strings keyed by secret ID
+type fakeSecretsManager map[string]string
+
+func (f fakeSecretsManager) GetSecretValue(_ context.Context, params *secretsmanager.GetSecretValueInput, _ ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	value, ok := f[aws.ToString(params.SecretId)]
+	if !ok {
+		return nil, &types.ResourceNotFoundException{Message: aws.String("not found")}
+	}
+	return &secretsmanager.GetSecretValueOutput{SecretString: aws.String(value)}, nil
+}
+
+func TestAWSSecretsManager(t *testing.T) {
+	ctx := context.Background()
+	provider := NewAWSSecretsManager(fakeSecretsManager{
+		"zolaris/db-password": "s3cret",
+		"rds!db-1234":         `{"username":"zolaris","password":"r0tated"}`,
+	})
+
+	value, err := provider.GetSecret(ctx, "zolaris/db-password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", value)
+
+	value, err = provider.GetSecret(ctx, "rds!db-1234#password")
+	require.NoError(t, err)
+	assert.Equal(t, "r0tated", value)
+
+	_, err = provider.GetSecret(ctx, "rds!db-1234#token")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = provider.GetSecret(ctx, "zolaris/db-password#password")
+	assert.ErrorContains(t, err, "not a JSON object")
+
+	_, err = provider.GetSecret(ctx, "zolaris/missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
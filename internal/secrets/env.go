@@ -0,0 +1,23 @@
+package secrets
+
+import (
+	"context"
+	"os"
+)
+
+// Env reads secrets from environment variables named after the secret
+type Env struct{}
+
+// NewEnv creates a provider reading environment variables
+func NewEnv() *Env {
+	return &Env{}
+}
+
+// GetSecret returns the value of the environment variable name
+func (e *Env) GetSecret(_ context.Context, name string) (string, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
@@ -0,0 +1,41 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// File reads secrets from one file per secret, as mounted by Docker
+// (/run/secrets) or by Kubernetes secret volumes
+type File struct {
+	dir string
+}
+
+// NewFile creates a provider reading the files in dir
+func NewFile(dir string) *File {
+	return &File{dir: dir}
+}
+
+// GetSecret returns the content of the file name in the secrets directory, without
+// its trailing newline. name must not leave the directory.
+func (f *File) GetSecret(_ context.Context, name string) (string, error) {
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("invalid secret name %q", name)
+	}
+
+	// Kubernetes swaps a symlink to rotate mounted secrets, so the file is read afresh every time
+	data, err := os.ReadFile(filepath.Join(f.dir, name))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
@@ -0,0 +1,62 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/config"
+)
+
+// Providers selectable with SECRETS_PROVIDER
+const (
+	ProviderEnv  = "env"
+	ProviderFile = "file"
+	ProviderAWS  = "aws"
+)
+
+// Open creates the provider selected by the configuration
+func Open(ctx context.Context, cfg *config.Config) (Provider, error) {
+	switch cfg.Secrets.Provider {
+	case ProviderEnv, "":
+		return NewEnv(), nil
+	case ProviderFile:
+		return NewFile(cfg.Secrets.Dir), nil
+	case ProviderAWS:
+		awsCfg, err := awsconfig.LoadDefaultConfig(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load AWS config: %w", err)
+		}
+		return NewAWSSecretsManager(secretsmanager.NewFromConfig(awsCfg)), nil
+	default:
+		return nil, fmt.Errorf("unknown secrets provider %q", cfg.Secrets.Provider)
+	}
+}
+
+// PostgresPassword watches the secret named by POSTGRES_PASSWORD_SECRET in the
+// configured provider. It returns nil if none is named, in which case the
+// password comes from the connection settings.
+func PostgresPassword(ctx context.Context, cfg *config.Config) (*Secret, error) {
+	return watchNamed(ctx, cfg, cfg.Database.PostgresPasswordSecret)
+}
+
+// JWTPublicKey watches the secret named by JWT_PUBLIC_KEY_SECRET, the PEM encoded
+// RSA public key a utils.JWTVerifier checks tokens with. It returns nil if none is named.
+func JWTPublicKey(ctx context.Context, cfg *config.Config) (*Secret, error) {
+	return watchNamed(ctx, cfg, cfg.Server.JWTPublicKeySecret)
+}
+
+// watchNamed watches the secret name in the configured provider, or returns nil if name is empty
+func watchNamed(ctx context.Context, cfg *config.Config, name string) (*Secret, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	provider, err := Open(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return Watch(ctx, provider, name, cfg.Secrets.RefreshPeriod)
+}
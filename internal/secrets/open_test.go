@@ -0,0 +1,28 @@
+package secrets
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/config"
+)
+
+func TestJWTPublicKey(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{Secrets: config.SecretsConfig{Provider: ProviderEnv}}
+
+	// Without a secret name there is nothing to watch
+	secret, err := JWTPublicKey(ctx, cfg)
+	require.NoError(t, err)
+	assert.Nil(t, secret)
+
+	t.Setenv("ZOLARIS_TEST_JWT_KEY", "-----BEGIN PUBLIC KEY-----")
+	cfg.Server.JWTPublicKeySecret = "ZOLARIS_TEST_JWT_KEY"
+	secret, err = JWTPublicKey(ctx, cfg)
+	require.NoError(t, err)
+	defer secret.Close()
+	assert.Equal(t, "-----BEGIN PUBLIC KEY-----", secret.Value())
+}
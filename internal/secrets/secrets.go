@@ -0,0 +1,123 @@
+// Package secrets reads credentials from the environment, from mounted secret
+// files or from AWS Secrets Manager, and keeps them up to date so they can be
+// rotated without a restart.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrNotFound is returned when a provider has no secret with the requested name
+var ErrNotFound = errors.New("secret not found")
+
+// Provider resolves secret values by name
+type Provider interface {
+	GetSecret(ctx context.Context, name string) (string, error)
+}
+
+// Secret is a secret value that is re-read from its provider periodically.
+// The last good value is kept when a refresh fails.
+type Secret struct {
+	provider Provider
+	name     string
+	value    atomic.Pointer[string]
+
+	mu       sync.Mutex
+	onChange []func(value string) error
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// Watch reads the secret name from provider and re-reads it every period until
+// Close is called. It fails if the first read fails. A non-positive period reads
+// the secret only once.
+func Watch(ctx context.Context, provider Provider, name string, period time.Duration) (*Secret, error) {
+	s := &Secret{
+		provider: provider,
+		name:     name,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	if err := s.Refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	if period <= 0 {
+		close(s.done)
+		return s, nil
+	}
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				refreshCtx, cancel := context.WithTimeout(context.Background(), period)
+				if err := s.Refresh(refreshCtx); err != nil {
+					slog.Warn("Keeping the current value of secret", "secret", s.name, "error", err)
+				}
+				cancel()
+			}
+		}
+	}()
+
+	return s, nil
+}
+
+// Value returns the current value of the secret
+func (s *Secret) Value() string {
+	return *s.value.Load()
+}
+
+// Refresh re-reads the secret from its provider
+func (s *Secret) Refresh(ctx context.Context) error {
+	value, err := s.provider.GetSecret(ctx, s.name)
+	if err != nil {
+		return fmt.Errorf("failed to read secret %s: %w", s.name, err)
+	}
+
+	if previous := s.value.Swap(&value); previous != nil && *previous != value {
+		slog.InfoContext(ctx, "Secret was rotated", "secret", s.name)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for _, fn := range s.onChange {
+			if err := fn(value); err != nil {
+				slog.WarnContext(ctx, "Failed to apply rotated secret", "secret", s.name, "error", err)
+			}
+		}
+	}
+	return nil
+}
+
+// OnChange registers fn to be called with the new value whenever a refresh
+// finds the secret rotated, so values derived from it are only rebuilt then.
+// An error from fn is logged; whatever fn derived before stays in use.
+func (s *Secret) OnChange(fn func(value string) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onChange = append(s.onChange, fn)
+}
+
+// Close stops the periodic refresh
+func (s *Secret) Close() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+		<-s.done
+	})
+}
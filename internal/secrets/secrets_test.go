@@ -0,0 +1,122 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProvider returns whatever value or error was set last
+type fakeProvider struct {
+	mu    sync.Mutex
+	value string
+	err   error
+}
+
+func (p *fakeProvider) set(value string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.value, p.err = value, err
+}
+
+func (p *fakeProvider) GetSecret(context.Context, string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.value, p.err
+}
+
+func TestWatch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("first read must succeed", func(t *testing.T) {
+		provider := &fakeProvider{err: ErrNotFound}
+
+		_, err := Watch(ctx, provider, "db-password", time.Minute)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("refresh picks up rotated values and keeps the last good one", func(t *testing.T) {
+		provider := &fakeProvider{value: "first"}
+
+		secret, err := Watch(ctx, provider, "db-password", 0)
+		require.NoError(t, err)
+		defer secret.Close()
+		assert.Equal(t, "first", secret.Value())
+
+		provider.set("second", nil)
+		require.NoError(t, secret.Refresh(ctx))
+		assert.Equal(t, "second", secret.Value())
+
+		provider.set("", errors.New("throttled"))
+		assert.Error(t, secret.Refresh(ctx))
+		assert.Equal(t, "second", secret.Value())
+	})
+
+	t.Run("rotation notifies the listeners", func(t *testing.T) {
+		provider := &fakeProvider{value: "first"}
+
+		secret, err := Watch(ctx, provider, "jwt-key", 0)
+		require.NoError(t, err)
+		defer secret.Close()
+
+		var seen []string
+		secret.OnChange(func(value string) error {
+			seen = append(seen, value)
+			return errors.New("not a key")
+		})
+
+		require.NoError(t, secret.Refresh(ctx))
+		assert.Empty(t, seen, "an unchanged value is not passed on")
+
+		provider.set("second", nil)
+		require.NoError(t, secret.Refresh(ctx), "a failing listener does not fail the refresh")
+		assert.Equal(t, []string{"second"}, seen)
+	})
+
+	t.Run("periodic refresh", func(t *testing.T) {
+		provider := &fakeProvider{value: "first"}
+
+		secret, err := Watch(ctx, provider, "db-password", 5*time.Millisecond)
+		require.NoError(t, err)
+		defer secret.Close()
+
+		provider.set("second", nil)
+		assert.Eventually(t, func() bool { return secret.Value() == "second" }, time.Second, 5*time.Millisecond)
+	})
+}
+
+func TestFile(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db-password"), []byte("s3cret\n"), 0600))
+	provider := NewFile(dir)
+
+	value, err := provider.GetSecret(ctx, "db-password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", value)
+
+	_, err = provider.GetSecret(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = provider.GetSecret(ctx, "../db-password")
+	assert.ErrorContains(t, err, "invalid secret name")
+}
+
+func TestEnv(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("ZOLARIS_TEST_SECRET", "s3cret")
+
+	value, err := NewEnv().GetSecret(ctx, "ZOLARIS_TEST_SECRET")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", value)
+
+	_, err = NewEnv().GetSecret(ctx, "ZOLARIS_TEST_SECRET_UNSET")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
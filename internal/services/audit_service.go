@@ -0,0 +1,159 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+	"github.com/afreedicp/zolaris-backend-app/internal/logging"
+	"github.com/afreedicp/zolaris-backend-app/internal/repositories"
+	"github.com/afreedicp/zolaris-backend-app/internal/tracing"
+)
+
+// auditIgnoredFields change on every write, so they are left out of audit diffs
+var auditIgnoredFields = map[string]bool{"createdAt": true, "updatedAt": true}
+
+type clientIPKey struct{}
+
+// WithClientIP returns a copy of ctx carrying the IP of the client, which is
+// recorded with the audit entries of the request
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// AuditService records the mutating actions of the other services in the
+// append-only audit log, and queries it
+type AuditService struct {
+	repo repositories.AuditRepositoryInterface
+}
+
+// NewAuditService creates a new audit service instance
+func NewAuditService(repo repositories.AuditRepositoryInterface) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// WithTx returns a copy of the service that records in tx, so an entry is
+// only kept if the action it records is committed
+func (s *AuditService) WithTx(tx pgx.Tx) *AuditService {
+	return &AuditService{repo: s.repo.WithTx(tx)}
+}
+
+// Record records entry with the fields that differ between before and after,
+// which are any values encoding to JSON objects; nil stands for no fields.
+// The client IP and request ID are taken from ctx.
+func (s *AuditService) Record(ctx context.Context, entry *domain.AuditEntry, before, after any) error {
+	changes, err := auditDiff(before, after)
+	if err != nil {
+		return fmt.Errorf("failed to diff audited %s: %w", entry.ResourceType, err)
+	}
+	entry.Changes = changes
+
+	if ip, _ := ctx.Value(clientIPKey{}).(string); ip != "" {
+		entry.IP = &ip
+	}
+	if requestID := logging.RequestID(ctx); requestID != "" {
+		entry.RequestID = &requestID
+	}
+
+	return s.repo.RecordAuditEntry(ctx, entry)
+}
+
+// RecordCommitted records an action outside of its transaction, once it has
+// been committed. As the action cannot be undone any more, a failure is
+// logged rather than returned.
+func (s *AuditService) RecordCommitted(ctx context.Context, entry *domain.AuditEntry, before, after any) {
+	if err := s.Record(ctx, entry, before, after); err != nil {
+		slog.ErrorContext(ctx, "Failed to record audit entry",
+			"action", entry.Action,
+			"resource_type", entry.ResourceType,
+			"resource_id", entry.ResourceID,
+			"error", err,
+		)
+	}
+}
+
+// ListEntries returns one page of the audit entries matching filter
+func (s *AuditService) ListEntries(ctx context.Context, filter domain.AuditFilter, opts domain.ListOptions) (*domain.Page[*domain.AuditEntry], error) {
+	ctx, span := tracing.Start(ctx, "AuditService.ListEntries")
+	defer span.End()
+
+	return s.repo.ListAuditEntries(ctx, filter, opts)
+}
+
+// ExportEntries passes every audit entry matching filter to fn, oldest first
+func (s *AuditService) ExportEntries(ctx context.Context, filter domain.AuditFilter, fn func(entry *domain.AuditEntry) error) error {
+	ctx, span := tracing.Start(ctx, "AuditService.ExportEntries")
+	defer span.End()
+
+	slog.InfoContext(ctx, "Exporting audit log", "filter", fmt.Sprintf("%+v", filter))
+	return s.repo.ExportAuditEntries(ctx, filter, fn)
+}
+
+// auditDiff returns the top-level fields whose JSON encoding differs between before and after
+func auditDiff(before, after any) (map[string]domain.AuditChange, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]domain.AuditChange)
+	for name, value := range beforeFields {
+		if other, ok := afterFields[name]; !ok || !bytes.Equal(value, other) {
+			changes[name] = domain.AuditChange{Before: value, After: afterFields[name]}
+		}
+	}
+	for name, value := range afterFields {
+		if _, ok := beforeFields[name]; !ok {
+			changes[name] = domain.AuditChange{After: value}
+		}
+	}
+	return changes, nil
+}
+
+// auditFields encodes v and splits it into its compacted top-level fields
+func auditFields(v any) (map[string]json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if string(data) == "null" {
+		return nil, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("audited value is not an object: %w", err)
+	}
+	for name, value := range fields {
+		if auditIgnoredFields[name] {
+			delete(fields, name)
+			continue
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, value); err != nil {
+			return nil, err
+		}
+		fields[name] = compact.Bytes()
+	}
+	return fields, nil
+}
+
+// auditActor returns the actor of an audit entry, nil for an anonymous client
+func auditActor(userID string) *string {
+	if userID == "" {
+		return nil
+	}
+	return &userID
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+	"github.com/afreedicp/zolaris-backend-app/internal/logging"
+	"github.com/afreedicp/zolaris-backend-app/internal/repositories"
+)
+
+// recordingAuditRepo keeps the recorded audit entries
+type recordingAuditRepo struct {
+	repositories.AuditRepositoryInterface
+	entries []*domain.AuditEntry
+}
+
+func (r *recordingAuditRepo) RecordAuditEntry(_ context.Context, entry *domain.AuditEntry) error {
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func TestAuditDiff(t *testing.T) {
+	type details struct {
+		Name      string  `json:"name"`
+		Phone     *string `json:"phone"`
+		UpdatedAt string  `json:"updatedAt"`
+	}
+	phone := "+100"
+
+	changes, err := auditDiff(
+		details{Name: "Hall", UpdatedAt: "monday"},
+		map[string]any{"name": "Lab", "phone": phone, "updatedAt": "tuesday", "floor": 2},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]domain.AuditChange{
+		"name":  {Before: json.RawMessage(`"Hall"`), After: json.RawMessage(`"Lab"`)},
+		"phone": {Before: json.RawMessage(`null`), After: json.RawMessage(`"+100"`)},
+		"floor": {After: json.RawMessage(`2`)},
+	}, changes)
+
+	// Unchanged fields are left out, whatever their formatting
+	changes, err = auditDiff(json.RawMessage(`{"details": {"a": 1}}`), json.RawMessage(`{ "details":{ "a":1 } }`))
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	// A deleted value has no after
+	changes, err = auditDiff(map[string]string{"name": "Hall"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]domain.AuditChange{"name": {Before: json.RawMessage(`"Hall"`)}}, changes)
+
+	_, err = auditDiff(nil, []string{"not", "an", "object"})
+	assert.Error(t, err)
+}
+
+func TestAuditServiceRecord(t *testing.T) {
+	repo := &recordingAuditRepo{}
+	service := NewAuditService(repo)
+
+	ctx := logging.With(context.Background(), logging.RequestIDKey, "req-1")
+	ctx = WithClientIP(ctx, "203.0.113.7")
+	entry := &domain.AuditEntry{
+		ActorID:      auditActor("user-1"),
+		Action:       domain.AuditActionCreate,
+		ResourceType: domain.AuditResourceDevice,
+		ResourceID:   "AA:00:00:00:00:01",
+	}
+	require.NoError(t, service.Record(ctx, entry, nil, map[string]string{"name": "Meter"}))
+
+	require.Len(t, repo.entries, 1)
+	recorded := repo.entries[0]
+	assert.Equal(t, "user-1", *recorded.ActorID)
+	assert.Equal(t, "203.0.113.7", *recorded.IP)
+	assert.Equal(t, "req-1", *recorded.RequestID)
+	assert.Equal(t, json.RawMessage(`"Meter"`), recorded.Changes["name"].After)
+
+	// Actions outside of a request have no client IP or request ID
+	entry = &domain.AuditEntry{ActorID: auditActor(""), Action: domain.AuditActionCreate, ResourceType: domain.AuditResourceUser}
+	service.RecordCommitted(context.Background(), entry, nil, nil)
+	require.Len(t, repo.entries, 2)
+	assert.Nil(t, repo.entries[1].ActorID)
+	assert.Nil(t, repo.entries[1].IP)
+	assert.Nil(t, repo.entries[1].RequestID)
+	assert.Empty(t, repo.entries[1].Changes)
+}
@@ -2,27 +2,46 @@ package services
 
 import (
 	"context"
-	"errors"
-	"log"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/afreedicp/zolaris-backend-app/internal/cache"
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
 	"github.com/afreedicp/zolaris-backend-app/internal/repositories"
+	"github.com/afreedicp/zolaris-backend-app/internal/tracing"
 	"github.com/afreedicp/zolaris-backend-app/internal/transport/dto"
 	"github.com/afreedicp/zolaris-backend-app/internal/transport/mappers"
 )
 
+// categoryCacheTTL bounds how stale cached category lists can be on instances
+// that did not make a change themselves. The lists are loaded from the primary, as
+// a lagging replica would put the changes just invalidated back into the cache.
+const categoryCacheTTL = 5 * time.Minute
+
+// categoryCachePrefix prefixes every cached category list
+const categoryCachePrefix = "category:"
+
 // CategoryService handles business logic for category operations
 type CategoryService struct {
-	categoryRepo *repositories.CategoryRepository
+	categoryRepo repositories.CategoryRepositoryInterface
+	cache        *cache.Cache
+	audit        *AuditService
 }
 
 // NewCategoryService creates a new category service instance
-func NewCategoryService(categoryRepo *repositories.CategoryRepository) *CategoryService {
-	return &CategoryService{categoryRepo: categoryRepo}
+func NewCategoryService(categoryRepo repositories.CategoryRepositoryInterface, cache *cache.Cache, audit *AuditService) *CategoryService {
+	return &CategoryService{categoryRepo: categoryRepo, cache: cache, audit: audit}
 }
 
 // AddCategory handles the business logic for adding a new category
 func (s *CategoryService) AddCategory(ctx context.Context, name, categoryType string) error {
-	log.Printf("Adding category %s of type %s", name, categoryType)
+	ctx, span := tracing.Start(ctx, "CategoryService.AddCategory")
+	defer span.End()
+
+	slog.InfoContext(ctx, "Adding category", "name", name, "type", categoryType)
 
 	// Check if category already exists
 	existingCategory, err := s.categoryRepo.GetCategoryByName(ctx, name)
@@ -31,15 +50,30 @@ func (s *CategoryService) AddCategory(ctx context.Context, name, categoryType st
 	}
 
 	if existingCategory != nil {
-		return errors.New("category with this name already exists")
+		return domain.NewConflictError("category with this name already exists")
 	}
 
-	return s.categoryRepo.AddCategory(ctx, name, categoryType)
+	if err := s.categoryRepo.AddCategory(ctx, name, categoryType); err != nil {
+		return err
+	}
+
+	s.cache.InvalidatePrefix(ctx, categoryCachePrefix)
+
+	// Categories are only known by name until they are read back
+	s.audit.RecordCommitted(ctx, &domain.AuditEntry{
+		Action:       domain.AuditActionCreate,
+		ResourceType: domain.AuditResourceCategory,
+		ResourceID:   name,
+	}, nil, map[string]string{"name": name, "type": categoryType})
+	return nil
 }
 
 // GetCategoryByName retrieves a category by its name
 func (s *CategoryService) GetCategoryByName(ctx context.Context, name string) (*dto.CategoryResponse, error) {
-	log.Printf("Getting category with name %s", name)
+	ctx, span := tracing.Start(ctx, "CategoryService.GetCategoryByName")
+	defer span.End()
+
+	slog.DebugContext(ctx, "Getting category", "name", name)
 	category, err := s.categoryRepo.GetCategoryByName(ctx, name)
 	if err != nil {
 		return nil, err
@@ -53,8 +87,14 @@ func (s *CategoryService) GetCategoryByName(ctx context.Context, name string) (*
 	return nil, nil
 } // GetCategoriesByType retrieves all categories of a specific type
 func (s *CategoryService) GetCategoriesByType(ctx context.Context, categoryType string) ([]*dto.CategoryResponse, error) {
-	log.Printf("Getting categories of type %s", categoryType)
-	categories, err := s.categoryRepo.GetCategoriesByType(ctx, categoryType)
+	ctx, span := tracing.Start(ctx, "CategoryService.GetCategoriesByType")
+	defer span.End()
+
+	slog.DebugContext(ctx, "Getting categories", "type", categoryType)
+	categories, err := cache.GetOrLoad(ctx, s.cache, categoryCachePrefix+"type:"+categoryType, categoryCacheTTL,
+		func(ctx context.Context) ([]*domain.Category, error) {
+			return s.fillRepo().GetCategoriesByType(ctx, categoryType)
+		})
 	if err != nil {
 		return nil, err
 	}
@@ -62,12 +102,33 @@ func (s *CategoryService) GetCategoriesByType(ctx context.Context, categoryType
 	return mappers.CategoriesToResponses(categories), nil
 }
 
-func (s *CategoryService) GetAllCategories(ctx context.Context) ([]*dto.CategoryResponse, error) {
-	log.Println("List all categories")
-	categories, err := s.categoryRepo.ListAllCategories(ctx)
-	if err != nil {
-		return nil, err
+func (s *CategoryService) GetAllCategories(ctx context.Context, opts domain.ListOptions) (*domain.Page[*domain.Category], error) {
+	ctx, span := tracing.Start(ctx, "CategoryService.GetAllCategories")
+	defer span.End()
+
+	slog.DebugContext(ctx, "Listing all categories")
+	return cache.GetOrLoad(ctx, s.cache, categoryCachePrefix+"all:"+listOptionsKey(opts), categoryCacheTTL,
+		func(ctx context.Context) (*domain.Page[*domain.Category], error) {
+			return s.fillRepo().ListAllCategories(ctx, opts)
+		})
+}
+
+// fillRepo returns the repository cache misses are loaded from: one avoiding a
+// lagging replica, unless nothing is cached
+func (s *CategoryService) fillRepo() repositories.CategoryRepositoryInterface {
+	if s.cache.Disabled() {
+		return s.categoryRepo
 	}
+	return s.categoryRepo.WithMaxLag(cacheFillMaxLag)
+}
 
-	return mappers.CategoriesToResponses(categories), nil
+// listOptionsKey renders list options as a stable cache key fragment
+func listOptionsKey(opts domain.ListOptions) string {
+	filters := make([]string, 0, len(opts.Filters))
+	for field, value := range opts.Filters {
+		filters = append(filters, field+"="+value)
+	}
+	slices.Sort(filters)
+
+	return fmt.Sprintf("%d:%d:%s:%s:%s", opts.Page, opts.PageSize, opts.Sort, opts.Cursor, strings.Join(filters, "&"))
 }
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/cache"
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+	"github.com/afreedicp/zolaris-backend-app/internal/repositories"
+)
+
+// routedCategoryRepo records the replica lag tolerance category lists were read with
+type routedCategoryRepo struct {
+	repositories.CategoryRepositoryInterface
+	maxLag time.Duration
+	reads  *[]time.Duration
+}
+
+func (r *routedCategoryRepo) WithMaxLag(maxLag time.Duration) repositories.CategoryRepositoryInterface {
+	return &routedCategoryRepo{maxLag: maxLag, reads: r.reads}
+}
+
+func (r *routedCategoryRepo) GetCategoriesByType(_ context.Context, categoryType string) ([]*domain.Category, error) {
+	*r.reads = append(*r.reads, r.maxLag)
+	return []*domain.Category{{Name: "branch", Type: categoryType}}, nil
+}
+
+func TestCategoryCacheFillLagTolerance(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("cached lists only tolerate a small replica lag", func(t *testing.T) {
+		var reads []time.Duration
+		service := NewCategoryService(&routedCategoryRepo{reads: &reads}, cache.New(cache.NewLRU(10)), nil)
+
+		for i := 0; i < 2; i++ {
+			categories, err := service.GetCategoriesByType(ctx, "office")
+			require.NoError(t, err)
+			require.Len(t, categories, 1)
+		}
+
+		// A lagging replica would cache again what a write has just invalidated
+		assert.Equal(t, []time.Duration{cacheFillMaxLag}, reads)
+	})
+
+	t.Run("without a cache reads use the normal routing", func(t *testing.T) {
+		var reads []time.Duration
+		service := NewCategoryService(&routedCategoryRepo{reads: &reads}, cache.New(cache.NewNoop()), nil)
+
+		for i := 0; i < 2; i++ {
+			_, err := service.GetCategoriesByType(ctx, "office")
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, []time.Duration{0, 0}, reads)
+	})
+}
@@ -2,55 +2,72 @@ package services
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"strconv"
 	"time"
 
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
 	"github.com/afreedicp/zolaris-backend-app/internal/repositories"
+	"github.com/afreedicp/zolaris-backend-app/internal/tracing"
 	"github.com/afreedicp/zolaris-backend-app/internal/transport/dto"
 	"github.com/afreedicp/zolaris-backend-app/internal/transport/mappers"
 )
 
 // DeviceService handles business logic for device operations
 type DeviceService struct {
-	deviceRepo *repositories.DeviceRepository
+	deviceRepo repositories.DeviceRepositoryInterface
+	audit      *AuditService
 }
 
 // NewDeviceService creates a new device service instance
-func NewDeviceService(deviceRepo *repositories.DeviceRepository) *DeviceService {
-	return &DeviceService{deviceRepo: deviceRepo}
+func NewDeviceService(deviceRepo repositories.DeviceRepositoryInterface, audit *AuditService) *DeviceService {
+	return &DeviceService{deviceRepo: deviceRepo, audit: audit}
 }
 
 // AddDevice handles the business logic for adding a new device
 func (s *DeviceService) AddDevice(ctx context.Context, deviceID, deviceName, userID string) error {
+	ctx, span := tracing.Start(ctx, "DeviceService.AddDevice")
+	defer span.End()
+
 	// Add any business logic here (validation, etc.)
-	log.Printf("Adding device %s for user %s", deviceID, userID)
-	return s.deviceRepo.AddDevice(ctx, deviceID, deviceName, userID)
+	slog.InfoContext(ctx, "Adding device", "device_id", deviceID)
+	if err := s.deviceRepo.AddDevice(ctx, deviceID, deviceName, userID); err != nil {
+		return err
+	}
+
+	s.audit.RecordCommitted(ctx, &domain.AuditEntry{
+		ActorID:      auditActor(userID),
+		Action:       domain.AuditActionCreate,
+		ResourceType: domain.AuditResourceDevice,
+		ResourceID:   deviceID,
+	}, nil, map[string]string{"name": deviceName, "userId": userID})
+	return nil
 }
 
 // GetUserDevices retrieves all devices for a user
-func (s *DeviceService) GetUserDevices(ctx context.Context, userID string) ([]*dto.DeviceResponse, error) {
-	log.Printf("Getting devices for user %s", userID)
-	devices, err := s.deviceRepo.GetDevicesByUserID(ctx, userID)
-	if err != nil {
-		return nil, err
-	}
+func (s *DeviceService) GetUserDevices(ctx context.Context, userID string, opts domain.ListOptions) (*domain.Page[*domain.Device], error) {
+	ctx, span := tracing.Start(ctx, "DeviceService.GetUserDevices")
+	defer span.End()
 
-	return mappers.DevicesToResponses(devices), nil
+	slog.DebugContext(ctx, "Getting devices", "owner_id", userID)
+	return s.deviceRepo.GetDevicesByUserID(ctx, userID, opts)
 }
 
 // GetDeviceSensorData retrieves sensor data for a device within a time range
 func (s *DeviceService) GetDeviceSensorData(ctx context.Context, macID, dateMode string, timestamp string) ([]*dto.SensorDataResponse, error) {
+	ctx, span := tracing.Start(ctx, "DeviceService.GetDeviceSensorData")
+	defer span.End()
+
 	// Parse the int64 timestamp from the string
 	timestampMs, err := strconv.ParseInt(timestamp, 10, 64)
 	if err != nil {
-		log.Printf("Error parsing timestamp: %v", err)
+		slog.WarnContext(ctx, "Error parsing timestamp", "timestamp", timestamp, "error", err)
 		return nil, err
 	}
 
 	// Calculate time range based on dateMode
-	startTime, endTime := s.calculateTimeRange(timestampMs, dateMode)
-	log.Printf("Getting sensor data for device %s from %d to %d", macID, startTime, endTime)
+	startTime, endTime := calculateTimeRange(timestampMs, dateMode)
+	slog.DebugContext(ctx, "Getting sensor data", "device", macID, "start", startTime, "end", endTime)
 
 	// Get raw sensor data
 	sensorData, err := s.deviceRepo.GetSensorData(ctx, macID, startTime, endTime)
@@ -63,7 +80,7 @@ func (s *DeviceService) GetDeviceSensorData(ctx context.Context, macID, dateMode
 }
 
 // calculateTimeRange calculates a time range looking backward from the provided timestamp
-func (s *DeviceService) calculateTimeRange(baseTimeMs int64, dateMode string) (int64, int64) {
+func calculateTimeRange(baseTimeMs int64, dateMode string) (int64, int64) {
 	// Convert milliseconds to seconds and nanoseconds for time package
 	seconds := baseTimeMs / 1000
 	nanoseconds := (baseTimeMs % 1000) * 1000000
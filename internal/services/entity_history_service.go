@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+	"github.com/afreedicp/zolaris-backend-app/internal/repositories"
+	"github.com/afreedicp/zolaris-backend-app/internal/tracing"
+)
+
+// defaultHistoryLimit is the number of history entries returned when no limit is given
+const defaultHistoryLimit = 100
+
+// EntityHistoryService provides the change history of entities and diffs of entity trees over time
+type EntityHistoryService struct {
+	historyRepo repositories.EntityHistoryRepositoryInterface
+	entityRepo  repositories.EntityRepositoryInterface
+}
+
+// NewEntityHistoryService creates a new entity history service instance
+func NewEntityHistoryService(historyRepo repositories.EntityHistoryRepositoryInterface, entityRepo repositories.EntityRepositoryInterface) *EntityHistoryService {
+	return &EntityHistoryService{
+		historyRepo: historyRepo,
+		entityRepo:  entityRepo,
+	}
+}
+
+// GetEntityHistory returns the recorded changes of an entity, newest first
+func (s *EntityHistoryService) GetEntityHistory(ctx context.Context, userId string, entityId string, limit int) ([]*domain.EntityHistoryEntry, error) {
+	ctx, span := tracing.Start(ctx, "EntityHistoryService.GetEntityHistory")
+	defer span.End()
+
+	if entityId == "" {
+		return nil, domain.NewValidationError("entity ID cannot be empty")
+	}
+
+	if err := s.checkAccess(ctx, userId, entityId); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+
+	return s.historyRepo.ListEntityHistory(ctx, entityId, limit)
+}
+
+// DiffSubtree compares the subtree rooted at entityId between two points in time
+func (s *EntityHistoryService) DiffSubtree(ctx context.Context, userId string, entityId string, from, to time.Time) (*domain.EntityTreeDiff, error) {
+	ctx, span := tracing.Start(ctx, "EntityHistoryService.DiffSubtree")
+	defer span.End()
+
+	if entityId == "" {
+		return nil, domain.NewValidationError("entity ID cannot be empty")
+	}
+
+	if !from.Before(to) {
+		return nil, domain.NewValidationError("from must be before to")
+	}
+
+	if err := s.checkAccess(ctx, userId, entityId); err != nil {
+		return nil, err
+	}
+
+	before, err := s.historyRepo.GetSubtreeSnapshot(ctx, entityId, from)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := s.historyRepo.GetSubtreeSnapshot(ctx, entityId, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := diffSnapshots(before, after)
+	diff.From = from
+	diff.To = to
+
+	return diff, nil
+}
+
+// checkAccess ensures the entity is within the user's subtrees
+func (s *EntityHistoryService) checkAccess(ctx context.Context, userId string, entityId string) error {
+	accessible, err := s.entityRepo.IsEntityAccessible(ctx, userId, entityId)
+	if err != nil {
+		return err
+	}
+	if !accessible {
+		return ErrEntityNotAccessible
+	}
+	return nil
+}
+
+// diffSnapshots lists the entities added, removed, moved and renamed between two snapshots of a subtree
+func diffSnapshots(before, after []domain.EntitySnapshot) *domain.EntityTreeDiff {
+	diff := &domain.EntityTreeDiff{
+		Added:   []domain.EntitySnapshot{},
+		Removed: []domain.EntitySnapshot{},
+		Moved:   []domain.EntityMove{},
+		Renamed: []domain.EntityRename{},
+	}
+
+	previous := make(map[string]domain.EntitySnapshot, len(before))
+	for _, entity := range before {
+		previous[entity.EntityID] = entity
+	}
+
+	current := make(map[string]bool, len(after))
+	for _, entity := range after {
+		current[entity.EntityID] = true
+
+		old, existed := previous[entity.EntityID]
+		if !existed {
+			diff.Added = append(diff.Added, entity)
+			continue
+		}
+
+		if !sameParent(old.ParentID, entity.ParentID) {
+			diff.Moved = append(diff.Moved, domain.EntityMove{
+				EntityID:     entity.EntityID,
+				Name:         entity.Name,
+				FromParentID: old.ParentID,
+				ToParentID:   entity.ParentID,
+			})
+		}
+
+		if old.Name != entity.Name {
+			diff.Renamed = append(diff.Renamed, domain.EntityRename{
+				EntityID: entity.EntityID,
+				FromName: old.Name,
+				ToName:   entity.Name,
+			})
+		}
+	}
+
+	for _, entity := range before {
+		if !current[entity.EntityID] {
+			diff.Removed = append(diff.Removed, entity)
+		}
+	}
+
+	return diff
+}
+
+// sameParent compares two nullable parent IDs
+func sameParent(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	root, floor1, floor2 := "root", "floor-1", "floor-2"
+
+	before := []domain.EntitySnapshot{
+		{EntityID: root, Name: "HQ"},
+		{EntityID: floor1, Name: "Floor 1", ParentID: &root},
+		{EntityID: floor2, Name: "Floor 2", ParentID: &root},
+		{EntityID: "room-a", Name: "Room A", ParentID: &floor1},
+		{EntityID: "room-b", Name: "Room B", ParentID: &floor1},
+	}
+	after := []domain.EntitySnapshot{
+		{EntityID: root, Name: "Headquarters"},
+		{EntityID: floor1, Name: "Floor 1", ParentID: &root},
+		{EntityID: floor2, Name: "Floor 2", ParentID: &root},
+		{EntityID: "room-a", Name: "Room A1", ParentID: &floor2},
+		{EntityID: "room-c", Name: "Room C", ParentID: &floor1},
+	}
+
+	diff := diffSnapshots(before, after)
+
+	assert.Equal(t, []domain.EntitySnapshot{after[4]}, diff.Added)
+	assert.Equal(t, []domain.EntitySnapshot{before[4]}, diff.Removed)
+	assert.Equal(t, []domain.EntityMove{{EntityID: "room-a", Name: "Room A1", FromParentID: &floor1, ToParentID: &floor2}}, diff.Moved)
+	assert.Equal(t, []domain.EntityRename{
+		{EntityID: root, FromName: "HQ", ToName: "Headquarters"},
+		{EntityID: "room-a", FromName: "Room A", ToName: "Room A1"},
+	}, diff.Renamed)
+}
+
+func TestDiffSubtreeRejectsInvalidInput(t *testing.T) {
+	service := NewEntityHistoryService(nil, nil)
+	ctx := context.Background()
+	now := time.Now()
+
+	// Input errors are validation errors, so they are answered with 400
+	_, err := service.DiffSubtree(ctx, "user-1", "", now.Add(-time.Hour), now)
+	assert.ErrorIs(t, err, domain.ErrValidation)
+
+	_, err = service.DiffSubtree(ctx, "user-1", "entity-1", now, now.Add(-time.Hour))
+	assert.ErrorIs(t, err, domain.ErrValidation)
+}
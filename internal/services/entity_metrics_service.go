@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+	"github.com/afreedicp/zolaris-backend-app/internal/repositories"
+	"github.com/afreedicp/zolaris-backend-app/internal/tracing"
+)
+
+// defaultMetricsConcurrency bounds how many devices are queried for sensor data at once
+const defaultMetricsConcurrency = 8
+
+// EntityMetricsService rolls device sensor data up the entity hierarchy
+type EntityMetricsService struct {
+	entityRepo  repositories.EntityRepositoryInterface
+	deviceRepo  repositories.DeviceRepositoryInterface
+	concurrency int
+}
+
+// NewEntityMetricsService creates a new entity metrics service instance
+func NewEntityMetricsService(entityRepo repositories.EntityRepositoryInterface, deviceRepo repositories.DeviceRepositoryInterface) *EntityMetricsService {
+	return &EntityMetricsService{
+		entityRepo:  entityRepo,
+		deviceRepo:  deviceRepo,
+		concurrency: defaultMetricsConcurrency,
+	}
+}
+
+// GetSubtreeMetrics aggregates the sensor readings of every device in the subtree rooted at entityId,
+// for the whole subtree and for each direct child of the root.
+// The time range is calculated from timestampMs and dateMode as for device sensor data.
+func (s *EntityMetricsService) GetSubtreeMetrics(ctx context.Context, userId string, entityId string, timestampMs int64, dateMode string) (*domain.SubtreeMetrics, error) {
+	ctx, span := tracing.Start(ctx, "EntityMetricsService.GetSubtreeMetrics")
+	defer span.End()
+
+	if entityId == "" {
+		return nil, domain.NewValidationError("entity ID cannot be empty")
+	}
+
+	accessible, err := s.entityRepo.IsEntityAccessible(ctx, userId, entityId)
+	if err != nil {
+		return nil, err
+	}
+	if !accessible {
+		return nil, ErrEntityNotAccessible
+	}
+
+	entities, err := s.entityRepo.GetSubtree(ctx, entityId)
+	if err != nil {
+		return nil, err
+	}
+	root := entities[0]
+
+	devices, err := s.deviceRepo.GetDevicesInSubtree(ctx, root.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subtree devices: %w", err)
+	}
+
+	// Map every descendant to the direct child of the root it sits under.
+	// Entities are ordered by depth, so each parent is mapped before its children.
+	branchOf := make(map[string]string, len(entities))
+	branches := make(map[string]*sensorAccumulator)
+	metrics := &domain.SubtreeMetrics{Children: make([]domain.EntityMetrics, 0)}
+	for _, entity := range entities[1:] {
+		if entity.ParentID == nil {
+			continue
+		}
+		if *entity.ParentID == root.ID {
+			branchOf[entity.ID] = entity.ID
+			branches[entity.ID] = &sensorAccumulator{}
+			metrics.Children = append(metrics.Children, domain.EntityMetrics{EntityID: entity.ID, EntityName: entity.Name})
+		} else {
+			branchOf[entity.ID] = branchOf[*entity.ParentID]
+		}
+	}
+
+	metrics.StartTime, metrics.EndTime = calculateTimeRange(timestampMs, dateMode)
+	slog.DebugContext(ctx, "Aggregating sensor data", "devices", len(devices), "entity_id", entityId)
+
+	// Fetch readings with bounded concurrency; a failing device is reported rather than failing the rollup
+	var (
+		mu    sync.Mutex
+		total sensorAccumulator
+	)
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(s.concurrency)
+
+	for _, device := range devices {
+		group.Go(func() error {
+			readings, err := s.deviceRepo.GetSensorData(groupCtx, device.MacAddress, metrics.StartTime, metrics.EndTime)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				slog.WarnContext(ctx, "Error getting sensor data", "device", device.MacAddress, "error", err)
+				metrics.FailedDevices = append(metrics.FailedDevices, device.MacAddress)
+				return nil
+			}
+
+			total.addDevice(readings)
+			if device.EntityID != nil {
+				if branch, ok := branches[branchOf[*device.EntityID]]; ok {
+					branch.addDevice(readings)
+				}
+			}
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	metrics.Total = domain.EntityMetrics{EntityID: root.ID, EntityName: root.Name, Aggregate: total.aggregate()}
+	for i := range metrics.Children {
+		metrics.Children[i].Aggregate = branches[metrics.Children[i].EntityID].aggregate()
+	}
+
+	return metrics, nil
+}
+
+// sensorAccumulator collects readings from many devices into running totals
+type sensorAccumulator struct {
+	devices     int
+	readings    int
+	temperature metricAccumulator
+	humidity    metricAccumulator
+	amperage    metricAccumulator
+}
+
+// addDevice adds the readings of one device
+func (a *sensorAccumulator) addDevice(readings []*domain.SensorReading) {
+	a.devices++
+	a.readings += len(readings)
+	for _, reading := range readings {
+		a.temperature.add(reading.Temperature)
+		a.humidity.add(reading.Humidity)
+		a.amperage.add(reading.Amperage)
+	}
+}
+
+// aggregate returns the summary of everything added so far
+func (a *sensorAccumulator) aggregate() domain.SensorAggregate {
+	return domain.SensorAggregate{
+		DeviceCount:  a.devices,
+		ReadingCount: a.readings,
+		Temperature:  a.temperature.summary(),
+		Humidity:     a.humidity.summary(),
+		Amperage:     a.amperage.summary(),
+	}
+}
+
+// metricAccumulator tracks count, sum and range of one metric
+type metricAccumulator struct {
+	count    int
+	sum      float64
+	min, max float64
+}
+
+// add records a raw reading, ignoring values that are not numeric
+func (m *metricAccumulator) add(raw string) {
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return
+	}
+
+	if m.count == 0 || value < m.min {
+		m.min = value
+	}
+	if m.count == 0 || value > m.max {
+		m.max = value
+	}
+	m.count++
+	m.sum += value
+}
+
+// summary returns nil when no numeric readings were recorded
+func (m *metricAccumulator) summary() *domain.MetricSummary {
+	if m.count == 0 {
+		return nil
+	}
+
+	return &domain.MetricSummary{
+		Count: m.count,
+		Min:   m.min,
+		Max:   m.max,
+		Avg:   m.sum / float64(m.count),
+	}
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+)
+
+func TestSensorAccumulator(t *testing.T) {
+	var acc sensorAccumulator
+	acc.addDevice([]*domain.SensorReading{
+		{Temperature: "20", Humidity: "40", Amperage: "1.5"},
+		{Temperature: "24", Humidity: "n/a", Amperage: ""},
+	})
+	acc.addDevice(nil)
+
+	aggregate := acc.aggregate()
+	assert.Equal(t, 2, aggregate.DeviceCount)
+	assert.Equal(t, 2, aggregate.ReadingCount)
+
+	require.NotNil(t, aggregate.Temperature)
+	assert.Equal(t, domain.MetricSummary{Count: 2, Min: 20, Max: 24, Avg: 22}, *aggregate.Temperature)
+
+	require.NotNil(t, aggregate.Humidity)
+	assert.Equal(t, 1, aggregate.Humidity.Count)
+
+	require.NotNil(t, aggregate.Amperage)
+	assert.Equal(t, 1.5, aggregate.Amperage.Avg)
+
+	var empty sensorAccumulator
+	assert.Nil(t, empty.aggregate().Temperature)
+}
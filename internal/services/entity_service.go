@@ -3,28 +3,45 @@ package services
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/cache"
 	"github.com/afreedicp/zolaris-backend-app/internal/domain"
 	"github.com/afreedicp/zolaris-backend-app/internal/repositories"
+	"github.com/afreedicp/zolaris-backend-app/internal/tracing"
 )
 
 // EntityService provides entity-related business operations
 type EntityService struct {
-	repo repositories.EntityRepository
-	userRepo  repositories.UserRepositoryInterface 
+	repo     repositories.EntityRepositoryInterface
+	userRepo repositories.UserRepositoryInterface
+	uow      *repositories.UnitOfWork
+	cache    *cache.Cache
+	audit    *AuditService
 }
 
-// NewEntityService creates a new entity service with the provided repository
-func NewEntityService(repo repositories.EntityRepository, userRepo repositories.UserRepositoryInterface)*EntityService {
+// NewEntityService creates a new entity service with the provided repositories.
+// The unit of work runs operations that span several repositories in one transaction.
+func NewEntityService(repo repositories.EntityRepositoryInterface, userRepo repositories.UserRepositoryInterface, uow *repositories.UnitOfWork, cache *cache.Cache, audit *AuditService) *EntityService {
 	return &EntityService{
-		repo: repo,
+		repo:     repo,
 		userRepo: userRepo,
+		uow:      uow,
+		cache:    cache,
+		audit:    audit,
 	}
 }
 
 // CheckEntityExists determines if an entity exists for a given user
 func (s *EntityService) CheckEntityExists(ctx context.Context, userId string) (bool, error) {
+	ctx, span := tracing.Start(ctx, "EntityService.CheckEntityExists")
+	defer span.End()
+
 	if userId == "" {
-		return false, fmt.Errorf("user ID cannot be empty")
+		return false, domain.NewValidationError("user ID cannot be empty")
 	}
 
 	return s.repo.CheckEntityPresence(ctx, userId)
@@ -32,12 +49,15 @@ func (s *EntityService) CheckEntityExists(ctx context.Context, userId string) (b
 
 // CreateRootEntity creates a new top-level entity without a parent
 func (s *EntityService) CreateRootEntity(ctx context.Context, categoryId string, entityName string, userId string, details map[string]any) (string, error) {
+	ctx, span := tracing.Start(ctx, "EntityService.CreateRootEntity")
+	defer span.End()
+
 	if categoryId == "" {
-		return "", fmt.Errorf("category ID cannot be empty")
+		return "", domain.NewValidationError("category ID cannot be empty")
 	}
 
 	if entityName == "" {
-		return "", fmt.Errorf("entity name cannot be empty")
+		return "", domain.NewValidationError("entity name cannot be empty")
 	}
 
 	// If details is nil, initialize it as an empty map
@@ -45,101 +65,293 @@ func (s *EntityService) CreateRootEntity(ctx context.Context, categoryId string,
 		details = make(map[string]any)
 	}
 
-	return s.repo.CreateRootEntity(ctx, categoryId, entityName, userId, details)
+	entityID, err := s.repo.CreateRootEntity(ctx, categoryId, entityName, userId, details)
+	if err != nil {
+		return "", err
+	}
+
+	s.audit.RecordCommitted(ctx, &domain.AuditEntry{
+		ActorID:      auditActor(userId),
+		Action:       domain.AuditActionCreate,
+		ResourceType: domain.AuditResourceEntity,
+		ResourceID:   entityID,
+	}, nil, auditedEntity(entityName, categoryId, "", details))
+	return entityID, nil
 }
 
 // CreateSubEntity creates a new entity as a child of an existing entity
 func (s *EntityService) CreateSubEntity(ctx context.Context, categoryId string, entityName string, userId string, details map[string]any, parentEntityID string) (string, error) {
+	ctx, span := tracing.Start(ctx, "EntityService.CreateSubEntity")
+	defer span.End()
+
 	if categoryId == "" {
-		return "", fmt.Errorf("category ID cannot be empty")
+		return "", domain.NewValidationError("category ID cannot be empty")
 	}
 
 	if entityName == "" {
-		return "", fmt.Errorf("entity name cannot be empty")
+		return "", domain.NewValidationError("entity name cannot be empty")
 	}
 	if details == nil {
 		details = make(map[string]any)
 	}
 
-	parentCategoryID, err := s.repo.GetCategoryIDByEntityID(ctx, parentEntityID)
-	if err != nil {
-		return "", fmt.Errorf("failed to get parent's category ID: %w", err)
-	}
+	// Create the sub-entity and link the sub-user to its parent atomically
+	var subentityID string
+	err := s.uow.Do(ctx, func(ctx context.Context, tx pgx.Tx) error {
+		repo := s.repo.WithTx(tx)
 
-	parentCategoryType, err := s.repo.GetCategoryType(ctx, parentCategoryID)
-	if err != nil {
-		return "", fmt.Errorf("failed to get parent's category type: %w", err)
-	}
+		parentCategoryID, err := repo.GetCategoryIDByEntityID(ctx, parentEntityID)
+		if err != nil {
+			return fmt.Errorf("failed to get parent's category ID: %w", err)
+		}
 
-	currentCategoryType, err := s.repo.GetCategoryType(ctx, categoryId)
-	if err != nil {
-		return "", fmt.Errorf("failed to get parent's category type: %w", err)
-	}
+		parentCategoryType, err := repo.GetCategoryType(ctx, parentCategoryID)
+		if err != nil {
+			return fmt.Errorf("failed to get parent's category type: %w", err)
+		}
 
-	subentityID, err := s.repo.CreateSubEntity(ctx, categoryId, entityName, userId, details, parentEntityID)
-	if err != nil {
-		return "", fmt.Errorf("failed to create sub-entity: %w", err)
-	}
-	if parentCategoryType == "user" &&  currentCategoryType == "user" {
-		subuserRaw, ok := details["subuser_id"]
-		if !ok {
-			return "", fmt.Errorf("subuser_id not found in details : %w", parentCategoryType)
+		currentCategoryType, err := repo.GetCategoryType(ctx, categoryId)
+		if err != nil {
+			return fmt.Errorf("failed to get category type: %w", err)
+		}
+
+		subentityID, err = repo.CreateSubEntity(ctx, categoryId, entityName, userId, details, parentEntityID)
+		if err != nil {
+			return fmt.Errorf("failed to create sub-entity: %w", err)
 		}
-		subuserID, ok := subuserRaw.(string)
-		if !ok {
-			return "", fmt.Errorf("subuser_id must be a string")
+
+		audit := s.audit.WithTx(tx)
+		err = audit.Record(ctx, &domain.AuditEntry{
+			ActorID:      auditActor(userId),
+			Action:       domain.AuditActionCreate,
+			ResourceType: domain.AuditResourceEntity,
+			ResourceID:   subentityID,
+		}, nil, auditedEntity(entityName, categoryId, parentEntityID, details))
+		if err != nil {
+			return err
 		}
 
+		if parentCategoryType == repositories.UserCategoryType && currentCategoryType == repositories.UserCategoryType {
+			subuserRaw, ok := details["subuser_id"]
+			if !ok {
+				return domain.NewValidationError("subuser_id not found in details : %s", parentCategoryType)
+			}
+			subuserID, ok := subuserRaw.(string)
+			if !ok {
+				return domain.NewValidationError("subuser_id must be a string")
+			}
+
+			userRepo := s.userRepo.WithTx(tx)
+			subuser, err := userRepo.GetUserByID(ctx, subuserID)
+			if err != nil {
+				return fmt.Errorf("failed to get sub-user: %w", err)
+			}
 
-		if err := s.userRepo.UpdateUserParentID(ctx, subuserID, &parentEntityID); err != nil {
-			return "", fmt.Errorf("failed to update user parent ID: %w", err)
+			if err := userRepo.UpdateUserParentID(ctx, subuserID, &parentEntityID); err != nil {
+				return fmt.Errorf("failed to update user parent ID: %w", err)
+			}
+
+			// An unknown sub-user is left alone, so there is nothing to record
+			if subuser != nil {
+				err = audit.Record(ctx, &domain.AuditEntry{
+					ActorID:      auditActor(userId),
+					Action:       domain.AuditActionUpdate,
+					ResourceType: domain.AuditResourceUser,
+					ResourceID:   subuserID,
+				}, map[string]*string{"parentId": subuser.ParentID}, map[string]*string{"parentId": &parentEntityID})
+				if err != nil {
+					return err
+				}
+			}
 		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
 	}
+
+	invalidateHierarchies(ctx, s.cache, s.repo, subentityID)
 	return subentityID, nil
 }
 
 // GetChildEntities retrieves all direct child entities of a given entity
 // If recursive is true, returns all descendants (children, grandchildren, etc.)
 func (s *EntityService) GetChildEntities(ctx context.Context, entityId string, recursive bool) ([]*domain.Entity, error) {
+	ctx, span := tracing.Start(ctx, "EntityService.GetChildEntities")
+	defer span.End()
+
 	if entityId == "" {
-		return nil, fmt.Errorf("entity ID cannot be empty")
+		return nil, domain.NewValidationError("entity ID cannot be empty")
 	}
 
 	return s.repo.GetChildEntities(ctx, entityId, recursive)
 }
 
-// GetEntityHierarchy retrieves an entity and all its descendant entities as a hierarchical structure
-func (s *EntityService) GetEntityHierarchy(ctx context.Context, rootEntityId string) (map[string]any, error) {
+// Defaults applied when a hierarchy or search request leaves depth or page size unset
+const (
+	defaultHierarchyMaxDepth = 10
+	defaultHierarchyLimit    = 50
+	defaultSearchLimit       = 50
+)
+
+// GetEntityHierarchy retrieves an entity and its descendants up to maxDepth levels as a hierarchical structure.
+// A nil maxDepth uses the default depth, and 0 returns the root alone. The root's direct
+// children are paged with limit and cursor.
+func (s *EntityService) GetEntityHierarchy(ctx context.Context, rootEntityId string, maxDepth *int, limit int, cursor string) (*domain.EntityHierarchy, error) {
+	ctx, span := tracing.Start(ctx, "EntityService.GetEntityHierarchy")
+	defer span.End()
+
 	if rootEntityId == "" {
-		return nil, fmt.Errorf("root entity ID cannot be empty")
+		return nil, domain.NewValidationError("root entity ID cannot be empty")
+	}
+
+	depth := defaultHierarchyMaxDepth
+	if maxDepth != nil {
+		depth = *maxDepth
+	}
+
+	if depth < 0 || limit < 0 {
+		return nil, domain.NewValidationError("max depth and limit must not be negative")
 	}
 
-	return s.repo.GetEntityHierarchy(ctx, rootEntityId)
+	if limit == 0 {
+		limit = defaultHierarchyLimit
+	}
+
+	key := fmt.Sprintf("%s%d:%d:%s", hierarchyCachePrefix(rootEntityId), depth, limit, cursor)
+	return cache.GetOrLoad(ctx, s.cache, key, hierarchyCacheTTL, func(ctx context.Context) (*domain.EntityHierarchy, error) {
+		return s.fillRepo().GetEntityHierarchy(ctx, rootEntityId, repositories.HierarchyOptions{
+			MaxDepth: depth,
+			Limit:    limit,
+			Cursor:   cursor,
+		})
+	})
+}
+
+// cacheFillMaxLag is the replication lag up to which cache misses are loaded from
+// the replica. Beyond it they are loaded from the primary, as a lagging replica
+// would cache again for a whole TTL what a write has just invalidated.
+const cacheFillMaxLag = 100 * time.Millisecond
+
+// fillRepo returns the repository cache misses are loaded from: one avoiding a
+// lagging replica, unless nothing is cached
+func (s *EntityService) fillRepo() repositories.EntityRepositoryInterface {
+	if s.cache.Disabled() {
+		return s.repo
+	}
+	return s.repo.WithMaxLag(cacheFillMaxLag)
+}
+
+// hierarchyCacheTTL bounds how stale a cached hierarchy can be after changes
+// that bypass the services, such as edits on another instance
+const hierarchyCacheTTL = time.Minute
+
+// hierarchyCachePrefix prefixes every cached hierarchy rooted at the entity
+func hierarchyCachePrefix(rootEntityId string) string {
+	return "entity:hierarchy:" + rootEntityId + ":"
+}
+
+// invalidateHierarchies drops the cached hierarchies rooted at the entity or any of
+// its ancestors, since all of them contain the entity's subtree
+func invalidateHierarchies(ctx context.Context, c *cache.Cache, repo repositories.EntityRepositoryInterface, entityId string) {
+	// Read from the primary, since a replica may not have the entity yet
+	primary := repo.Primary()
+	ancestors, err := primary.GetEntityAncestors(ctx, entityId, true)
+	if err != nil {
+		slog.WarnContext(ctx, "Error finding ancestors, dropping all cached hierarchies", "entity_id", entityId, "error", err)
+		c.InvalidatePrefix(ctx, "entity:hierarchy:")
+		return
+	}
+
+	for _, ancestor := range ancestors {
+		c.InvalidatePrefix(ctx, hierarchyCachePrefix(ancestor.ID))
+	}
+}
+
+// GetEntityAncestors returns the chain of ancestors of an entity within the
+// user's subtrees, starting at the root
+func (s *EntityService) GetEntityAncestors(ctx context.Context, userId string, entityId string, includeSelf bool) ([]*domain.EntityNode, error) {
+	ctx, span := tracing.Start(ctx, "EntityService.GetEntityAncestors")
+	defer span.End()
+
+	if entityId == "" {
+		return nil, domain.NewValidationError("entity ID cannot be empty")
+	}
+
+	accessible, err := s.repo.IsEntityAccessible(ctx, userId, entityId)
+	if err != nil {
+		return nil, err
+	}
+	if !accessible {
+		return nil, ErrEntityNotAccessible
+	}
+
+	return s.repo.GetEntityAncestors(ctx, entityId, includeSelf)
+}
+
+// SearchEntities searches the subtrees accessible to filter.UserID, shallowest matches first
+func (s *EntityService) SearchEntities(ctx context.Context, filter repositories.EntitySearchFilter) ([]*domain.EntityNode, error) {
+	ctx, span := tracing.Start(ctx, "EntityService.SearchEntities")
+	defer span.End()
+
+	if filter.UserID == "" {
+		return nil, domain.NewValidationError("user ID cannot be empty")
+	}
+
+	if filter.Limit < 0 {
+		return nil, domain.NewValidationError("limit must not be negative")
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = defaultSearchLimit
+	}
+
+	return s.repo.SearchEntities(ctx, filter)
 }
 
 // ListEntityChildren lists all children of a given entity with optional filtering
 // level: 0 for direct children only, -1 for all descendants, or specific depth (1, 2, 3, etc.)
-// categoryType: filter by category type (optional)
-func (s *EntityService) ListEntityChildren(ctx context.Context, entityId string, level int, categoryType string) ([]*domain.Entity, error) {
+// opts selects the page, sort order and filters (categoryType, categoryId)
+func (s *EntityService) ListEntityChildren(ctx context.Context, entityId string, level int, opts domain.ListOptions) (*domain.Page[*domain.Entity], error) {
+	ctx, span := tracing.Start(ctx, "EntityService.ListEntityChildren")
+	defer span.End()
+
 	if entityId == "" {
-		return nil, fmt.Errorf("entity ID cannot be empty")
+		return nil, domain.NewValidationError("entity ID cannot be empty")
 	}
 
 	// Validate level parameter
 	if level < -1 {
-		return nil, fmt.Errorf("invalid level: must be -1 (all levels), 0 (direct children only), or a positive integer")
+		return nil, domain.NewValidationError("invalid level: must be -1 (all levels), 0 (direct children only), or a positive integer")
 	}
 
-	return s.repo.ListEntityChildren(ctx, entityId, level, categoryType)
+	return s.repo.ListEntityChildren(ctx, entityId, level, opts)
 }
 
 
 
 
 func (s *EntityService) GetEntityID(ctx context.Context, userId string) (string, error) {
+	ctx, span := tracing.Start(ctx, "EntityService.GetEntityID")
+	defer span.End()
+
 	if userId == "" {
-		return "", fmt.Errorf("user ID is empty")
+		return "", domain.NewValidationError("user ID is empty")
 	}
 
 	return s.repo.GetEntityID(ctx, userId) // You must have this repo method implemented
-}
\ No newline at end of file
+}
+
+// auditedEntity returns the fields of a new entity recorded in the audit log
+func auditedEntity(name, categoryId, parentEntityId string, details map[string]any) map[string]any {
+	fields := map[string]any{
+		"name":       name,
+		"categoryId": categoryId,
+		"details":    details,
+	}
+	if parentEntityId != "" {
+		fields["parentId"] = parentEntityId
+	}
+	return fields
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+	"github.com/afreedicp/zolaris-backend-app/internal/repositories"
+)
+
+// subtreeEntityRepo lets each user reach only the entities listed for them
+type subtreeEntityRepo struct {
+	repositories.EntityRepositoryInterface
+	accessible map[string][]string
+}
+
+func (r *subtreeEntityRepo) IsEntityAccessible(ctx context.Context, userId string, entityId string) (bool, error) {
+	for _, id := range r.accessible[userId] {
+		if id == entityId {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *subtreeEntityRepo) GetEntityAncestors(ctx context.Context, entityId string, includeSelf bool) ([]*domain.EntityNode, error) {
+	return []*domain.EntityNode{{}}, nil
+}
+
+func TestGetEntityAncestorsChecksAccess(t *testing.T) {
+	repo := &subtreeEntityRepo{accessible: map[string][]string{"user-1": {"entity-1"}}}
+	service := NewEntityService(repo, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	ancestors, err := service.GetEntityAncestors(ctx, "user-1", "entity-1", false)
+	require.NoError(t, err)
+	assert.Len(t, ancestors, 1)
+
+	_, err = service.GetEntityAncestors(ctx, "user-2", "entity-1", false)
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+}
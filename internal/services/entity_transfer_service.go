@@ -0,0 +1,268 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/cache"
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+	"github.com/afreedicp/zolaris-backend-app/internal/repositories"
+	"github.com/afreedicp/zolaris-backend-app/internal/tracing"
+)
+
+// maxImportRows bounds the size of a single bulk import
+const maxImportRows = 5000
+
+// ErrEntityNotAccessible is returned when an entity lies outside the caller's subtrees
+var ErrEntityNotAccessible = domain.NewForbiddenError("entity is not accessible")
+
+// EntityTransferService handles bulk import and export of entity trees and their devices
+type EntityTransferService struct {
+	entityRepo repositories.EntityRepositoryInterface
+	deviceRepo repositories.DeviceRepositoryInterface
+	cache      *cache.Cache
+	audit      *AuditService
+}
+
+// NewEntityTransferService creates a new entity transfer service instance
+func NewEntityTransferService(entityRepo repositories.EntityRepositoryInterface, deviceRepo repositories.DeviceRepositoryInterface, cache *cache.Cache, audit *AuditService) *EntityTransferService {
+	return &EntityTransferService{
+		entityRepo: entityRepo,
+		deviceRepo: deviceRepo,
+		cache:      cache,
+		audit:      audit,
+	}
+}
+
+// ImportEntities validates every row of an import and then creates it under parentEntityId in one transaction.
+// When parentEntityId is empty the import is attached to the user's own entity.
+// Row level problems are reported in the result rather than as an error; nothing is written if any row is invalid.
+func (s *EntityTransferService) ImportEntities(ctx context.Context, userId string, parentEntityId string, rows []domain.EntityImportRow, dryRun bool) (*domain.EntityImportResult, error) {
+	ctx, span := tracing.Start(ctx, "EntityTransferService.ImportEntities")
+	defer span.End()
+
+	if userId == "" {
+		return nil, domain.NewValidationError("user ID cannot be empty")
+	}
+
+	result := &domain.EntityImportResult{DryRun: dryRun, EntityIDs: map[string]string{}}
+
+	if len(rows) == 0 {
+		result.Errors = []domain.EntityImportError{{Field: "rows", Message: "import contains no rows"}}
+		return result, nil
+	}
+
+	if len(rows) > maxImportRows {
+		result.Errors = []domain.EntityImportError{{Field: "rows", Message: fmt.Sprintf("import is limited to %d rows", maxImportRows)}}
+		return result, nil
+	}
+
+	if parentEntityId == "" {
+		entityId, err := s.entityRepo.GetEntityID(ctx, userId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve import parent: %w", err)
+		}
+		parentEntityId = entityId
+	} else {
+		accessible, err := s.entityRepo.IsEntityAccessible(ctx, userId, parentEntityId)
+		if err != nil {
+			return nil, err
+		}
+		if !accessible {
+			return nil, ErrEntityNotAccessible
+		}
+	}
+
+	// Look up everything the rows refer to before validating
+	var categoryIds, deviceIds []string
+	for _, row := range rows {
+		if row.CategoryID != "" {
+			categoryIds = append(categoryIds, row.CategoryID)
+		}
+		if row.DeviceID != "" {
+			deviceIds = append(deviceIds, row.DeviceID)
+		}
+	}
+
+	categoryTypes, err := s.entityRepo.GetCategoryTypes(ctx, categoryIds)
+	if err != nil {
+		return nil, err
+	}
+
+	deviceOwners, err := s.deviceRepo.GetDeviceOwners(ctx, deviceIds)
+	if err != nil {
+		return nil, err
+	}
+
+	ordered, rowErrors := validateImportRows(rows, categoryTypes, deviceOwners, userId)
+	if len(rowErrors) > 0 {
+		result.Errors = rowErrors
+		return result, nil
+	}
+
+	slog.InfoContext(ctx, "Importing entities", "rows", len(ordered), "parent_entity_id", parentEntityId, "dry_run", dryRun)
+
+	entityIds, devices, err := s.entityRepo.ImportEntityRows(ctx, userId, parentEntityId, ordered, dryRun)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import entities: %w", err)
+	}
+
+	if !dryRun {
+		invalidateHierarchies(ctx, s.cache, s.entityRepo, parentEntityId)
+
+		// One entry for the whole import, on the entity it was attached to
+		s.audit.RecordCommitted(ctx, &domain.AuditEntry{
+			ActorID:      auditActor(userId),
+			Action:       domain.AuditActionImport,
+			ResourceType: domain.AuditResourceEntity,
+			ResourceID:   parentEntityId,
+		}, nil, map[string]any{"entitiesCreated": len(entityIds), "devicesImported": devices})
+	}
+
+	result.EntityIDs = entityIds
+	result.EntitiesCreated = len(entityIds)
+	result.DevicesImported = devices
+
+	return result, nil
+}
+
+// ExportSubtree returns the subtree rooted at entityId together with the devices assigned within it
+func (s *EntityTransferService) ExportSubtree(ctx context.Context, userId string, entityId string) (*domain.EntitySubtree, error) {
+	ctx, span := tracing.Start(ctx, "EntityTransferService.ExportSubtree")
+	defer span.End()
+
+	if entityId == "" {
+		return nil, domain.NewValidationError("entity ID cannot be empty")
+	}
+
+	accessible, err := s.entityRepo.IsEntityAccessible(ctx, userId, entityId)
+	if err != nil {
+		return nil, err
+	}
+	if !accessible {
+		return nil, ErrEntityNotAccessible
+	}
+
+	entities, err := s.entityRepo.GetSubtree(ctx, entityId)
+	if err != nil {
+		return nil, err
+	}
+
+	devices, err := s.deviceRepo.GetDevicesInSubtree(ctx, entities[0].Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subtree devices: %w", err)
+	}
+
+	return &domain.EntitySubtree{Entities: entities, Devices: devices}, nil
+}
+
+// validateImportRows checks every row against the rest of the import and the referenced categories and devices.
+// Valid imports are returned reordered so that each entity precedes the rows that reference it.
+func validateImportRows(rows []domain.EntityImportRow, categoryTypes map[string]repositories.CategoryType, deviceOwners map[string]string, userId string) ([]domain.EntityImportRow, []domain.EntityImportError) {
+	var rowErrors []domain.EntityImportError
+	reject := func(row domain.EntityImportRow, field, message string) {
+		rowErrors = append(rowErrors, domain.EntityImportError{Row: row.Row, Ref: row.Ref, Field: field, Message: message})
+	}
+
+	// Index entity references first so parents may appear after their children
+	entityRows := make(map[string]domain.EntityImportRow)
+	for _, row := range rows {
+		if row.Kind != domain.ImportKindEntity || row.Ref == "" {
+			continue
+		}
+		if _, exists := entityRows[row.Ref]; exists {
+			reject(row, "ref", "duplicate reference")
+			continue
+		}
+		entityRows[row.Ref] = row
+	}
+
+	seenDevices := make(map[string]bool)
+	for _, row := range rows {
+		switch row.Kind {
+		case domain.ImportKindEntity:
+			if row.Ref == "" {
+				reject(row, "ref", "required field")
+			}
+			if len(row.Name) < 2 || len(row.Name) > 100 {
+				reject(row, "name", "must be between 2 and 100 characters long")
+			}
+			if row.CategoryID == "" {
+				reject(row, "category_id", "required field")
+			} else if categoryType, ok := categoryTypes[row.CategoryID]; !ok {
+				reject(row, "category_id", "category not found")
+			} else if categoryType == repositories.UserCategoryType {
+				reject(row, "category_id", "user entities cannot be imported")
+			}
+
+		case domain.ImportKindDevice:
+			if row.DeviceID == "" {
+				reject(row, "device_id", "required field")
+			} else if len(row.DeviceID) > 17 {
+				reject(row, "device_id", "must be at most 17 characters long")
+			} else if seenDevices[row.DeviceID] {
+				reject(row, "device_id", "duplicate device")
+			} else if owner, ok := deviceOwners[row.DeviceID]; ok && owner != userId {
+				reject(row, "device_id", "device is registered to another user")
+			}
+			seenDevices[row.DeviceID] = true
+			if row.Name == "" || len(row.Name) > 100 {
+				reject(row, "name", "must be between 1 and 100 characters long")
+			}
+
+		default:
+			reject(row, "kind", "must be one of: entity device")
+			continue
+		}
+
+		if row.ParentRef != "" {
+			if row.ParentRef == row.Ref && row.Kind == domain.ImportKindEntity {
+				reject(row, "parent_ref", "entity cannot be its own parent")
+			} else if _, ok := entityRows[row.ParentRef]; !ok {
+				reject(row, "parent_ref", "parent reference not found")
+			}
+		}
+	}
+
+	if len(rowErrors) > 0 {
+		return nil, rowErrors
+	}
+
+	// Order entities breadth first from the import root; anything unreachable is part of a cycle
+	children := make(map[string][]domain.EntityImportRow)
+	var devices []domain.EntityImportRow
+	for _, row := range rows {
+		if row.Kind == domain.ImportKindDevice {
+			devices = append(devices, row)
+			continue
+		}
+		children[row.ParentRef] = append(children[row.ParentRef], row)
+	}
+
+	ordered := make([]domain.EntityImportRow, 0, len(rows))
+	queue := []string{""}
+	for len(queue) > 0 {
+		ref := queue[0]
+		queue = queue[1:]
+		for _, child := range children[ref] {
+			ordered = append(ordered, child)
+			queue = append(queue, child.Ref)
+		}
+	}
+
+	if len(ordered) < len(entityRows) {
+		placed := make(map[string]bool, len(ordered))
+		for _, row := range ordered {
+			placed[row.Ref] = true
+		}
+		for _, row := range rows {
+			if row.Kind == domain.ImportKindEntity && !placed[row.Ref] {
+				reject(row, "parent_ref", "parent references form a cycle")
+			}
+		}
+		return nil, rowErrors
+	}
+
+	return append(ordered, devices...), nil
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+	"github.com/afreedicp/zolaris-backend-app/internal/repositories"
+)
+
+func TestValidateImportRows(t *testing.T) {
+	categoryTypes := map[string]repositories.CategoryType{
+		"office-cat":   repositories.OfficeCategoryType,
+		"location-cat": repositories.LocationCategoryType,
+		"user-cat":     repositories.UserCategoryType,
+	}
+	deviceOwners := map[string]string{
+		"AA:AA:AA:AA:AA:01": "user-1",
+		"AA:AA:AA:AA:AA:02": "user-2",
+	}
+
+	t.Run("OrdersParentsBeforeChildren", func(t *testing.T) {
+		rows := []domain.EntityImportRow{
+			{Row: 2, Kind: domain.ImportKindDevice, ParentRef: "floor", Name: "Sensor", DeviceID: "AA:AA:AA:AA:AA:01"},
+			{Row: 3, Kind: domain.ImportKindEntity, Ref: "floor", ParentRef: "site", Name: "Floor 1", CategoryID: "location-cat"},
+			{Row: 4, Kind: domain.ImportKindEntity, Ref: "site", Name: "Site A", CategoryID: "office-cat"},
+		}
+
+		ordered, rowErrors := validateImportRows(rows, categoryTypes, deviceOwners, "user-1")
+		require.Empty(t, rowErrors)
+		require.Len(t, ordered, 3)
+		assert.Equal(t, "site", ordered[0].Ref)
+		assert.Equal(t, "floor", ordered[1].Ref)
+		assert.Equal(t, domain.ImportKindDevice, ordered[2].Kind)
+	})
+
+	t.Run("ReportsEveryInvalidRow", func(t *testing.T) {
+		rows := []domain.EntityImportRow{
+			{Row: 2, Kind: domain.ImportKindEntity, Ref: "a", Name: "A", CategoryID: "office-cat"},
+			{Row: 3, Kind: domain.ImportKindEntity, Ref: "b", Name: "Site B", CategoryID: "missing"},
+			{Row: 4, Kind: domain.ImportKindEntity, Ref: "c", Name: "Site C", CategoryID: "user-cat"},
+			{Row: 5, Kind: domain.ImportKindEntity, Ref: "d", ParentRef: "nope", Name: "Site D", CategoryID: "office-cat"},
+			{Row: 6, Kind: domain.ImportKindDevice, ParentRef: "a", Name: "Sensor", DeviceID: "AA:AA:AA:AA:AA:02"},
+			{Row: 7, Kind: "gateway", Ref: "e"},
+		}
+
+		_, rowErrors := validateImportRows(rows, categoryTypes, deviceOwners, "user-1")
+
+		failedRows := make(map[int]string)
+		for _, rowError := range rowErrors {
+			failedRows[rowError.Row] = rowError.Field
+		}
+		assert.Equal(t, map[int]string{
+			2: "name",
+			3: "category_id",
+			4: "category_id",
+			5: "parent_ref",
+			6: "device_id",
+			7: "kind",
+		}, failedRows)
+	})
+
+	t.Run("RejectsCycles", func(t *testing.T) {
+		rows := []domain.EntityImportRow{
+			{Row: 2, Kind: domain.ImportKindEntity, Ref: "a", ParentRef: "b", Name: "Site A", CategoryID: "office-cat"},
+			{Row: 3, Kind: domain.ImportKindEntity, Ref: "b", ParentRef: "a", Name: "Site B", CategoryID: "office-cat"},
+			{Row: 4, Kind: domain.ImportKindEntity, Ref: "c", Name: "Site C", CategoryID: "office-cat"},
+		}
+
+		_, rowErrors := validateImportRows(rows, categoryTypes, deviceOwners, "user-1")
+		require.Len(t, rowErrors, 2)
+		assert.Equal(t, "parent_ref", rowErrors[0].Field)
+		assert.Equal(t, "parent_ref", rowErrors[1].Field)
+	})
+}
@@ -2,27 +2,44 @@ package services
 
 import (
 	"context"
-	"log"
+	"log/slog"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
 	"github.com/afreedicp/zolaris-backend-app/internal/repositories"
+	"github.com/afreedicp/zolaris-backend-app/internal/tracing"
 )
 
 // PolicyService handles business logic for IoT policy operations
 type PolicyService struct {
-	policyRepo *repositories.PolicyRepository
+	policyRepo repositories.PolicyRepositoryInterface
 	policyName string
+	audit      *AuditService
 }
 
 // NewPolicyService creates a new policy service instance
-func NewPolicyService(policyRepo *repositories.PolicyRepository, policyName string) *PolicyService {
+func NewPolicyService(policyRepo repositories.PolicyRepositoryInterface, policyName string, audit *AuditService) *PolicyService {
 	return &PolicyService{
 		policyRepo: policyRepo,
 		policyName: policyName,
+		audit:      audit,
 	}
 }
 
 // AttachIoTPolicy attaches an IoT policy to an identity
 func (s *PolicyService) AttachIoTPolicy(ctx context.Context, identityID string) error {
-	log.Printf("Attaching policy %s to identity %s", s.policyName, identityID)
-	return s.policyRepo.AttachPolicy(ctx, s.policyName, identityID)
+	ctx, span := tracing.Start(ctx, "PolicyService.AttachIoTPolicy")
+	defer span.End()
+
+	slog.InfoContext(ctx, "Attaching IoT policy", "policy", s.policyName, "identity_id", identityID)
+	if err := s.policyRepo.AttachPolicy(ctx, s.policyName, identityID); err != nil {
+		return err
+	}
+
+	s.audit.RecordCommitted(ctx, &domain.AuditEntry{
+		Action:       domain.AuditActionAttachPolicy,
+		ResourceType: domain.AuditResourceIoTIdentity,
+		ResourceID:   identityID,
+	}, nil, map[string]string{"policyName": s.policyName})
+	return nil
 }
 
@@ -3,54 +3,80 @@ package services
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"time"
 
+	"github.com/afreedicp/zolaris-backend-app/internal/cache"
 	"github.com/afreedicp/zolaris-backend-app/internal/domain"
 	"github.com/afreedicp/zolaris-backend-app/internal/repositories"
+	"github.com/afreedicp/zolaris-backend-app/internal/tracing"
 	"github.com/afreedicp/zolaris-backend-app/internal/transport/dto"
 	"github.com/afreedicp/zolaris-backend-app/internal/transport/mappers"
 )
 
+// cognitoUserTTL is how long the user ID of a Cognito identity is cached.
+// The mapping never changes once a user exists.
+const cognitoUserTTL = 10 * time.Minute
+
 // UserService handles business logic for user operations
 type UserService struct {
 	userRepo repositories.UserRepositoryInterface
+	cache    *cache.Cache
+	audit    *AuditService
 }
 
 // NewUserService creates a new user service instance
-func NewUserService(userRepo repositories.UserRepositoryInterface) *UserService {
-	return &UserService{userRepo: userRepo}
+func NewUserService(userRepo repositories.UserRepositoryInterface, cache *cache.Cache, audit *AuditService) *UserService {
+	return &UserService{userRepo: userRepo, cache: cache, audit: audit}
+}
+
+func cognitoUserKey(cId string) string {
+	return "user:cognito:" + cId
 }
 
+// GetUserIdByCognitoId returns the ID of the user with the given Cognito ID, or an empty string if there is none.
+// Known users are cached; unknown IDs are not, so a user can sign in right after signing up.
 func (s *UserService) GetUserIdByCognitoId(ctx context.Context, cId string) (string, error) {
-	// Corrected line 25:
-	// Capture the values from the repository call first
-	userID, err := s.userRepo.GetUserIdByCognitoId(ctx, cId)
+	ctx, span := tracing.Start(ctx, "UserService.GetUserIdByCognitoId")
+	defer span.End()
+
+	var cachedUserID string
+	if s.cache.Get(ctx, cognitoUserKey(cId), &cachedUserID) {
+		return cachedUserID, nil
+	}
 
-	// Now you can use them in the log statement.
-	// You need to decide what you want to log for the second %s.
-	// It's usually the actual ID or an error message.
-	// If userID is empty, it means not found, which is a success from repo's perspective.
-	// If err is not nil, that's an actual error from the DB.
+	// An empty userID means not found, which is a success from the repository's perspective.
+	// The Cognito ID is logged as an attribute so it is redacted.
+	userID, err := s.userRepo.GetUserIdByCognitoId(ctx, cId)
 	if err != nil {
-		log.Printf("Error getting user ID by Cognito ID %s: %v", cId, err)
+		slog.ErrorContext(ctx, "Error getting user ID by Cognito ID", "cognito_id", cId, "error", err)
 		return "", fmt.Errorf("error retrieving user ID by Cognito ID: %w", err)
 	}
-	log.Printf("Getting user ID by Cognito ID: %s, Result: %s", cId, userID) // Changed the log message
-	
+	slog.DebugContext(ctx, "Resolved user ID by Cognito ID", "cognito_id", cId, "resolved_user_id", userID)
+
+	if userID != "" {
+		s.cache.Set(ctx, cognitoUserKey(cId), userID, cognitoUserTTL)
+	}
+
 	// Then return the results
 	return userID, nil
 }
 
 // GetUserByID retrieves a user by their ID
 func (s *UserService) GetUserByID(ctx context.Context, userID string) (*domain.User, error) {
-	log.Printf("Getting user details for user %s", userID)
+	ctx, span := tracing.Start(ctx, "UserService.GetUserByID")
+	defer span.End()
+
+	slog.DebugContext(ctx, "Getting user details", "target_user_id", userID)
 	return s.userRepo.GetUserByID(ctx, userID)
 }
 
 // CreateUser creates a new user account
 func (s *UserService) CreateUser(ctx context.Context, req *dto.UserDetailsRequest) (*domain.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.CreateUser")
+	defer span.End()
+
 	// Convert DTO to domain entity
-	log.Printf("UserRequestToEntity")
 	user := mappers.UserRequestToEntity(req, nil)
 
 	// Save user to database
@@ -59,11 +85,21 @@ func (s *UserService) CreateUser(ctx context.Context, req *dto.UserDetailsReques
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
+	// Users sign up before they can authenticate, so there is no actor
+	s.audit.RecordCommitted(ctx, &domain.AuditEntry{
+		Action:       domain.AuditActionCreate,
+		ResourceType: domain.AuditResourceUser,
+		ResourceID:   user.ID,
+	}, nil, user)
+
 	return user, nil
 }
 
 // UpdateUserDetails updates a user's details
 func (s *UserService) UpdateUserDetails(ctx context.Context, userID string, req *dto.UserDetailsRequest) (*domain.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.UpdateUserDetails")
+	defer span.End()
+
 	// Get existing user
 	existingUser, err := s.userRepo.GetUserByID(ctx, userID)
 	if err != nil {
@@ -71,9 +107,12 @@ func (s *UserService) UpdateUserDetails(ctx context.Context, userID string, req
 	}
 
 	if existingUser == nil {
-		return nil, fmt.Errorf("user not found with ID: %s", userID)
+		return nil, domain.NewNotFoundError("user not found with ID: %s", userID)
 	}
 
+	// The mapper updates existingUser in place
+	before := auditedUserDetails(existingUser)
+
 	// Update user with new details
 	updatedUser := mappers.UserRequestToEntity(req, existingUser)
 
@@ -83,15 +122,38 @@ func (s *UserService) UpdateUserDetails(ctx context.Context, userID string, req
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
 
+	s.audit.RecordCommitted(ctx, &domain.AuditEntry{
+		ActorID:      auditActor(userID),
+		Action:       domain.AuditActionUpdate,
+		ResourceType: domain.AuditResourceUser,
+		ResourceID:   userID,
+	}, before, auditedUserDetails(updatedUser))
+
 	return updatedUser, nil
 }
 
+// auditedUserDetails returns the user fields written by UpdateUser
+func auditedUserDetails(user *domain.User) map[string]any {
+	return map[string]any{
+		"firstName": user.FirstName,
+		"lastName":  user.LastName,
+		"phone":     user.Phone,
+		"address":   user.Address,
+	}
+}
+
 // CheckHasParentID checks if a user has a parent ID
 func (s *UserService) CheckHasParentID(ctx context.Context, userID string) (bool, error) {
+	ctx, span := tracing.Start(ctx, "UserService.CheckHasParentID")
+	defer span.End()
+
 	return s.userRepo.CheckHasParentID(ctx, userID)
 }
 
-func (s *UserService) ListReferredUsers(ctx context.Context, userID string) ([]*domain.User, error) {
-	log.Printf("Listing referred users for user %s", userID)
-	return s.userRepo.ListReferredUsers(ctx, userID)
+func (s *UserService) ListReferredUsers(ctx context.Context, userID string, opts domain.ListOptions) (*domain.Page[*domain.User], error) {
+	ctx, span := tracing.Start(ctx, "UserService.ListReferredUsers")
+	defer span.End()
+
+	slog.DebugContext(ctx, "Listing referred users", "referrer_id", userID)
+	return s.userRepo.ListReferredUsers(ctx, userID, opts)
 }
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/cache"
+	"github.com/afreedicp/zolaris-backend-app/internal/repositories"
+)
+
+// cognitoUserRepo answers Cognito lookups from a map and counts them
+type cognitoUserRepo struct {
+	repositories.UserRepositoryInterface
+	users   map[string]string
+	lookups int
+}
+
+func (r *cognitoUserRepo) GetUserIdByCognitoId(_ context.Context, cId string) (string, error) {
+	r.lookups++
+	return r.users[cId], nil
+}
+
+func TestGetUserIdByCognitoIdIsCached(t *testing.T) {
+	ctx := context.Background()
+	repo := &cognitoUserRepo{users: map[string]string{"cognito-1": "user-1"}}
+	service := NewUserService(repo, cache.New(cache.NewLRU(10)), nil)
+
+	for i := 0; i < 3; i++ {
+		userID, err := service.GetUserIdByCognitoId(ctx, "cognito-1")
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", userID)
+	}
+	assert.Equal(t, 1, repo.lookups)
+
+	// Unknown identities are looked up every time, so new users are found right away
+	for i := 0; i < 2; i++ {
+		userID, err := service.GetUserIdByCognitoId(ctx, "cognito-2")
+		require.NoError(t, err)
+		assert.Empty(t, userID)
+	}
+	repo.users["cognito-2"] = "user-2"
+
+	userID, err := service.GetUserIdByCognitoId(ctx, "cognito-2")
+	require.NoError(t, err)
+	assert.Equal(t, "user-2", userID)
+	assert.Equal(t, 4, repo.lookups)
+}
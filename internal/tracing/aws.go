@@ -0,0 +1,38 @@
+package tracing
+
+import (
+	"context"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/smithy-go/middleware"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AWSLoadOption traces every call of the clients created from the loaded AWS
+// config, e.g. awsconfig.LoadDefaultConfig(ctx, tracing.AWSLoadOption())
+func AWSLoadOption() func(*awsconfig.LoadOptions) error {
+	return awsconfig.WithAPIOptions([]func(*middleware.Stack) error{addAWSMiddleware})
+}
+
+// addAWSMiddleware wraps each operation, including its retries, in a span
+func addAWSMiddleware(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("ZolarisTracing",
+		func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			service, operation := awsmiddleware.GetServiceID(ctx), awsmiddleware.GetOperationName(ctx)
+			ctx, span := Start(ctx, service+"."+operation,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					semconv.RPCSystemKey.String("aws-api"),
+					semconv.RPCService(service),
+					semconv.RPCMethod(operation),
+					semconv.CloudRegion(awsmiddleware.GetRegion(ctx)),
+				),
+			)
+
+			out, metadata, err := next.HandleInitialize(ctx, in)
+			End(span, err)
+			return out, metadata, err
+		}), middleware.After)
+}
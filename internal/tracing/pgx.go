@@ -0,0 +1,70 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// QueryTracer creates a span for every query run through a pgx connection.
+// Set it as the Tracer of the connection config of a pool.
+type QueryTracer struct{}
+
+// TraceQueryStart starts the span of a query. The SQL text is recorded; the
+// arguments are not, as they may hold personal data.
+func (QueryTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	operation := queryOperation(data.SQL)
+	ctx, _ = Start(ctx, "postgres "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBQueryText(data.SQL),
+		),
+	)
+	return ctx
+}
+
+// TraceQueryEnd ends the span of a query. No rows is an answer, not a failure.
+func (QueryTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	err := data.Err
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+	End(trace.SpanFromContext(ctx), err)
+}
+
+// queryOperation returns the SQL command of a query, such as SELECT, or the
+// statement that follows the common table expressions of a WITH query
+func queryOperation(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "QUERY"
+	}
+	operation := strings.ToUpper(fields[0])
+	if operation != "WITH" {
+		return operation
+	}
+
+	// The main statement follows the closing parenthesis of the last CTE
+	depth := 0
+	for i, r := range sql {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				rest := strings.Fields(sql[i+1:])
+				if len(rest) > 0 && rest[0] != "," && !strings.HasPrefix(rest[0], ",") {
+					return strings.ToUpper(strings.Trim(rest[0], "("))
+				}
+			}
+		}
+	}
+	return operation
+}
@@ -0,0 +1,134 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/config"
+	"github.com/afreedicp/zolaris-backend-app/internal/testutil/spantest"
+)
+
+func TestSetupStdout(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	var out bytes.Buffer
+	shutdown, err := Setup(context.Background(), config.TracingConfig{Exporter: "stdout", SampleRatio: 1, ServiceName: "zolaris-test"}, &out)
+	require.NoError(t, err)
+
+	_, span := Start(context.Background(), "EntityService.GetEntityHierarchy")
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+
+	assert.Contains(t, out.String(), `"Name":"EntityService.GetEntityHierarchy"`)
+	assert.Contains(t, out.String(), `"Value":"zolaris-test"`)
+}
+
+func TestSetupNone(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	shutdown, err := Setup(context.Background(), config.TracingConfig{Exporter: "none"}, nil)
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+	assert.Equal(t, previous, otel.GetTracerProvider())
+
+	// Trace context is still propagated in W3C headers
+	carrier := propagation.MapCarrier{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), carrier)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", trace.SpanContextFromContext(ctx).TraceID().String())
+}
+
+func TestQueryOperation(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT id FROM z_entity WHERE id = $1", "SELECT"},
+		{"\n\t\tinsert into z_device (id) values ($1)", "INSERT"},
+		{"WITH RECURSIVE tree AS (SELECT id FROM z_entity WHERE id = $1) SELECT * FROM tree", "SELECT"},
+		{"WITH a AS (SELECT 1), b AS (SELECT (2)) UPDATE z_entity SET name = $1", "UPDATE"},
+		{"", "QUERY"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, queryOperation(tt.sql), tt.sql)
+	}
+}
+
+func TestQueryTracer(t *testing.T) {
+	recorder := spantest.Record(t)
+	tracer := QueryTracer{}
+
+	ctx, parent := Start(context.Background(), "entity.GetSubtree")
+	queryCtx := tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "SELECT name FROM z_entity WHERE id = $1", Args: []any{"secret-id"}})
+	tracer.TraceQueryEnd(queryCtx, nil, pgx.TraceQueryEndData{Err: pgx.ErrNoRows})
+	queryCtx = tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "DELETE FROM z_entity"})
+	tracer.TraceQueryEnd(queryCtx, nil, pgx.TraceQueryEndData{Err: errors.New("permission denied")})
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+
+	selectSpan := spans[0]
+	assert.Equal(t, "postgres SELECT", selectSpan.Name())
+	assert.Equal(t, trace.SpanKindClient, selectSpan.SpanKind())
+	assert.Equal(t, parent.SpanContext().SpanID(), selectSpan.Parent().SpanID())
+	assert.Equal(t, codes.Unset, selectSpan.Status().Code)
+	assert.Contains(t, selectSpan.Attributes(), attribute.String("db.query.text", "SELECT name FROM z_entity WHERE id = $1"))
+	for _, attr := range selectSpan.Attributes() {
+		assert.NotContains(t, attr.Value.Emit(), "secret-id")
+	}
+
+	deleteSpan := spans[1]
+	assert.Equal(t, "postgres DELETE", deleteSpan.Name())
+	assert.Equal(t, codes.Error, deleteSpan.Status().Code)
+	assert.Equal(t, "permission denied", deleteSpan.Status().Description)
+}
+
+type failingHTTPClient struct{}
+
+func (failingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusBadRequest,
+		Header:     http.Header{"Content-Type": []string{"application/x-amz-json-1.0"}},
+		Body:       io.NopCloser(strings.NewReader(`{"__type":"com.amazonaws.dynamodb.v20120810#ResourceNotFoundException","message":"Requested resource not found"}`)),
+		Request:    req,
+	}, nil
+}
+
+func TestAWSMiddleware(t *testing.T) {
+	recorder := spantest.Record(t)
+
+	client := dynamodb.New(dynamodb.Options{
+		Region:           "ap-south-1",
+		Credentials:      credentials.NewStaticCredentialsProvider("key", "secret", ""),
+		HTTPClient:       failingHTTPClient{},
+		RetryMaxAttempts: 1,
+		APIOptions:       []func(*middleware.Stack) error{addAWSMiddleware},
+	})
+	_, err := client.DescribeTable(context.Background(), &dynamodb.DescribeTableInput{TableName: awssdk.String("devices")})
+	require.Error(t, err)
+
+	span := spantest.Find(recorder, "DynamoDB.DescribeTable")
+	require.NotNil(t, span)
+	assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+	assert.Equal(t, codes.Error, span.Status().Code)
+	assert.Contains(t, span.Attributes(), attribute.String("rpc.method", "DescribeTable"))
+	assert.Contains(t, span.Attributes(), attribute.String("cloud.region", "ap-south-1"))
+}
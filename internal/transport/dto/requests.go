@@ -47,16 +47,50 @@ type SensorDataRequest struct {
 	DateMode    string `json:"dateMode" validate:"required,oneof=hourly daily weekly monthly yearly"`
 }
 
+// SensorDataQuery holds the query parameters of a sensor data request for the device in the path
+type SensorDataQuery struct {
+	Timestamp string `json:"timestamp" form:"timestamp"`
+	DateMode  string `json:"dateMode" form:"dateMode"`
+}
+
 // TimeRange defines start and end times for data filtering
 type TimeRange struct {
 	StartTime time.Time `json:"startTime"`
 	EndTime   time.Time `json:"endTime"`
 }
 
-// PaginationParams contains pagination parameters
+// PaginationParams contains pagination and sorting parameters shared by list endpoints.
+// Cursor continues from a previous page's nextCursor and takes precedence over Page.
+// Sort names a field, prefixed with "-" for descending order.
 type PaginationParams struct {
-	Page     int `json:"page" form:"page" default:"0"`
-	PageSize int `json:"pageSize" form:"pageSize" default:"20"`
+	Page     int    `json:"page" form:"page" default:"1" validate:"omitempty,min=1"`
+	PageSize int    `json:"pageSize" form:"pageSize" default:"20" validate:"omitempty,min=1,max=100"`
+	Cursor   string `json:"cursor" form:"cursor"`
+	Sort     string `json:"sort" form:"sort"`
+}
+
+// ListUserDevicesRequest represents a request to list the authenticated user's devices
+type ListUserDevicesRequest struct {
+	PaginationParams
+	Category string `json:"category" form:"category"`
+	EntityID string `json:"entityId" form:"entityId" validate:"omitempty,uuid"`
+}
+
+// ListReferredUsersRequest represents a request to list the users referred by the authenticated user
+type ListReferredUsersRequest struct {
+	PaginationParams
+	Role string `json:"role" form:"role" validate:"omitempty,oneof=admin user"`
+}
+
+// ListCategoriesRequest represents a request to list all categories
+type ListCategoriesRequest struct {
+	PaginationParams
+	Type string `json:"type" form:"type" validate:"omitempty,oneof=user office location"`
+}
+
+// GetCategoriesByTypeRequest represents a request to get the categories of one type
+type GetCategoriesByTypeRequest struct {
+	Type string `json:"type" validate:"required,oneof=user office location"`
 }
 
 // CreateRootEntityRequest represents a request to create a root entity
@@ -76,12 +110,100 @@ type CreateSubEntityRequest struct {
 
 // GetEntityChildrenRequest represents a request to get children of an entity
 type GetEntityChildrenRequest struct {
+	PaginationParams
 	Recursive    bool   `json:"recursive" form:"recursive" default:"false"`
 	Level        int    `json:"level" form:"level" default:"0"`
-	CategoryType string `json:"categoryType" form:"categoryType"`
+	CategoryType string `json:"categoryType" form:"categoryType" validate:"omitempty,oneof=user office location"`
+	CategoryID   string `json:"categoryId" form:"categoryId" validate:"omitempty,uuid"`
 }
 
 // GetEntityHierarchyRequest represents a request to get an entity hierarchy
 type GetEntityHierarchyRequest struct {
-	MaxDepth int `json:"maxDepth" form:"maxDepth" default:"10"`
+	// MaxDepth is nil when absent, so 0 can ask for the root alone
+	MaxDepth *int   `json:"maxDepth" form:"maxDepth" validate:"omitempty,min=0,max=50"`
+	Limit    int    `json:"limit" form:"limit" default:"50" validate:"omitempty,min=1,max=200"`
+	Cursor   string `json:"cursor" form:"cursor"`
+}
+
+// GetEntityAncestorsRequest represents a request to get the ancestors of an entity
+type GetEntityAncestorsRequest struct {
+	IncludeSelf bool `json:"includeSelf" form:"includeSelf" default:"false"`
+}
+
+// SearchEntitiesRequest represents a search across the caller's entity tree
+type SearchEntitiesRequest struct {
+	Name         string `json:"name" form:"name" validate:"omitempty,max=100"`
+	CategoryID   string `json:"categoryId" form:"categoryId" validate:"omitempty,uuid"`
+	CategoryType string `json:"categoryType" form:"categoryType" validate:"omitempty,oneof=user office location"`
+	// Details is a JSON object the entity details must contain, e.g. {"floor":"3"}
+	Details string `json:"details" form:"details"`
+	Limit   int    `json:"limit" form:"limit" default:"50" validate:"omitempty,min=1,max=200"`
+}
+
+// EntityTreeDocument is a nested entity tree with devices, used for both import and export
+type EntityTreeDocument struct {
+	ParentEntityID string           `json:"parentEntityId,omitempty"`
+	Entities       []EntityTreeNode `json:"entities"`
+}
+
+// EntityTreeNode is an entity with its devices and child entities in an EntityTreeDocument
+type EntityTreeNode struct {
+	Ref        string             `json:"ref,omitempty"`
+	Name       string             `json:"name"`
+	CategoryID string             `json:"categoryId"`
+	Details    map[string]any     `json:"details,omitempty"`
+	Devices    []EntityTreeDevice `json:"devices,omitempty"`
+	Children   []EntityTreeNode   `json:"children,omitempty"`
+}
+
+// EntityTreeDevice is a device assigned to an entity in an EntityTreeDocument
+type EntityTreeDevice struct {
+	DeviceID   string `json:"deviceId"`
+	DeviceName string `json:"deviceName"`
+}
+
+// ImportEntitiesRequest holds the query parameters of an entity import
+type ImportEntitiesRequest struct {
+	DryRun         bool   `json:"dryRun" form:"dryRun" default:"false"`
+	ParentEntityID string `json:"parentEntityId" form:"parentEntityId" validate:"omitempty,uuid"`
+}
+
+// ExportEntitiesRequest holds the query parameters of an entity export
+type ExportEntitiesRequest struct {
+	Format string `json:"format" form:"format" default:"json" validate:"omitempty,oneof=json csv"`
+}
+
+// EntityMetricsRequest represents a request for sensor metrics aggregated over an entity subtree
+type EntityMetricsRequest struct {
+	Timestamp string `json:"timestamp" form:"timestamp" validate:"omitempty,numeric"`
+	DateMode  string `json:"dateMode" form:"dateMode" default:"daily" validate:"omitempty,oneof=hourly daily weekly monthly yearly"`
+}
+
+// EntityHistoryRequest represents a request for the change history of an entity
+type EntityHistoryRequest struct {
+	Limit int `json:"limit" form:"limit" default:"100" validate:"omitempty,min=1,max=500"`
+}
+
+// EntityDiffRequest represents a request to diff an entity subtree between two RFC 3339 timestamps
+type EntityDiffRequest struct {
+	From string `json:"from" form:"from" validate:"required"`
+	To   string `json:"to" form:"to"`
+}
+
+// ListAuditEntriesRequest represents a request to list the audit log, filtered by
+// actor, action, resource and an RFC 3339 time range
+type ListAuditEntriesRequest struct {
+	PaginationParams
+	AuditFilterParams
+}
+
+// AuditFilterParams holds the filters of the audit log list and export. From is
+// inclusive and To exclusive.
+type AuditFilterParams struct {
+	ActorID      string `json:"actorId" form:"actorId" validate:"omitempty,uuid"`
+	Action       string `json:"action" form:"action" validate:"omitempty,max=32"`
+	ResourceType string `json:"resourceType" form:"resourceType" validate:"omitempty,max=32"`
+	ResourceID   string `json:"resourceId" form:"resourceId" validate:"omitempty,max=255"`
+	From         string `json:"from" form:"from"`
+	To           string `json:"to" form:"to"`
 }
@@ -51,6 +51,7 @@ type AddressOutput struct {
 type DeviceResponse struct {
 	DeviceID    string    `json:"deviceId"`
 	DeviceName  string    `json:"deviceName"`
+	EntityID    string    `json:"entityId,omitempty"`
 	Category    string    `json:"category,omitempty"`
 	Description string    `json:"description,omitempty"`
 	CreatedAt   time.Time `json:"createdAt"`
@@ -73,11 +74,12 @@ type CategoryResponse struct {
 
 // PaginatedResponse wraps list responses with pagination metadata
 type PaginatedResponse struct {
-	Items      any   `json:"items"`
-	TotalItems int64 `json:"totalItems"`
-	Page       int   `json:"page"`
-	PageSize   int   `json:"pageSize"`
-	TotalPages int   `json:"totalPages"`
+	Items      any    `json:"items"`
+	TotalItems int64  `json:"totalItems"`
+	Page       int    `json:"page"`
+	PageSize   int    `json:"pageSize"`
+	TotalPages int    `json:"totalPages"`
+	NextCursor string `json:"nextCursor,omitempty"`
 }
 
 // EntityResponse represents an entity in API responses
@@ -98,12 +100,132 @@ type EntityResponse struct {
 // EntityHierarchyResponse represents an entity with its children in API responses
 type EntityHierarchyResponse struct {
 	EntityResponse
-	Children []EntityHierarchyResponse `json:"children,omitempty"`
+	ChildCount int                       `json:"childCount"`
+	Children   []EntityHierarchyResponse `json:"children,omitempty"`
+	NextCursor string                    `json:"nextCursor,omitempty"`
 }
 
-// EntityChildrenResponse represents a list of entity children
-type EntityChildrenResponse struct {
-	ParentID string            `json:"parentId"`
-	Children []*EntityResponse `json:"children"`
-	Count    int               `json:"count"`
+// EntityAncestorsResponse represents the chain of ancestors of an entity, root first
+type EntityAncestorsResponse struct {
+	EntityID  string            `json:"entityId"`
+	Ancestors []*EntityResponse `json:"ancestors"`
+}
+
+// EntitySearchResponse represents the results of an entity search
+type EntitySearchResponse struct {
+	Results []*EntityResponse `json:"results"`
+	Count   int               `json:"count"`
+}
+
+// ImportRowError represents a rejected row of a bulk import
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Ref     string `json:"ref,omitempty"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// EntityImportResponse summarises a successful bulk import
+type EntityImportResponse struct {
+	DryRun          bool              `json:"dryRun"`
+	EntitiesCreated int               `json:"entitiesCreated"`
+	DevicesImported int               `json:"devicesImported"`
+	EntityIDs       map[string]string `json:"entityIds"`
+}
+
+// MetricSummaryResponse represents the summary of one sensor metric
+type MetricSummaryResponse struct {
+	Count int     `json:"count"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Avg   float64 `json:"avg"`
+}
+
+// EntityMetricsResponse represents the sensor aggregate of an entity's subtree
+type EntityMetricsResponse struct {
+	EntityID     string                 `json:"entityId"`
+	EntityName   string                 `json:"entityName"`
+	DeviceCount  int                    `json:"deviceCount"`
+	ReadingCount int                    `json:"readingCount"`
+	Temperature  *MetricSummaryResponse `json:"temperature,omitempty"`
+	Humidity     *MetricSummaryResponse `json:"humidity,omitempty"`
+	Amperage     *MetricSummaryResponse `json:"amperage,omitempty"`
+}
+
+// SubtreeMetricsResponse represents sensor metrics rolled up over an entity subtree
+type SubtreeMetricsResponse struct {
+	Total         EntityMetricsResponse   `json:"total"`
+	Children      []EntityMetricsResponse `json:"children"`
+	StartTime     int64                   `json:"startTime"`
+	EndTime       int64                   `json:"endTime"`
+	FailedDevices []string                `json:"failedDevices,omitempty"`
+}
+
+// EntityHistoryEntryResponse represents one recorded change to an entity
+type EntityHistoryEntryResponse struct {
+	ID        int64          `json:"id"`
+	Action    string         `json:"action"`
+	ActorID   string         `json:"actorId,omitempty"`
+	Before    map[string]any `json:"before,omitempty"`
+	After     map[string]any `json:"after,omitempty"`
+	ChangedAt time.Time      `json:"changedAt"`
+}
+
+// EntityHistoryResponse represents the change history of an entity, newest first
+type EntityHistoryResponse struct {
+	EntityID string                        `json:"entityId"`
+	Entries  []*EntityHistoryEntryResponse `json:"entries"`
+	Count    int                           `json:"count"`
+}
+
+// EntitySnapshotResponse represents an entity as it was at a point in time
+type EntitySnapshotResponse struct {
+	EntityID string `json:"entityId"`
+	Name     string `json:"name"`
+	ParentID string `json:"parentId,omitempty"`
+}
+
+// EntityMoveResponse represents an entity whose parent changed
+type EntityMoveResponse struct {
+	EntityID     string `json:"entityId"`
+	Name         string `json:"name"`
+	FromParentID string `json:"fromParentId,omitempty"`
+	ToParentID   string `json:"toParentId,omitempty"`
+}
+
+// EntityRenameResponse represents an entity whose name changed
+type EntityRenameResponse struct {
+	EntityID string `json:"entityId"`
+	FromName string `json:"fromName"`
+	ToName   string `json:"toName"`
+}
+
+// EntityTreeDiffResponse represents the changes to a subtree between two points in time
+type EntityTreeDiffResponse struct {
+	EntityID string                   `json:"entityId"`
+	From     time.Time                `json:"from"`
+	To       time.Time                `json:"to"`
+	Added    []EntitySnapshotResponse `json:"added"`
+	Removed  []EntitySnapshotResponse `json:"removed"`
+	Moved    []EntityMoveResponse     `json:"moved"`
+	Renamed  []EntityRenameResponse   `json:"renamed"`
+}
+
+// AuditEntryResponse represents one action recorded in the audit log
+type AuditEntryResponse struct {
+	ID           int64                          `json:"id"`
+	OccurredAt   time.Time                      `json:"occurredAt"`
+	ActorID      string                         `json:"actorId,omitempty"`
+	Action       string                         `json:"action"`
+	ResourceType string                         `json:"resourceType"`
+	ResourceID   string                         `json:"resourceId"`
+	Changes      map[string]AuditChangeResponse `json:"changes"`
+	IP           string                         `json:"ip,omitempty"`
+	RequestID    string                         `json:"requestId,omitempty"`
+}
+
+// AuditChangeResponse represents the value of a field before and after an audited action
+type AuditChangeResponse struct {
+	Before any `json:"before,omitempty"`
+	After  any `json:"after,omitempty"`
 }
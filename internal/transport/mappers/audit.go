@@ -0,0 +1,110 @@
+package mappers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+	"github.com/afreedicp/zolaris-backend-app/internal/transport/dto"
+)
+
+// AuditCSVHeader lists the columns of the audit log CSV export
+var AuditCSVHeader = []string{"id", "occurred_at", "actor_id", "action", "resource_type", "resource_id", "changes", "ip", "request_id"}
+
+// AuditEntryToResponse converts a domain AuditEntry to an AuditEntryResponse DTO
+func AuditEntryToResponse(entry *domain.AuditEntry) *dto.AuditEntryResponse {
+	if entry == nil {
+		return nil
+	}
+
+	response := &dto.AuditEntryResponse{
+		ID:           entry.ID,
+		OccurredAt:   entry.OccurredAt,
+		ActorID:      stringValue(entry.ActorID),
+		Action:       entry.Action,
+		ResourceType: entry.ResourceType,
+		ResourceID:   entry.ResourceID,
+		Changes:      make(map[string]dto.AuditChangeResponse, len(entry.Changes)),
+		IP:           stringValue(entry.IP),
+		RequestID:    stringValue(entry.RequestID),
+	}
+
+	for field, change := range entry.Changes {
+		var value dto.AuditChangeResponse
+		if len(change.Before) > 0 {
+			_ = json.Unmarshal(change.Before, &value.Before)
+		}
+		if len(change.After) > 0 {
+			_ = json.Unmarshal(change.After, &value.After)
+		}
+		response.Changes[field] = value
+	}
+
+	return response
+}
+
+// AuditEntriesToResponses converts a slice of domain AuditEntry to AuditEntryResponse DTOs
+func AuditEntriesToResponses(entries []*domain.AuditEntry) []*dto.AuditEntryResponse {
+	responses := make([]*dto.AuditEntryResponse, len(entries))
+	for i, entry := range entries {
+		responses[i] = AuditEntryToResponse(entry)
+	}
+	return responses
+}
+
+// AuditCSVWriter writes audit entries as CSV rows under AuditCSVHeader
+type AuditCSVWriter struct {
+	writer *csv.Writer
+}
+
+// NewAuditCSVWriter writes the header of the audit log CSV to w
+func NewAuditCSVWriter(w io.Writer) (*AuditCSVWriter, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(AuditCSVHeader); err != nil {
+		return nil, err
+	}
+	return &AuditCSVWriter{writer: writer}, nil
+}
+
+// Write writes entry as one row. The changes column holds them as JSON.
+func (w *AuditCSVWriter) Write(entry *domain.AuditEntry) error {
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return err
+	}
+
+	record := []string{
+		strconv.FormatInt(entry.ID, 10),
+		entry.OccurredAt.UTC().Format(time.RFC3339Nano),
+		stringValue(entry.ActorID),
+		entry.Action,
+		entry.ResourceType,
+		entry.ResourceID,
+		string(changes),
+		stringValue(entry.IP),
+		stringValue(entry.RequestID),
+	}
+	for i, value := range record {
+		record[i] = csvSafe(value)
+	}
+	return w.writer.Write(record)
+}
+
+// Flush writes any buffered rows and returns the first error of the writer
+func (w *AuditCSVWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// csvSafe prefixes values a spreadsheet would evaluate as a formula with a
+// quote, as the exported values come from clients
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
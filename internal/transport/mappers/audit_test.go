@@ -0,0 +1,40 @@
+package mappers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+)
+
+func TestAuditCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewAuditCSVWriter(&buf)
+	require.NoError(t, err)
+
+	actor := "6f1c2a9e-4b7d-4c1a-9d2e-3f5a6b7c8d9e"
+	require.NoError(t, writer.Write(&domain.AuditEntry{
+		ID:           7,
+		OccurredAt:   time.Date(2026, 3, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600)),
+		ActorID:      &actor,
+		Action:       domain.AuditActionCreate,
+		ResourceType: domain.AuditResourceCategory,
+		ResourceID:   "=HYPERLINK(\"http://evil\")",
+		Changes:      map[string]domain.AuditChange{"name": {After: []byte(`"-1+1"`)}},
+	}))
+	require.NoError(t, writer.Flush())
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, AuditCSVHeader, records[0])
+	assert.Equal(t, []string{
+		"7", "2026-03-01T11:00:00Z", actor, "create", "category",
+		"'=HYPERLINK(\"http://evil\")", `{"name":{"after":"-1+1"}}`, "", "",
+	}, records[1])
+}
@@ -0,0 +1,245 @@
+package mappers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/domain"
+	"github.com/afreedicp/zolaris-backend-app/internal/transport/dto"
+)
+
+// EntityCSVHeader lists the columns of the parent-reference CSV format used for entity import and export
+var EntityCSVHeader = []string{"kind", "ref", "parent_ref", "name", "category_id", "details", "device_id"}
+
+// TreeDocumentToImportRows flattens a nested entity tree into import rows.
+// Rows are numbered in document order and entities without a ref get a generated one.
+func TreeDocumentToImportRows(doc *dto.EntityTreeDocument) []domain.EntityImportRow {
+	var rows []domain.EntityImportRow
+
+	var walk func(node dto.EntityTreeNode, parentRef string)
+	walk = func(node dto.EntityTreeNode, parentRef string) {
+		row := domain.EntityImportRow{
+			Row:        len(rows) + 1,
+			Kind:       domain.ImportKindEntity,
+			Ref:        node.Ref,
+			ParentRef:  parentRef,
+			Name:       node.Name,
+			CategoryID: node.CategoryID,
+			Details:    node.Details,
+		}
+		if row.Ref == "" {
+			row.Ref = "#" + strconv.Itoa(row.Row)
+		}
+		rows = append(rows, row)
+
+		for _, device := range node.Devices {
+			rows = append(rows, domain.EntityImportRow{
+				Row:       len(rows) + 1,
+				Kind:      domain.ImportKindDevice,
+				ParentRef: row.Ref,
+				Name:      device.DeviceName,
+				DeviceID:  device.DeviceID,
+			})
+		}
+
+		for _, child := range node.Children {
+			walk(child, row.Ref)
+		}
+	}
+
+	for _, node := range doc.Entities {
+		walk(node, "")
+	}
+
+	return rows
+}
+
+// CSVToImportRows parses a parent-reference CSV into import rows numbered by line.
+// Malformed cells are reported as row errors; a malformed file returns an error.
+func CSVToImportRows(r io.Reader) ([]domain.EntityImportRow, []domain.EntityImportError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, fmt.Errorf("CSV is empty")
+		}
+		return nil, nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"ref", "parent_ref", "name"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, fmt.Errorf("CSV header is missing column %q", required)
+		}
+	}
+
+	var rows []domain.EntityImportRow
+	var rowErrors []domain.EntityImportError
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read CSV line %d: %w", line, err)
+		}
+
+		cell := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		row := domain.EntityImportRow{
+			Row:        line,
+			Kind:       cell("kind"),
+			Ref:        cell("ref"),
+			ParentRef:  cell("parent_ref"),
+			Name:       cell("name"),
+			CategoryID: cell("category_id"),
+			DeviceID:   cell("device_id"),
+		}
+		if row.Kind == "" {
+			row.Kind = domain.ImportKindEntity
+		}
+
+		if details := cell("details"); details != "" {
+			if err := json.Unmarshal([]byte(details), &row.Details); err != nil {
+				rowErrors = append(rowErrors, domain.EntityImportError{
+					Row:     line,
+					Ref:     row.Ref,
+					Field:   "details",
+					Message: "must be a JSON object",
+				})
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, rowErrors, nil
+}
+
+// SubtreeToTreeDocument converts an exported subtree to a nested tree keyed by entity ID
+func SubtreeToTreeDocument(subtree *domain.EntitySubtree) *dto.EntityTreeDocument {
+	doc := &dto.EntityTreeDocument{Entities: []dto.EntityTreeNode{}}
+	if subtree == nil || len(subtree.Entities) == 0 {
+		return doc
+	}
+
+	devicesByEntity := make(map[string][]dto.EntityTreeDevice)
+	for _, device := range subtree.Devices {
+		if device.EntityID == nil {
+			continue
+		}
+		devicesByEntity[*device.EntityID] = append(devicesByEntity[*device.EntityID], dto.EntityTreeDevice{
+			DeviceID:   device.MacAddress,
+			DeviceName: device.Name,
+		})
+	}
+
+	childrenByParent := make(map[string][]*domain.EntityNode)
+	for _, entity := range subtree.Entities[1:] {
+		if entity.ParentID != nil {
+			childrenByParent[*entity.ParentID] = append(childrenByParent[*entity.ParentID], entity)
+		}
+	}
+
+	var build func(entity *domain.EntityNode) dto.EntityTreeNode
+	build = func(entity *domain.EntityNode) dto.EntityTreeNode {
+		node := dto.EntityTreeNode{
+			Ref:        entity.ID,
+			Name:       entity.Name,
+			CategoryID: entity.CategoryID,
+			Details:    EntityToResponse(&entity.Entity).Details,
+			Devices:    devicesByEntity[entity.ID],
+		}
+		for _, child := range childrenByParent[entity.ID] {
+			node.Children = append(node.Children, build(child))
+		}
+		return node
+	}
+
+	doc.Entities = append(doc.Entities, build(subtree.Entities[0]))
+	return doc
+}
+
+// WriteSubtreeCSV writes an exported subtree in the parent-reference CSV format, keyed by entity ID
+func WriteSubtreeCSV(w io.Writer, subtree *domain.EntitySubtree) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(EntityCSVHeader); err != nil {
+		return err
+	}
+
+	for i, entity := range subtree.Entities {
+		parentRef := ""
+		if i > 0 && entity.ParentID != nil {
+			parentRef = *entity.ParentID
+		}
+
+		details := ""
+		if len(entity.Details) > 0 && string(entity.Details) != "null" {
+			details = string(entity.Details)
+		}
+
+		record := []string{domain.ImportKindEntity, entity.ID, parentRef, entity.Name, entity.CategoryID, details, ""}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	for _, device := range subtree.Devices {
+		parentRef := ""
+		if device.EntityID != nil {
+			parentRef = *device.EntityID
+		}
+
+		record := []string{domain.ImportKindDevice, "", parentRef, device.Name, "", "", device.MacAddress}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// ImportResultToResponse converts a domain EntityImportResult to an EntityImportResponse DTO
+func ImportResultToResponse(result *domain.EntityImportResult) *dto.EntityImportResponse {
+	if result == nil {
+		return nil
+	}
+
+	return &dto.EntityImportResponse{
+		DryRun:          result.DryRun,
+		EntitiesCreated: result.EntitiesCreated,
+		DevicesImported: result.DevicesImported,
+		EntityIDs:       result.EntityIDs,
+	}
+}
+
+// ImportErrorsToResponses converts domain import errors to ImportRowError DTOs
+func ImportErrorsToResponses(rowErrors []domain.EntityImportError) []dto.ImportRowError {
+	responses := make([]dto.ImportRowError, len(rowErrors))
+	for i, rowError := range rowErrors {
+		responses[i] = dto.ImportRowError{
+			Row:     rowError.Row,
+			Ref:     rowError.Ref,
+			Field:   rowError.Field,
+			Message: rowError.Message,
+		}
+	}
+	return responses
+}
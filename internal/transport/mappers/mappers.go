@@ -3,7 +3,7 @@ package mappers
 import (
 	"encoding/json"
 	"time"
-"log"
+"log/slog"
 	"github.com/afreedicp/zolaris-backend-app/internal/domain"
 	"github.com/afreedicp/zolaris-backend-app/internal/transport/dto"
 )
@@ -76,7 +76,7 @@ func UserRequestToEntity(req *dto.UserDetailsRequest, existingUser *domain.User)
             user.CognitoID = &req.CognitoID
         } else {
             // This should ideally not happen if validate:"required" works correctly on CognitoID in the DTO
-            log.Printf("Warning: CognitoID was empty in request DTO, but is required for user creation. This might lead to DB error if not handled upstream.")
+            slog.Warn("CognitoID was empty in request DTO, but is required for user creation. This might lead to DB error if not handled upstream.")
         }
     }
 
@@ -126,6 +126,10 @@ func DeviceToResponse(device *domain.Device) *dto.DeviceResponse {
 		CreatedAt:  device.CreatedAt,
 	}
 
+	if device.EntityID != nil {
+		response.EntityID = *device.EntityID
+	}
+
 	if device.Category != nil {
 		response.Category = *device.Category
 	}
@@ -255,56 +259,219 @@ func EntitiesToResponses(entities []*domain.Entity) []*dto.EntityResponse {
 	return responses
 }
 
-// HierarchyMapToResponse converts a map-based entity hierarchy to EntityHierarchyResponse
-func HierarchyMapToResponse(entityMap map[string]any) *dto.EntityHierarchyResponse {
-	if entityMap == nil {
+// EntityNodeToResponse converts a domain EntityNode to an EntityResponse DTO, without its children
+func EntityNodeToResponse(node *domain.EntityNode) *dto.EntityResponse {
+	if node == nil {
+		return nil
+	}
+
+	response := EntityToResponse(&node.Entity)
+	response.CategoryName = node.CategoryName
+	response.CategoryType = node.CategoryType
+
+	return response
+}
+
+// EntityNodesToResponses converts a slice of domain EntityNode to EntityResponse DTOs
+func EntityNodesToResponses(nodes []*domain.EntityNode) []*dto.EntityResponse {
+	responses := make([]*dto.EntityResponse, len(nodes))
+	for i, node := range nodes {
+		responses[i] = EntityNodeToResponse(node)
+	}
+	return responses
+}
+
+// EntityNodeToHierarchyResponse converts a domain EntityNode and its loaded children to EntityHierarchyResponse
+func EntityNodeToHierarchyResponse(node *domain.EntityNode) *dto.EntityHierarchyResponse {
+	if node == nil {
 		return nil
 	}
 
 	response := &dto.EntityHierarchyResponse{
-		EntityResponse: dto.EntityResponse{
-			ID:        entityMap["id"].(string),
-			Name:      entityMap["name"].(string),
-			Depth:     entityMap["depth"].(int),
-			CreatedAt: entityMap["created_at"].(time.Time),
-		},
+		EntityResponse: *EntityNodeToResponse(node),
+		ChildCount:     node.ChildCount,
 	}
 
-	// Handle optional fields
-	if userID, ok := entityMap["user_id"].(string); ok {
-		response.UserID = userID
+	// Process children recursively
+	if len(node.Children) > 0 {
+		response.Children = make([]dto.EntityHierarchyResponse, len(node.Children))
+		for i, child := range node.Children {
+			response.Children[i] = *EntityNodeToHierarchyResponse(child)
+		}
 	}
 
-	if parentID, ok := entityMap["parent_id"].(string); ok {
-		response.ParentID = parentID
+	return response
+}
+
+// EntityHierarchyToResponse converts a domain EntityHierarchy to EntityHierarchyResponse
+func EntityHierarchyToResponse(hierarchy *domain.EntityHierarchy) *dto.EntityHierarchyResponse {
+	if hierarchy == nil {
+		return nil
 	}
 
-	if categoryID, ok := entityMap["category_id"].(string); ok {
-		response.CategoryID = categoryID
+	response := EntityNodeToHierarchyResponse(hierarchy.Root)
+	if response != nil {
+		response.NextCursor = hierarchy.NextCursor
 	}
 
-	if categoryName, ok := entityMap["category_name"].(string); ok {
-		response.CategoryName = categoryName
+	return response
+}
+
+// MetricSummaryToResponse converts a domain MetricSummary to a MetricSummaryResponse DTO
+func MetricSummaryToResponse(summary *domain.MetricSummary) *dto.MetricSummaryResponse {
+	if summary == nil {
+		return nil
+	}
+
+	return &dto.MetricSummaryResponse{
+		Count: summary.Count,
+		Min:   summary.Min,
+		Max:   summary.Max,
+		Avg:   summary.Avg,
 	}
+}
 
-	if categoryType, ok := entityMap["category_type"].(string); ok {
-		response.CategoryType = categoryType
+// EntityMetricsToResponse converts a domain EntityMetrics to an EntityMetricsResponse DTO
+func EntityMetricsToResponse(metrics domain.EntityMetrics) dto.EntityMetricsResponse {
+	return dto.EntityMetricsResponse{
+		EntityID:     metrics.EntityID,
+		EntityName:   metrics.EntityName,
+		DeviceCount:  metrics.Aggregate.DeviceCount,
+		ReadingCount: metrics.Aggregate.ReadingCount,
+		Temperature:  MetricSummaryToResponse(metrics.Aggregate.Temperature),
+		Humidity:     MetricSummaryToResponse(metrics.Aggregate.Humidity),
+		Amperage:     MetricSummaryToResponse(metrics.Aggregate.Amperage),
 	}
+}
 
-	if details, ok := entityMap["details"].(map[string]any); ok {
-		response.Details = details
+// SubtreeMetricsToResponse converts a domain SubtreeMetrics to a SubtreeMetricsResponse DTO
+func SubtreeMetricsToResponse(metrics *domain.SubtreeMetrics) *dto.SubtreeMetricsResponse {
+	if metrics == nil {
+		return nil
 	}
 
-	// Process children recursively
-	if children, ok := entityMap["children"].([]map[string]any); ok && len(children) > 0 {
-		response.Children = make([]dto.EntityHierarchyResponse, len(children))
-		for i, child := range children {
-			childResponse := HierarchyMapToResponse(child)
-			if childResponse != nil {
-				response.Children[i] = *childResponse
-			}
+	response := &dto.SubtreeMetricsResponse{
+		Total:         EntityMetricsToResponse(metrics.Total),
+		Children:      make([]dto.EntityMetricsResponse, len(metrics.Children)),
+		StartTime:     metrics.StartTime,
+		EndTime:       metrics.EndTime,
+		FailedDevices: metrics.FailedDevices,
+	}
+	for i, child := range metrics.Children {
+		response.Children[i] = EntityMetricsToResponse(child)
+	}
+
+	return response
+}
+
+// EntityHistoryEntryToResponse converts a domain EntityHistoryEntry to an EntityHistoryEntryResponse DTO
+func EntityHistoryEntryToResponse(entry *domain.EntityHistoryEntry) *dto.EntityHistoryEntryResponse {
+	if entry == nil {
+		return nil
+	}
+
+	response := &dto.EntityHistoryEntryResponse{
+		ID:        entry.ID,
+		Action:    entry.Action,
+		ChangedAt: entry.ChangedAt,
+	}
+
+	if entry.ActorID != nil {
+		response.ActorID = *entry.ActorID
+	}
+
+	if len(entry.Before) > 0 {
+		_ = json.Unmarshal(entry.Before, &response.Before)
+	}
+
+	if len(entry.After) > 0 {
+		_ = json.Unmarshal(entry.After, &response.After)
+	}
+
+	return response
+}
+
+// EntityHistoryToResponses converts a slice of domain EntityHistoryEntry to EntityHistoryEntryResponse DTOs
+func EntityHistoryToResponses(entries []*domain.EntityHistoryEntry) []*dto.EntityHistoryEntryResponse {
+	responses := make([]*dto.EntityHistoryEntryResponse, len(entries))
+	for i, entry := range entries {
+		responses[i] = EntityHistoryEntryToResponse(entry)
+	}
+	return responses
+}
+
+// EntityTreeDiffToResponse converts a domain EntityTreeDiff to an EntityTreeDiffResponse DTO
+func EntityTreeDiffToResponse(entityID string, diff *domain.EntityTreeDiff) *dto.EntityTreeDiffResponse {
+	if diff == nil {
+		return nil
+	}
+
+	response := &dto.EntityTreeDiffResponse{
+		EntityID: entityID,
+		From:     diff.From,
+		To:       diff.To,
+		Added:    entitySnapshotsToResponses(diff.Added),
+		Removed:  entitySnapshotsToResponses(diff.Removed),
+		Moved:    make([]dto.EntityMoveResponse, len(diff.Moved)),
+		Renamed:  make([]dto.EntityRenameResponse, len(diff.Renamed)),
+	}
+
+	for i, move := range diff.Moved {
+		response.Moved[i] = dto.EntityMoveResponse{
+			EntityID:     move.EntityID,
+			Name:         move.Name,
+			FromParentID: stringValue(move.FromParentID),
+			ToParentID:   stringValue(move.ToParentID),
+		}
+	}
+
+	for i, rename := range diff.Renamed {
+		response.Renamed[i] = dto.EntityRenameResponse{
+			EntityID: rename.EntityID,
+			FromName: rename.FromName,
+			ToName:   rename.ToName,
 		}
 	}
 
 	return response
 }
+
+func entitySnapshotsToResponses(snapshots []domain.EntitySnapshot) []dto.EntitySnapshotResponse {
+	responses := make([]dto.EntitySnapshotResponse, len(snapshots))
+	for i, snapshot := range snapshots {
+		responses[i] = dto.EntitySnapshotResponse{
+			EntityID: snapshot.EntityID,
+			Name:     snapshot.Name,
+			ParentID: stringValue(snapshot.ParentID),
+		}
+	}
+	return responses
+}
+
+// stringValue dereferences an optional string, returning "" for nil
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// PaginationToListOptions converts pagination parameters and field filters to domain ListOptions.
+// Empty filter values are dropped.
+func PaginationToListOptions(params dto.PaginationParams, filters map[string]string) domain.ListOptions {
+	opts := domain.ListOptions{
+		Page:     params.Page,
+		PageSize: params.PageSize,
+		Cursor:   params.Cursor,
+		Sort:     params.Sort,
+		Filters:  make(map[string]string),
+	}
+
+	for field, value := range filters {
+		if value != "" {
+			opts.Filters[field] = value
+		}
+	}
+
+	return opts
+}
@@ -61,6 +61,26 @@ func Forbidden(c *gin.Context, message string) {
 	Error(c, http.StatusForbidden, message, "FORBIDDEN")
 }
 
+// Conflict sends a 409 error response
+func Conflict(c *gin.Context, message string) {
+	Error(c, http.StatusConflict, message, "CONFLICT")
+}
+
+// PayloadTooLarge sends a 413 error response
+func PayloadTooLarge(c *gin.Context, message string) {
+	Error(c, http.StatusRequestEntityTooLarge, message, "PAYLOAD_TOO_LARGE")
+}
+
+// TooManyRequests sends a 429 error response
+func TooManyRequests(c *gin.Context, message string) {
+	Error(c, http.StatusTooManyRequests, message, "RATE_LIMITED")
+}
+
+// ServiceUnavailable sends a 503 error response
+func ServiceUnavailable(c *gin.Context, message string) {
+	Error(c, http.StatusServiceUnavailable, message, "SERVICE_UNAVAILABLE")
+}
+
 // InternalError sends a 500 error response
 func InternalError(c *gin.Context, message string) {
 	Error(c, http.StatusInternalServerError, message, "INTERNAL_ERROR")
@@ -76,8 +96,18 @@ func ValidationErrors(c *gin.Context, errors []dto.ValidationError) {
 	})
 }
 
+// ImportErrors sends a response listing the rows rejected by a bulk import
+func ImportErrors(c *gin.Context, errors []dto.ImportRowError) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"success": false,
+		"error":   "Import validation failed",
+		"code":    "IMPORT_VALIDATION_ERROR",
+		"details": errors,
+	})
+}
+
 // Paginated sends a paginated response
-func Paginated(c *gin.Context, items any, total int64, page, pageSize int) {
+func Paginated(c *gin.Context, items any, total int64, page, pageSize int, nextCursor string) {
 	totalPages := int(total) / pageSize
 	if int(total)%pageSize > 0 {
 		totalPages++
@@ -91,6 +121,7 @@ func Paginated(c *gin.Context, items any, total int64, page, pageSize int) {
 			Page:       page,
 			PageSize:   pageSize,
 			TotalPages: totalPages,
+			NextCursor: nextCursor,
 		},
 	})
 }
@@ -1,20 +1,49 @@
 package utils
 
 import (
+	"crypto/rsa"
 	"fmt"
+	"sync/atomic"
 
 	"github.com/golang-jwt/jwt/v4"
 )
 
-func GetUserId(tokenString string) (string, error) {
+// JWTVerifier checks the RSA signature and the time claims of JWTs against a
+// public key, which can be replaced when its secret is rotated
+type JWTVerifier struct {
+	key atomic.Pointer[rsa.PublicKey]
+}
 
+// NewJWTVerifier creates a verifier for the PEM encoded RSA public key, as read
+// with secrets.JWTPublicKey
+func NewJWTVerifier(publicKeyPEM string) (*JWTVerifier, error) {
+	v := &JWTVerifier{}
+	if err := v.SetKey(publicKeyPEM); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// SetKey replaces the public key with the PEM encoded one. The current key is
+// kept if it cannot be parsed.
+func (v *JWTVerifier) SetKey(publicKeyPEM string) error {
+	key, err := jwt.ParseRSAPublicKeyFromPEM([]byte(publicKeyPEM))
+	if err != nil {
+		return fmt.Errorf("invalid JWT public key: %w", err)
+	}
+	v.key.Store(key)
+	return nil
+}
+
+// GetUserId verifies a JWT and returns its sub claim
+func (v *JWTVerifier) GetUserId(tokenString string) (string, error) {
+	key := v.key.Load()
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return nil, nil
-	}, jwt.WithoutClaimsValidation())
-
+		return key, nil
+	})
 	if err != nil {
 		return "", fmt.Errorf("failed to parse token: %w", err)
 	}
@@ -23,8 +52,7 @@ func GetUserId(tokenString string) (string, error) {
 		if sub, ok := claims["sub"].(string); ok {
 			return sub, nil
 		}
-    return "", fmt.Errorf("sub claim not found in token or not a string")
 	}
 
-	return tokenString, nil
+	return "", fmt.Errorf("sub claim not found in token or not a string")
 }
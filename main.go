@@ -4,27 +4,52 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"slices"
+	"strings"
+	"syscall"
 	"time"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 
 	"github.com/afreedicp/zolaris-backend-app/api/handlers"
+	"github.com/afreedicp/zolaris-backend-app/api/routes"
 	"github.com/afreedicp/zolaris-backend-app/docs"
 	"github.com/afreedicp/zolaris-backend-app/internal/aws"
+	"github.com/afreedicp/zolaris-backend-app/internal/cache"
 	"github.com/afreedicp/zolaris-backend-app/internal/config"
 	"github.com/afreedicp/zolaris-backend-app/internal/db"
+	"github.com/afreedicp/zolaris-backend-app/internal/health"
+	"github.com/afreedicp/zolaris-backend-app/internal/idempotency"
+	"github.com/afreedicp/zolaris-backend-app/internal/lifecycle"
+	"github.com/afreedicp/zolaris-backend-app/internal/logging"
+	"github.com/afreedicp/zolaris-backend-app/internal/metrics"
 	"github.com/afreedicp/zolaris-backend-app/internal/middleware"
+	"github.com/afreedicp/zolaris-backend-app/internal/openapi"
+	"github.com/afreedicp/zolaris-backend-app/internal/ratelimit"
+	"github.com/afreedicp/zolaris-backend-app/internal/redisclient"
 	"github.com/afreedicp/zolaris-backend-app/internal/repositories"
+	"github.com/afreedicp/zolaris-backend-app/internal/secrets"
 	"github.com/afreedicp/zolaris-backend-app/internal/services"
+	"github.com/afreedicp/zolaris-backend-app/internal/tracing"
+	"github.com/afreedicp/zolaris-backend-app/internal/utils"
 )
 
+//go:generate go run ./cmd/openapi
+
+// @title Zolaris Backend API
+// @version 1.0
+// @description API for IoT device management
+// @BasePath /
+// @schemes http https
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-Cognito-ID
 func main() {
 	// Load configuration
 	log.Println("Loading configuration...")
@@ -33,12 +58,42 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	// Initialize Swagger documentation
-	docs.SwaggerInfo.Title = "Zolaris Backend API"
-	docs.SwaggerInfo.Description = "API for IoT device management"
-	docs.SwaggerInfo.Version = "1.0"
-	docs.SwaggerInfo.BasePath = "/"
-	docs.SwaggerInfo.Schemes = []string{"http", "https"}
+	// Print the effective configuration instead of starting the server when invoked as `config`
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Fatalf("Failed to print configuration: %v", err)
+		}
+		return
+	}
+
+	// Run schema migrations instead of the server when invoked as `migrate ...`
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(context.Background(), cfg, os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
+	// Log JSON records from here on; the log package is routed through the same logger
+	var logLevel slog.LevelVar
+	setLogLevel(&logLevel, cfg.Logging.Level)
+	slog.SetDefault(logging.New(os.Stderr, cfg.Logging, &logLevel))
+
+	var effective strings.Builder
+	if err := cfg.Print(&effective); err != nil {
+		log.Printf("Failed to print configuration: %v", err)
+	}
+	slog.Info("Effective configuration", "config", effective.String())
+
+	// Trace requests down to the queries and AWS calls; spans are exported to stdout or an OTLP collector
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, os.Stdout)
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+
+	// Resources registered with the lifecycle manager are released in reverse order on shutdown
+	lifecycleManager := lifecycle.New(cfg.Shutdown)
+	lifecycleManager.OnClose("tracing", shutdownTracing)
 
 	// Set Gin mode based on environment
 	if cfg.Server.Environment == "production" {
@@ -47,17 +102,65 @@ func main() {
 
 	// Initialize AWS clients
 	log.Println("Initializing AWS clients...")
-	awsClients, err := aws.InitAWSClients(context.Background())
+	appMetrics := metrics.New()
+	awsClients, err := aws.InitAWSClients(context.Background(), appMetrics.AWSLoadOption(), tracing.AWSLoadOption())
 	if err != nil {
 		log.Fatalf("Failed to initialize AWS clients: %v", err)
 	}
 
+	// Verify the bearer tokens of authenticated requests when a public key is configured
+	var jwtVerifier *utils.JWTVerifier
+	jwtPublicKey, err := secrets.JWTPublicKey(context.Background(), cfg)
+	if err != nil {
+		log.Fatalf("Failed to read JWT public key: %v", err)
+	}
+	if jwtPublicKey != nil {
+		lifecycleManager.OnClose("jwt public key", func(context.Context) error {
+			jwtPublicKey.Close()
+			return nil
+		})
+		if jwtVerifier, err = utils.NewJWTVerifier(jwtPublicKey.Value()); err != nil {
+			log.Fatalf("Failed to load JWT public key: %v", err)
+		}
+		jwtPublicKey.OnChange(jwtVerifier.SetKey)
+	}
+
 	// Initialize database clients
 	log.Println("Initializing database clients...")
-	database, err := db.NewDatabase(context.Background(), awsClients.DynamoDB, cfg)
+	postgresPassword, err := secrets.PostgresPassword(context.Background(), cfg)
+	if err != nil {
+		log.Fatalf("Failed to read database password: %v", err)
+	}
+	if postgresPassword != nil {
+		lifecycleManager.OnClose("postgres password", func(context.Context) error {
+			postgresPassword.Close()
+			return nil
+		})
+	}
+	database, err := db.NewDatabase(context.Background(), awsClients.DynamoDB, cfg, postgresPassword)
 	if err != nil {
 		log.Fatalf("Failed to initialize database clients: %v", err)
 	}
+	lifecycleManager.OnClose("database", func(context.Context) error {
+		database.Close()
+		return nil
+	})
+
+	// One Redis client is shared by the cache, the rate limit and the idempotency stores
+	redisClient, err := redisclient.Open(context.Background(), cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to redis: %v", err)
+	}
+	if redisClient != nil {
+		lifecycleManager.OnClose("redis", func(context.Context) error { return redisClient.Close() })
+	}
+
+	// Initialize the lookup cache
+	appCache, err := cache.Open(context.Background(), cfg, redisClient)
+	if err != nil {
+		log.Fatalf("Failed to initialize cache: %v", err)
+	}
+	lifecycleManager.OnClose("cache", func(context.Context) error { return appCache.Close() })
 
 	// Initialize repositories
 	deviceRepo := repositories.NewDeviceRepository(database.GetPostgresPool(), database.GetDynamoClient())
@@ -65,29 +168,103 @@ func main() {
 	categoryRepo := repositories.NewCategoryRepository(database.GetPostgresPool())
 	userRepo := repositories.NewUserRepository(database.GetPostgresPool())
 	entityRepo := repositories.NewEntityRepository(database.GetPostgresPool())
+	entityHistoryRepo := repositories.NewEntityHistoryRepository(database.GetPostgresPool())
+	auditRepo := repositories.NewAuditRepository(database.GetPostgresPool())
+	unitOfWork := repositories.NewUnitOfWork(database.GetPostgresPool())
 
 	deviceRepo.WithMachineTable(database.GetMachineDataTableName())
 
+	// Serve hierarchy and listing reads from the replica when one is configured
+	readRouter := database.GetReadRouter()
+	deviceRepo.WithReadRouter(readRouter)
+	categoryRepo.WithReadRouter(readRouter)
+	userRepo.WithReadRouter(readRouter)
+	entityRepo.WithReadRouter(readRouter)
+	entityHistoryRepo.WithReadRouter(readRouter)
+	auditRepo.WithReadRouter(readRouter)
+
+	// Time every repository call
+	instrumentedDeviceRepo := repositories.InstrumentDeviceRepository(deviceRepo, appMetrics)
+	instrumentedPolicyRepo := repositories.InstrumentPolicyRepository(policyRepo, appMetrics)
+	instrumentedCategoryRepo := repositories.InstrumentCategoryRepository(categoryRepo, appMetrics)
+	instrumentedUserRepo := repositories.InstrumentUserRepository(userRepo, appMetrics)
+	instrumentedEntityRepo := repositories.InstrumentEntityRepository(entityRepo, appMetrics)
+	instrumentedEntityHistoryRepo := repositories.InstrumentEntityHistoryRepository(entityHistoryRepo, appMetrics)
+	instrumentedAuditRepo := repositories.InstrumentAuditRepository(auditRepo, appMetrics)
+
+	// Publish pool, cache and business metrics
+	appMetrics.Register(metrics.NewPoolCollector(database.GetPools()))
+	appMetrics.Register(metrics.NewReplicaCollectors(readRouter)...)
+	appMetrics.Register(metrics.NewCacheCollector(appCache))
+	appMetrics.Register(metrics.NewBusinessCollector(repositories.NewStatsRepository(database.GetPostgresPool()).WithReadRouter(readRouter), time.Minute))
+
+	// Readiness checks of every dependency a request may need
+	migrator, err := db.NewMigrator(database.GetPostgresPool())
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	checker := health.NewChecker(cfg.Health.CheckTimeout, cfg.Health.CacheTTL)
+	checker.Add("postgres", health.PostgresPing(database.GetPostgresPool()))
+	checker.Add("schema", health.SchemaVersion(migrator))
+	checker.Add("dynamodb", health.DynamoDBTables(awsClients.DynamoDB, database.GetDeviceTableName(), database.GetMachineDataTableName()))
+	checker.Add("iot", health.IoTConfig(awsClients.GetIoTClient(), cfg.AWS.IoTPolicy))
+	lifecycleManager.OnDrain(checker.Drain)
+
 	// Initialize services
-	deviceService := services.NewDeviceService(deviceRepo)
-	policyService := services.NewPolicyService(policyRepo, cfg.AWS.IoTPolicy)
-	categoryService := services.NewCategoryService(categoryRepo)
-	userService := services.NewUserService(userRepo)
-	entityService := services.NewEntityService(entityRepo, userRepo)
+	auditService := services.NewAuditService(instrumentedAuditRepo)
+	deviceService := services.NewDeviceService(instrumentedDeviceRepo, auditService)
+	policyService := services.NewPolicyService(instrumentedPolicyRepo, cfg.AWS.IoTPolicy, auditService)
+	categoryService := services.NewCategoryService(instrumentedCategoryRepo, appCache, auditService)
+	userService := services.NewUserService(instrumentedUserRepo, appCache, auditService)
+	entityService := services.NewEntityService(instrumentedEntityRepo, instrumentedUserRepo, unitOfWork, appCache, auditService)
+	entityTransferService := services.NewEntityTransferService(instrumentedEntityRepo, instrumentedDeviceRepo, appCache, auditService)
+	entityMetricsService := services.NewEntityMetricsService(instrumentedEntityRepo, instrumentedDeviceRepo)
+	entityHistoryService := services.NewEntityHistoryService(instrumentedEntityHistoryRepo, instrumentedEntityRepo)
 
 	// Initialize handlers
-	entityHandler := handlers.NewEntityHandler(entityService)
-	userHandler := handlers.NewUserHandler(userService)
-	addDeviceHandler := handlers.NewAddDeviceHandler(deviceService)
-	attachIotPolicyHandler := handlers.NewAttachIotPolicyHandler(policyService)
-	getDeviceSensorDataHandler := handlers.NewGetDeviceSensorDataHandler(deviceService)
-	listUserDevicesHandler := handlers.NewListUserDevicesHandler(deviceService)
-	addCategoryHandler := handlers.NewAddCategoryHandler(categoryService)
-	getCategoriesByTypeHandler := handlers.NewGetCategoriesByTypeHandler(categoryService)
-	listAllCategoriesHandler := handlers.NewListAllCategoriesHandler(categoryService)
+	apiRoutes := routes.New(routes.Handlers{
+		Entity:              handlers.NewEntityHandler(entityService),
+		EntityTransfer:      handlers.NewEntityTransferHandler(entityTransferService),
+		EntityMetrics:       handlers.NewEntityMetricsHandler(entityMetricsService),
+		EntityHistory:       handlers.NewEntityHistoryHandler(entityHistoryService),
+		Audit:               handlers.NewAuditHandler(auditService),
+		User:                handlers.NewUserHandler(userService),
+		AddDevice:           handlers.NewAddDeviceHandler(deviceService),
+		AttachIotPolicy:     handlers.NewAttachIotPolicyHandler(policyService),
+		GetDeviceSensorData: handlers.NewGetDeviceSensorDataHandler(deviceService),
+		ListUserDevices:     handlers.NewListUserDevicesHandler(deviceService),
+		AddCategory:         handlers.NewAddCategoryHandler(categoryService),
+		GetCategoriesByType: handlers.NewGetCategoriesByTypeHandler(categoryService),
+		ListAllCategories:   handlers.NewListAllCategoriesHandler(categoryService),
+	}, cfg.Server.LegacyRoutesSunset)
+	healthHandler := handlers.NewHealthHandler(checker)
 
 	// Create router with global middleware
 	r := gin.New()
+	// Only the proxies in TRUSTED_PROXIES may set the client IP with X-Forwarded-For
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatalf("Invalid trusted proxies: %v", err)
+	}
+
+	// Throttle each client per route group, keyed by user, API key or IP
+	rateLimitStore, err := ratelimit.Open(context.Background(), cfg, redisClient)
+	if err != nil {
+		log.Fatalf("Failed to initialize rate limiting: %v", err)
+	}
+	rateLimitKey := middleware.RateLimitKey(cfg.RateLimit.APIKeys)
+	rateLimit := func(group, limit string) gin.HandlerFunc {
+		parsed, err := ratelimit.ParseLimit(limit)
+		if err != nil {
+			log.Fatalf("Invalid %s rate limit: %v", group, err)
+		}
+		return middleware.GinRateLimitMiddleware(rateLimitStore, group, parsed, rateLimitKey)
+	}
+
+	// Replay the responses of retried POST requests sent with an Idempotency-Key
+	idempotencyStore, err := idempotency.Open(context.Background(), cfg, redisClient)
+	if err != nil {
+		log.Fatalf("Failed to initialize idempotency store: %v", err)
+	}
 
 	// Set up Swagger endpoint with dynamic host based on environment
 	swaggerHost := fmt.Sprintf("http://localhost:%d", cfg.Server.Port)
@@ -98,79 +275,55 @@ func main() {
 	swaggerURL := ginSwagger.URL(fmt.Sprintf("%s/swagger/doc.json", swaggerHost))
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler, swaggerURL))
 
-	// Set up CORS
-	r.Use(cors.New(cors.Config{
-		AllowOriginFunc: func(origin string) bool {
-			// Allow specific origins
-			allowedOrigins := []string{
-				"http://3.110.190.71",
-				"https://staging.duvw6ii0xapud.amplifyapp.com",
-			}
-			if slices.Contains(allowedOrigins, origin) {
-				return true
-			}
-			// Allow all localhost origins
-			if len(origin) > 16 && origin[:16] == "http://localhost" {
-				return true
-			}
-			return false
-		},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "X-Cognito-ID"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-		MaxAge:           1 * time.Hour,
-	}))
+	// Probes are registered ahead of the global middleware, so they are not logged, traced or counted
+	r.GET("/livez", healthHandler.HandleLivez)
+	r.GET("/readyz", healthHandler.HandleReadyz)
+
+	// Set up CORS; the policy is reloaded from the configuration on SIGHUP
+	corsPolicy := middleware.NewCORS(cfg.CORS)
+	r.Use(corsPolicy.Handler())
+	lifecycleManager.Go("config reload", func(ctx context.Context) {
+		reloadOnHangup(ctx, corsPolicy, &logLevel)
+	})
 
 	// Apply global middleware
 	r.Use(middleware.GinLoggerMiddleware())
+	r.Use(middleware.GinTracingMiddleware())
+	r.Use(middleware.GinMetricsMiddleware(appMetrics))
+	// In development and testing, check requests and responses against the generated OpenAPI document
+	if cfg.Server.OpenAPIValidation != "off" {
+		doc, err := openapi.Load([]byte(docs.SwaggerInfo.ReadDoc()))
+		if err != nil {
+			log.Fatalf("Failed to load the OpenAPI document: %v", err)
+		}
+		r.Use(middleware.GinOpenAPIMiddleware(doc, cfg.Server.OpenAPIValidation == "enforce"))
+	}
 	r.Use(gin.Recovery())
+	r.Use(middleware.GinErrorMiddleware())
 
 	// Health check endpoint
-	// @Summary Health check
-	// @Description Check if the API is running
-	// @Tags System
-	// @Accept json
-	// @Produce plain
-	// @Success 200 {string} string "OK"
-	// @Router /health [get]
-	r.GET("/health", func(c *gin.Context) {
-		c.String(http.StatusOK, "OK")
-	})
+	r.GET("/health", healthHandler.HandleHealth)
+
+	// Prometheus metrics, including the cache hit/miss counters
+	r.GET("/metrics", gin.WrapH(appMetrics.Handler()))
 
-	// Group private routes (require authentication)
-	private := r.Group("/")
-	private.Use(middleware.GinAuthMiddleware(userService))
-	{
-		
-		// Device endpoints
-		private.POST("/device/add", addDeviceHandler.HandleGin)
-		private.GET("/user/devices", listUserDevicesHandler.HandleGin)
-
-		// User endpoints
-		private.GET("/user/check-parent-id", userHandler.HandleCheckHasParentID)
-		private.POST("/user/details", userHandler.HandleUpdateUserDetails)
-		private.GET("/user/details", userHandler.HandleGetUserDetails)
-		private.GET("/user/has-entity", entityHandler.HandleCheckEntityPresence)
-		private.GET("/user/referrals", userHandler.HandleListReferredUsers)
-		
-
-		// Entity endpoints (authenticated)
-		private.POST("/entity/root", entityHandler.HandleCreateRootEntity)
-		private.POST("/entity/sub", entityHandler.HandleCreateSubEntity)
-	}
-
-	// Public routes (no authentication required)
-	r.POST("/user/createUser",userHandler.CreateUserDetails)
-	r.POST("/device/attach-policy", attachIotPolicyHandler.HandleGin)
-	r.POST("/device/sensor-data", getDeviceSensorDataHandler.HandleGin)
-	r.POST("/category/add", addCategoryHandler.HandleGin)
-	r.GET("/category/type/:type", getCategoriesByTypeHandler.HandleGin)
-	r.GET("/category/all", listAllCategoriesHandler.HandleGin)
-
-	// Entity endpoints (public)
-	r.GET("/entity/:entity_id/children", entityHandler.HandleGetEntityChildren)
-	r.GET("/entity/:entity_id/hierarchy", entityHandler.HandleGetEntityHierarchy)
+	// Register every version of the API; each group has its own rate limit.
+	// Authenticated requests are limited per IP before the user is looked up,
+	// and per user after.
+	authenticated := []gin.HandlerFunc{
+		rateLimit("authenticated-ip", cfg.RateLimit.AuthenticatedIP),
+		middleware.GinAuthMiddleware(userService, jwtVerifier),
+		rateLimit("authenticated", cfg.RateLimit.Authenticated),
+		middleware.GinIdempotencyMiddleware(idempotencyStore, cfg.Idempotency),
+	}
+	apiRoutes.Register(r, routes.Groups{
+		routes.Public:        {rateLimit("public", cfg.RateLimit.Public)},
+		routes.Signup:        {rateLimit("signup", cfg.RateLimit.Signup)},
+		routes.Device:        {rateLimit("device", cfg.RateLimit.Device)},
+		routes.Authenticated: authenticated,
+		// Only the users listed in ADMIN_USER_IDS
+		routes.Admin: append(slices.Clone(authenticated), middleware.GinAdminMiddleware(cfg.Server.AdminUserIDs)),
+	})
 
 	// Create server
 	port := cfg.Server.Port
@@ -179,26 +332,48 @@ func main() {
 		Handler: r,
 	}
 
-	// Start server in a goroutine
-	go func() {
-		log.Printf("Server listening on port %d", port)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server error: %v", err)
-		}
-	}()
+	// Serve until SIGINT or SIGTERM, then drain requests and workers and close the resources
+	lifecycleManager.Serve(server)
+	if err := lifecycleManager.Run(); err != nil {
+		log.Printf("Shutdown failed: %v", err)
+		os.Exit(1)
+	}
+}
+
+// reloadOnHangup reloads the configuration on SIGHUP and applies the settings
+// that can change without a restart. An invalid configuration is logged and
+// the current settings are kept.
+func reloadOnHangup(ctx context.Context, corsPolicy *middleware.CORS, logLevel *slog.LevelVar) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
 
-	// Graceful shutdown
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt)
-	<-quit
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+		}
 
-	log.Println("Server shutting down...")
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+		log.Println("Reloading configuration...")
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			log.Printf("Failed to reload configuration, keeping the current settings: %v", err)
+			continue
+		}
 
-	if err := server.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		corsPolicy.Update(cfg.CORS)
+		setLogLevel(logLevel, cfg.Logging.Level)
+		slog.Info("Configuration reloaded", "cors_allowed_origins", cfg.CORS.AllowedOrigins, "log_level", logLevel.Level())
 	}
+}
 
-	log.Println("Server exited properly")
+// setLogLevel applies a level validated by config.Load
+func setLogLevel(logLevel *slog.LevelVar, name string) {
+	level, err := logging.ParseLevel(name)
+	if err != nil {
+		log.Printf("Invalid log level %q, keeping %s", name, logLevel.Level())
+		return
+	}
+	logLevel.Set(level)
 }
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/afreedicp/zolaris-backend-app/internal/config"
+	"github.com/afreedicp/zolaris-backend-app/internal/db"
+	"github.com/afreedicp/zolaris-backend-app/internal/secrets"
+)
+
+const migrateUsage = "usage: migrate up | down [N] | status | to VERSION"
+
+// runMigrate implements the `migrate` subcommand against the configured PostgreSQL database
+func runMigrate(ctx context.Context, cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	password, err := secrets.PostgresPassword(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	if password != nil {
+		defer password.Close()
+	}
+
+	pgDB, err := db.NewPostgresDB(ctx, cfg, password)
+	if err != nil {
+		return err
+	}
+	defer pgDB.Close()
+
+	migrator, err := db.NewMigrator(pgDB.GetPool())
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		if err := migrator.Up(ctx); err != nil {
+			return err
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil {
+				return fmt.Errorf("invalid step count %q", args[1])
+			}
+		}
+		if err := migrator.Down(ctx, steps); err != nil {
+			return err
+		}
+	case "to":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		if err := migrator.To(ctx, uint(version)); err != nil {
+			return err
+		}
+	case "status":
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	status, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Schema version: %d (latest %d, dirty %t)", status.Version, status.Latest, status.Dirty)
+	for _, migration := range status.Pending {
+		log.Printf("Pending: %06d_%s", migration.Version, migration.Name)
+	}
+	return nil
+}